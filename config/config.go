@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,6 +13,15 @@ import (
 type Config struct {
 	Server      ServerConfig      `yaml:"server" json:"server"`
 	AI          AIConfig          `yaml:"ai" json:"ai"`
+	Auth        AuthConfig        `yaml:"auth" json:"auth"`
+	History     HistoryConfig     `yaml:"history" json:"history"`
+	Experiments ExperimentsConfig `yaml:"experiments" json:"experiments"`
+	Feedback    FeedbackConfig    `yaml:"feedback" json:"feedback"`
+	Analysis    AnalysisConfig    `yaml:"analysis" json:"analysis"`
+	Corpus      CorpusConfig      `yaml:"corpus" json:"corpus"`
+	Personas    PersonasConfig    `yaml:"personas" json:"personas"`
+	Analogies   AnalogiesConfig   `yaml:"analogies" json:"analogies"`
+	I18n        I18nConfig        `yaml:"i18n" json:"i18n"`
 	Logging     LoggingConfig     `yaml:"logging" json:"logging"`
 	Monitoring  MonitoringConfig  `yaml:"monitoring" json:"monitoring"`
 	Development DevelopmentConfig `yaml:"development" json:"development"`
@@ -27,19 +37,22 @@ type ServerConfig struct {
 	TLSEnabled   bool   `yaml:"tls_enabled" json:"tls_enabled"`
 	TLSCertFile  string `yaml:"tls_cert_file" json:"tls_cert_file"`
 	TLSKeyFile   string `yaml:"tls_key_file" json:"tls_key_file"`
+	// AllowedOrigins 允许建立WebSocket连接的其他来源，如 https://app.example.com；与服务同源的页面总是允许
+	AllowedOrigins []string `yaml:"allowed_origins" json:"allowed_origins"`
 }
 
 // AIConfig AI配置
 type AIConfig struct {
-	Mode               string               `yaml:"mode" json:"mode"`
-	MaxAnalysisTime    int                  `yaml:"max_analysis_time" json:"max_analysis_time"`
-	InteractionTimeout int                  `yaml:"interaction_timeout" json:"interaction_timeout"`
-	CacheEnabled       bool                 `yaml:"cache_enabled" json:"cache_enabled"`
-	Cache              CacheConfig          `yaml:"cache" json:"cache"`
-	CircuitBreaker     CircuitBreakerConfig `yaml:"circuit_breaker" json:"circuit_breaker"`
-	Concurrency        ConcurrencyConfig    `yaml:"concurrency" json:"concurrency"`
-	RateLimit          RateLimitConfig      `yaml:"rate_limit" json:"rate_limit"`
-	AIRateLimit        AIRateLimitConfig    `yaml:"ai_rate_limit" json:"ai_rate_limit"`
+	Mode               string                 `yaml:"mode" json:"mode"`
+	MaxAnalysisTime    int                    `yaml:"max_analysis_time" json:"max_analysis_time"`
+	InteractionTimeout int                    `yaml:"interaction_timeout" json:"interaction_timeout"`
+	CacheEnabled       bool                   `yaml:"cache_enabled" json:"cache_enabled"`
+	Cache              CacheConfig            `yaml:"cache" json:"cache"`
+	CircuitBreaker     CircuitBreakerConfig   `yaml:"circuit_breaker" json:"circuit_breaker"`
+	Concurrency        ConcurrencyConfig      `yaml:"concurrency" json:"concurrency"`
+	RateLimit          RateLimitConfig        `yaml:"rate_limit" json:"rate_limit"`
+	AIRateLimit        AIRateLimitConfig      `yaml:"ai_rate_limit" json:"ai_rate_limit"`
+	Voices             map[string]VoiceConfig `yaml:"voices" json:"voices"` // 各名人风格朗读回答时的默认音色
 }
 
 // CacheConfig 缓存配置
@@ -73,6 +86,69 @@ type AIRateLimitConfig struct {
 	RetryWaitTime      int  `yaml:"retry_wait_time" json:"retry_wait_time"`
 }
 
+// VoiceConfig 语音合成音色配置
+type VoiceConfig struct {
+	Voice        string  `yaml:"voice" json:"voice"`               // 音色名，如onyx、nova
+	Speed        float64 `yaml:"speed" json:"speed"`               // 语速倍数，0表示1.0
+	Instructions string  `yaml:"instructions" json:"instructions"` // 语气说明，仅支持指令的模型使用
+}
+
+// AuthConfig 用户认证配置
+type AuthConfig struct {
+	Enabled           bool   `yaml:"enabled" json:"enabled"`
+	DataFile          string `yaml:"data_file" json:"data_file"`
+	SessionTTL        int    `yaml:"session_ttl" json:"session_ttl"` // 小时
+	CookieSecure      bool   `yaml:"cookie_secure" json:"cookie_secure"`
+	AllowRegistration bool   `yaml:"allow_registration" json:"allow_registration"`
+	AdminUsername     string `yaml:"admin_username" json:"admin_username"` // 启动时创建或设为管理员的账号
+	AdminPassword     string `yaml:"admin_password" json:"-"`              // 新建管理员账号时使用的密码
+}
+
+// HistoryConfig 训练历史配置
+type HistoryConfig struct {
+	DataFile string `yaml:"data_file" json:"data_file"`
+}
+
+// ExperimentsConfig 提示词实验配置
+type ExperimentsConfig struct {
+	DataFile   string `yaml:"data_file" json:"data_file"`     // 实验定义
+	EventsFile string `yaml:"events_file" json:"events_file"` // 曝光、投票和评分事件
+}
+
+// FeedbackConfig 用户反馈配置
+type FeedbackConfig struct {
+	DataFile string `yaml:"data_file" json:"data_file"`
+}
+
+// AnalysisConfig 表达分析配置
+type AnalysisConfig struct {
+	LexiconFile        string `yaml:"lexicon_file" json:"lexicon_file"`                 // 赘词词表，文件不存在时使用内置词表
+	ProfileLexiconFile string `yaml:"profile_lexicon_file" json:"profile_lexicon_file"` // 用户画像词表，合并到内置词表
+}
+
+// CorpusConfig 名人讲话语料配置
+type CorpusConfig struct {
+	Dir        string `yaml:"dir" json:"dir"`               // 讲稿目录，按 <目录>/<风格标识>/<文档名>.md 存放
+	Passages   int    `yaml:"passages" json:"passages"`     // 生成回答时检索的片段数，0表示不检索
+	Embeddings bool   `yaml:"embeddings" json:"embeddings"` // 是否结合向量检索（需要服务商支持/embeddings）
+}
+
+// PersonasConfig 自定义风格配置
+type PersonasConfig struct {
+	DataFile string `yaml:"data_file" json:"data_file"` // 用户上传样本生成的风格
+}
+
+// AnalogiesConfig 兴趣类比库配置
+type AnalogiesConfig struct {
+	Dir string `yaml:"dir" json:"dir"` // 编辑维护的类比库目录，文件合并到内置类比库
+}
+
+// I18nConfig 界面和回答语言配置
+type I18nConfig struct {
+	DefaultLocale string `yaml:"default_locale" json:"default_locale"` // 无法协商时使用的语言，也是缺少翻译时的回退语言
+	LocalesDir    string `yaml:"locales_dir" json:"locales_dir"`       // 编辑维护的消息目录，文件合并到内置消息目录
+}
+
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level       string            `yaml:"level" json:"level"`
@@ -194,6 +270,9 @@ func LoadFromFile(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// DefaultInteractionTimeout 未配置interaction_timeout时AI交互的超时时间（秒）
+const DefaultInteractionTimeout = 600
+
 // GetDefaultConfig 获取默认配置
 func GetDefaultConfig() *Config {
 	return &Config{
@@ -207,7 +286,7 @@ func GetDefaultConfig() *Config {
 		AI: AIConfig{
 			Mode:               "internal",
 			MaxAnalysisTime:    60,
-			InteractionTimeout: 600, // 10分钟AI交互超时
+			InteractionTimeout: DefaultInteractionTimeout,
 			CacheEnabled:       true,
 			Cache: CacheConfig{
 				TTL:        3600,
@@ -230,6 +309,47 @@ func GetDefaultConfig() *Config {
 				EnableRetry:        true,
 				RetryWaitTime:      5,
 			},
+			Voices: map[string]VoiceConfig{
+				"kanghui":   {Voice: "onyx", Speed: 0.95, Instructions: "像新闻联播播音员一样，字正腔圆、沉稳庄重"},
+				"dongqing":  {Voice: "shimmer", Speed: 0.95, Instructions: "温柔亲切，语气舒缓，富有感染力"},
+				"hanhan":    {Voice: "echo", Speed: 1.1, Instructions: "随意直接，带一点调侃"},
+				"chengming": {Voice: "fable", Speed: 1.05, Instructions: "冷静理性，逻辑感强，重音落在关键论点上"},
+			},
+		},
+		Auth: AuthConfig{
+			Enabled:           true,
+			DataFile:          "data/users.json",
+			SessionTTL:        168, // 7天
+			CookieSecure:      false,
+			AllowRegistration: false,
+		},
+		History: HistoryConfig{
+			DataFile: "data/history.jsonl",
+		},
+		Experiments: ExperimentsConfig{
+			DataFile:   "data/experiments.json",
+			EventsFile: "data/experiment_events.jsonl",
+		},
+		Feedback: FeedbackConfig{
+			DataFile: "data/feedback.jsonl",
+		},
+		Analysis: AnalysisConfig{
+			LexiconFile:        "config/disfluency.yaml",
+			ProfileLexiconFile: "config/profile.yaml",
+		},
+		Corpus: CorpusConfig{
+			Dir:      "data/corpus",
+			Passages: 3,
+		},
+		Personas: PersonasConfig{
+			DataFile: "data/personas.json",
+		},
+		Analogies: AnalogiesConfig{
+			Dir: "data/analogies",
+		},
+		I18n: I18nConfig{
+			DefaultLocale: "zh-CN",
+			LocalesDir:    "data/locales",
 		},
 		Logging: LoggingConfig{
 			Level:       "info",
@@ -282,6 +402,9 @@ func overrideFromEnv(config *Config) {
 	if writeTimeout := getEnvAsInt("SERVER_WRITE_TIMEOUT", 0); writeTimeout > 0 {
 		config.Server.WriteTimeout = writeTimeout
 	}
+	if origins := os.Getenv("SERVER_ALLOWED_ORIGINS"); origins != "" {
+		config.Server.AllowedOrigins = strings.Split(origins, ",")
+	}
 
 	// AI配置
 	if aiMode := os.Getenv("AI_MODE"); aiMode != "" {
@@ -294,6 +417,64 @@ func overrideFromEnv(config *Config) {
 		config.AI.CacheEnabled = getEnvAsBool("AI_CACHE_ENABLED", true)
 	}
 
+	// 认证配置
+	if authEnabled := os.Getenv("AUTH_ENABLED"); authEnabled != "" {
+		config.Auth.Enabled = getEnvAsBool("AUTH_ENABLED", true)
+	}
+	if dataFile := os.Getenv("AUTH_DATA_FILE"); dataFile != "" {
+		config.Auth.DataFile = dataFile
+	}
+	if allowRegistration := os.Getenv("AUTH_ALLOW_REGISTRATION"); allowRegistration != "" {
+		config.Auth.AllowRegistration = getEnvAsBool("AUTH_ALLOW_REGISTRATION", false)
+	}
+	if adminUsername := os.Getenv("AUTH_ADMIN_USERNAME"); adminUsername != "" {
+		config.Auth.AdminUsername = adminUsername
+	}
+	if adminPassword := os.Getenv("AUTH_ADMIN_PASSWORD"); adminPassword != "" {
+		config.Auth.AdminPassword = adminPassword
+	}
+
+	// 训练历史配置
+	if historyFile := os.Getenv("HISTORY_DATA_FILE"); historyFile != "" {
+		config.History.DataFile = historyFile
+	}
+
+	// 用户反馈配置
+	if feedbackFile := os.Getenv("FEEDBACK_DATA_FILE"); feedbackFile != "" {
+		config.Feedback.DataFile = feedbackFile
+	}
+
+	// 表达分析配置
+	if lexiconFile := os.Getenv("DISFLUENCY_LEXICON_FILE"); lexiconFile != "" {
+		config.Analysis.LexiconFile = lexiconFile
+	}
+	if profileLexiconFile := os.Getenv("PROFILE_LEXICON_FILE"); profileLexiconFile != "" {
+		config.Analysis.ProfileLexiconFile = profileLexiconFile
+	}
+
+	// 名人讲话语料配置
+	if corpusDir := os.Getenv("CORPUS_DIR"); corpusDir != "" {
+		config.Corpus.Dir = corpusDir
+	}
+
+	// 自定义风格配置
+	if personasFile := os.Getenv("PERSONAS_DATA_FILE"); personasFile != "" {
+		config.Personas.DataFile = personasFile
+	}
+
+	// 兴趣类比库配置
+	if analogiesDir := os.Getenv("ANALOGIES_DIR"); analogiesDir != "" {
+		config.Analogies.Dir = analogiesDir
+	}
+
+	// 语言配置
+	if defaultLocale := os.Getenv("DEFAULT_LOCALE"); defaultLocale != "" {
+		config.I18n.DefaultLocale = defaultLocale
+	}
+	if localesDir := os.Getenv("LOCALES_DIR"); localesDir != "" {
+		config.I18n.LocalesDir = localesDir
+	}
+
 	// 日志配置
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.Logging.Level = logLevel
@@ -306,6 +487,14 @@ func overrideFromEnv(config *Config) {
 	if debug := os.Getenv("DEBUG"); debug != "" {
 		config.Development.Debug = getEnvAsBool("DEBUG", false)
 	}
+
+	// 生产环境配置
+	if gzipEnabled := os.Getenv("GZIP_ENABLED"); gzipEnabled != "" {
+		config.Production.GzipEnabled = getEnvAsBool("GZIP_ENABLED", true)
+	}
+	if cacheTTL := os.Getenv("STATIC_CACHE_TTL"); cacheTTL != "" {
+		config.Production.StaticCacheTTL = getEnvAsInt("STATIC_CACHE_TTL", 86400)
+	}
 }
 
 // validateConfig 验证配置
@@ -331,6 +520,9 @@ func validateConfig(config *Config) error {
 	if config.Server.WriteTimeout < 1 {
 		config.Server.WriteTimeout = 30
 	}
+	if config.Auth.SessionTTL < 1 {
+		config.Auth.SessionTTL = 168
+	}
 
 	return nil
 }
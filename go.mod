@@ -5,7 +5,8 @@ go 1.22.12
 require (
 	github.com/gorilla/websocket v1.5.1
 	github.com/sashabaranov/go-openai v1.41.2
+	golang.org/x/crypto v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/net v0.17.0 // indirect
+require golang.org/x/net v0.21.0 // indirect
@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed analogies/*.yaml
+var builtinAnalogies embed.FS
+
+// DefaultInterest 没有探测到兴趣或兴趣没有类比时使用的领域
+const DefaultInterest = "游戏"
+
+// Analogy 兴趣类比：用熟悉的场景说明一个道理
+type Analogy struct {
+	Domain string   `yaml:"-" json:"domain"`
+	Image  string   `yaml:"image" json:"image"`                     // 类比的场景
+	Lesson string   `yaml:"lesson" json:"lesson"`                   // 场景说明的道理
+	Source []string `yaml:"source" json:"source,omitempty"`         // 场景本身涉及的概念
+	Target []string `yaml:"target" json:"target,omitempty"`         // 能说明的话题概念
+	Theme  string   `yaml:"theme,omitempty" json:"theme,omitempty"` // 适用的话题主题，与topicFrame.Theme对应
+}
+
+// Text 完整的类比句
+func (a Analogy) Text() string {
+	return "这就像" + a.Image + "——" + a.Lesson
+}
+
+// relevance 类比与话题的相关度：每个目标概念出现在话题中或属于话题的概念计2分，主题相符计1分
+func (a Analogy) relevance(topic string, concepts []string, theme string) int {
+	score := 0
+	lower := strings.ToLower(topic)
+	for _, target := range a.Target {
+		if strings.Contains(lower, strings.ToLower(target)) || contains(concepts, target) {
+			score += 2
+		}
+	}
+	if theme != "" && a.Theme == theme {
+		score++
+	}
+	return score
+}
+
+// ScoredAnalogy 带相关度的类比
+type ScoredAnalogy struct {
+	Analogy
+	Score int `json:"score"`
+}
+
+// analogyFile 类比库文件，每个文件属于一个领域，同一领域可以分成多个文件
+type analogyFile struct {
+	Domain    string    `yaml:"domain"`
+	Analogies []Analogy `yaml:"analogies"`
+}
+
+// AnalogyBase 按兴趣领域组织的类比知识库
+type AnalogyBase struct {
+	domains map[string][]Analogy
+}
+
+// DefaultAnalogyBase 返回内置类比库
+func DefaultAnalogyBase() *AnalogyBase {
+	base := &AnalogyBase{domains: make(map[string][]Analogy)}
+	if err := base.loadFS(builtinAnalogies, "analogies"); err != nil {
+		// 内置类比库有测试保证，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析内置类比库失败: %v", err))
+	}
+	return base
+}
+
+// LoadAnalogyBase 加载目录中的类比库文件（.yaml、.yml或.json）并合并到内置类比库，
+// 已有领域的类比追加在内置类比之后，新领域直接加入
+func LoadAnalogyBase(dir string) (*AnalogyBase, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("读取类比库目录失败: %w", err)
+	}
+	base := DefaultAnalogyBase()
+	if err := base.loadFS(os.DirFS(dir), "."); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// loadFS 加载目录中的类比库文件，按文件名顺序
+func (b *AnalogyBase) loadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("读取类比库目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return fmt.Errorf("读取类比库文件失败: %w", err)
+		}
+		// JSON是YAML的子集，两种格式都用YAML解析
+		var file analogyFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("解析类比库文件%s失败: %w", entry.Name(), err)
+		}
+		if err := b.add(file); err != nil {
+			return fmt.Errorf("类比库文件%s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// add 校验并加入一个文件中的类比
+func (b *AnalogyBase) add(file analogyFile) error {
+	domain := strings.TrimSpace(file.Domain)
+	if domain == "" {
+		return errors.New("缺少domain")
+	}
+	for i, analogy := range file.Analogies {
+		if strings.TrimSpace(analogy.Image) == "" || strings.TrimSpace(analogy.Lesson) == "" {
+			return fmt.Errorf("第%d条类比缺少image或lesson", i+1)
+		}
+		analogy.Domain = domain
+		b.domains[domain] = append(b.domains[domain], analogy)
+	}
+	return nil
+}
+
+// Domains 类比库中的全部领域，按名称排列
+func (b *AnalogyBase) Domains() []string {
+	return sortedKeys(b.domains)
+}
+
+// Analogies 某个领域的全部类比
+func (b *AnalogyBase) Analogies(domain string) []Analogy {
+	return b.domains[domain]
+}
+
+// Relevant 按与话题的相关度从高到低返回领域中相关的类比，domain为空时在全部领域中查找
+func (b *AnalogyBase) Relevant(domain, topic string) []ScoredAnalogy {
+	frame := matchFrame(topic)
+	concepts := append(append([]string(nil), frame.Keywords...), frame.Concepts...)
+
+	var candidates []Analogy
+	if domain != "" {
+		candidates = b.domains[domain]
+	} else {
+		for _, name := range b.Domains() {
+			candidates = append(candidates, b.domains[name]...)
+		}
+	}
+
+	var result []ScoredAnalogy
+	for _, analogy := range candidates {
+		if score := analogy.relevance(topic, concepts, frame.Theme); score > 0 {
+			result = append(result, ScoredAnalogy{Analogy: analogy, Score: score})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	return result
+}
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDefaultAnalogyBase 测试内置类比库
+func TestDefaultAnalogyBase(t *testing.T) {
+	base := DefaultAnalogyBase()
+	for _, domain := range []string{"游戏", "动漫", "体育", "科技", "文艺", "职场", "美食", "金融"} {
+		analogies := base.Analogies(domain)
+		if len(analogies) < 5 {
+			t.Fatalf("%s类比太少: %d", domain, len(analogies))
+		}
+		for _, analogy := range analogies {
+			if analogy.Domain != domain || len(analogy.Target) == 0 || len(analogy.Source) == 0 {
+				t.Fatalf("类比缺少领域或概念标签: %+v", analogy)
+			}
+		}
+	}
+
+	// 按话题概念匹配：网红书店话题在美食领域选中网红餐厅
+	relevant := base.Relevant("美食", "如何看待网红书店遍地开花")
+	if len(relevant) == 0 || !strings.Contains(relevant[0].Image, "网红餐厅") {
+		t.Fatalf("应选中与话题概念相关的类比: %+v", relevant)
+	}
+	if got := base.Relevant("美食", "今天天气怎么样"); len(got) != 0 {
+		t.Fatalf("没有相关概念时不应返回类比: %+v", got)
+	}
+}
+
+// TestLoadAnalogyBase 测试加载编辑维护的类比库目录
+func TestLoadAnalogyBase(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cooking.yaml": "domain: 美食\nanalogies:\n  - image: 慢炖老火汤\n    lesson: 火候到了味道自然出来\n    target: [坚持]\n",
+		"travel.json":  `{"domain": "旅行", "analogies": [{"image": "特种兵式旅游", "lesson": "景点打卡越多，记住的风景越少", "target": ["打卡"]}]}`,
+		"notes.txt":    "不是类比库文件",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base, err := LoadAnalogyBase(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	food := base.Analogies("美食")
+	if food[len(food)-1].Image != "慢炖老火汤" || len(food) != len(DefaultAnalogyBase().Analogies("美食"))+1 {
+		t.Fatalf("已有领域的类比应追加在内置类比之后: %+v", food)
+	}
+	if len(base.Analogies("旅行")) != 1 || base.Analogies("旅行")[0].Domain != "旅行" {
+		t.Fatalf("JSON文件中的新领域应加入: %v", base.Domains())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("domain: 旅行\nanalogies:\n  - image: 只有场景\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAnalogyBase(dir); err == nil {
+		t.Fatal("缺少lesson的类比应报错")
+	}
+	if _, err := LoadAnalogyBase(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("目录不存在时应返回错误")
+	}
+}
+
+// TestStyleResponseAnalogy 测试本地模拟回答引用相关类比
+func TestStyleResponseAnalogy(t *testing.T) {
+	ai := NewHanStyleAI()
+	response := ai.GenerateStyleResponse("hanhan", "大家都在加班内卷，你怎么看？", "")
+	if !strings.Contains(response, "这就像") {
+		t.Fatalf("应引用与内卷相关的类比: %s", response)
+	}
+	if plain := ai.GenerateStyleResponse("kanghui", "今天天气怎么样", ""); strings.Contains(plain, "打个比方") {
+		t.Fatalf("没有相关类比时不应附加: %s", plain)
+	}
+}
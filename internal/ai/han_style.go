@@ -1,10 +1,13 @@
 package ai
 
 import (
-	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
+
+	"reactedge/internal/analysis"
+	"reactedge/internal/i18n"
 )
 
 // ExpressionPattern 表达模式
@@ -18,10 +21,14 @@ type ExpressionPattern struct {
 
 // UserProfile 用户画像
 type UserProfile struct {
-	PrimaryInterest string   `json:"primary_interest"` // 游戏/动漫/体育/科技/文艺
+	PrimaryInterest string   `json:"primary_interest"` // 兴趣领域，与类比库的领域对应，如游戏/体育/职场
 	ThinkingStyle   string   `json:"thinking_style"`   // 归纳/演绎/类比
 	MetaphorStyle   string   `json:"metaphor_style"`   // 科技/文艺/生活
 	Strengths       []string `json:"strengths"`
+	Interests       []InterestScore `json:"interests,omitempty"` // 按分数排列的全部兴趣
+	Confidence      float64  `json:"confidence"`              // 主要兴趣的置信度 0-1
+	Samples         int      `json:"samples,omitempty"`       // 合并的回答数
+	AIAssisted      bool     `json:"ai_assisted,omitempty"`   // 是否有大模型参与判断
 }
 
 // ExpressionDNA 表达DNA分析结果
@@ -35,14 +42,17 @@ type ExpressionDNA struct {
 	UniquenessScore  int                 `json:"uniqueness_score"`  // 独特性分数
 	Recommendations  []string            `json:"recommendations"`   // 优化建议
 	NextChallenge    string              `json:"next_challenge"`    // 下次挑战
+	Language         analysis.Language   `json:"language,omitempty"` // 回答语言，建议按该语言给出
 }
 
 // HanStyleAI 韩寒风格AI引擎（现已扩展支持多风格）
 type HanStyleAI struct {
 	expressionPatterns []ExpressionPattern
-	gameAnalogies      map[string][]string
+	analogies          *AnalogyBase // 按兴趣领域组织的类比库
+	analogiesMutex     sync.RWMutex
 	hanStyleCorpus     []string
 	random             *rand.Rand
+	randomMutex        sync.Mutex
 }
 
 // NewHanStyleAI 创建韩寒风格AI引擎（现已扩展支持多风格）
@@ -54,7 +64,7 @@ func NewHanStyleAI() *HanStyleAI {
 	}
 
 	ai.initializeExpressionPatterns()
-	ai.initializeGameAnalogies()
+	ai.analogies = DefaultAnalogyBase()
 	ai.initializeHanCorpus()
 
 	return ai
@@ -106,41 +116,35 @@ func (ai *HanStyleAI) initializeExpressionPatterns() {
 	}
 }
 
-// initializeGameAnalogies 初始化游戏类比库
-func (ai *HanStyleAI) initializeGameAnalogies() {
-	ai.gameAnalogies = map[string][]string{
-		"游戏": {
-			"这就像《塞尔达》里到处是神庙但解谜都很简单——数量多了，质量却被稀释了",
-			"现在的现象就像游戏里的外挂，短期好用，但破坏了游戏平衡",
-			"这和MOBA游戏一样，团队重要性超过个人英雄主义",
-			"就像RPG游戏，升级不能只看经验值，更要看技能成长",
-		},
-		"动漫": {
-			"这就像动漫里的热血少年，明明弱小却总想挑战强者",
-			"现在的文化现象像动漫里的模板剧情，套路化却总有人买账",
-			"就像《死亡笔记》，权力越大，责任越重，却也越危险",
-		},
-		"体育": {
-			"这就像足球比赛，战术重要，但个人灵感决定胜负",
-			"现在的竞争像马拉松，不是冲刺就能赢的",
-			"就像篮球明星，数据好看但团队配合才出冠军",
-		},
-		"科技": {
-			"这就像智能手机，功能越来越多，但很多人只会刷抖音",
-			"现在的创新像AI发展，潜力巨大但伦理风险同样大",
-			"就像互联网思维，连接一切却也放大了一切问题",
-		},
-		"文艺": {
-			"这就像诗歌朗诵，形式优美但内容空洞最可怕",
-			"现在的创作像绘画临摹，技术娴熟却缺少灵魂",
-			"就像音乐节，热闹喧嚣但真正在听歌的人不多",
-		},
-	}
+// intn 并发安全地生成[0, n)的随机数
+func (ai *HanStyleAI) intn(n int) int {
+	ai.randomMutex.Lock()
+	defer ai.randomMutex.Unlock()
+	return ai.random.Intn(n)
+}
+
+// AnalogyBase 返回当前使用的类比库
+func (ai *HanStyleAI) AnalogyBase() *AnalogyBase {
+	ai.analogiesMutex.RLock()
+	defer ai.analogiesMutex.RUnlock()
+	return ai.analogies
+}
+
+// SetAnalogyBase 替换类比库，例如加载了编辑维护的类比库目录之后
+func (ai *HanStyleAI) SetAnalogyBase(base *AnalogyBase) {
+	ai.analogiesMutex.Lock()
+	defer ai.analogiesMutex.Unlock()
+	ai.analogies = base
 }
 
 // initializeHanCorpus 初始化韩寒语料库（简化版）
 func (ai *HanStyleAI) initializeHanCorpus() {
-	ai.hanStyleCorpus = []string{
+	ai.hanStyleCorpus = HanStyleCorpus()
+}
+
+// HanStyleCorpus 韩寒风格语料，离线风格分类器也用它训练
+func HanStyleCorpus() []string {
+	return []string{
 		"很多人说这是好事，但好事有时候是最可怕的陷阱",
 		"表面上看是繁荣，实际上暴露了我们用'打卡'代替'阅读'的虚荣",
 		"当书店开始比拼装修而不是书目，这和奶茶店比杯子颜值有什么区别",
@@ -157,53 +161,40 @@ func (ai *HanStyleAI) GetExpressionPatterns() []ExpressionPattern {
 	return ai.expressionPatterns
 }
 
-// GeneratePersonalizedTemplate 生成个性化模板
+// GeneratePersonalizedTemplate 生成个性化模板，按话题、兴趣和思维方式组装四步应答框架
 func (ai *HanStyleAI) GeneratePersonalizedTemplate(profile UserProfile, topic string) string {
-	var template strings.Builder
-
-	// 根据兴趣选择类比
-	analogies := ai.gameAnalogies[profile.PrimaryInterest]
-	if len(analogies) == 0 {
-		analogies = ai.gameAnalogies["游戏"] // 默认游戏类比
-	}
-
-	gameAnalogy := analogies[ai.random.Intn(len(analogies))]
-
-	// 构建个性化模板
-	template.WriteString(fmt.Sprintf("老师，我觉得%s——\n", gameAnalogy))
-	template.WriteString("数量多了，质量却被稀释了。\n")
-	template.WriteString("表面上是书店繁荣，实际上暴露了我们用'打卡'代替'阅读'的虚荣。\n")
-	template.WriteString("如果书店变成拍照背景板，那和游戏里的贴图BUG有什么区别？")
-
-	return template.String()
+	return ai.ComposeTemplate(profile, topic).Text()
 }
 
-// AnalyzeExpressionDNA 分析表达DNA
+// AnalyzeExpressionDNA 分析表达DNA，按回答语言选用标记词；模式和标签沿用中文名称，便于跨语言比较
 func (ai *HanStyleAI) AnalyzeExpressionDNA(userSpeech string, profile UserProfile) ExpressionDNA {
 	// 简单的分析逻辑（实际项目中会更复杂）
+	language := analysis.DetectLanguage(userSpeech)
+	markers := language.Profile()
+
 	// 计算犀利指数
-	sharpenessScore := 60 + ai.random.Intn(40) // 60-99随机
+	sharpenessScore := sharpnessScore(userSpeech, markers)
 
 	// 检测思维模式
-	thinkingPattern := ai.detectThinkingPattern(userSpeech)
+	thinkingPattern := ai.detectThinkingPattern(userSpeech, markers)
 
 	// 检测类比风格
-	metaphorStyle := ai.detectMetaphorStyle(userSpeech)
+	metaphorStyle := ai.detectMetaphorStyle(userSpeech, markers)
 
 	// 检测节奏特征
-	rhythmSignature := ai.detectRhythmSignature(userSpeech)
+	rhythmSignature := ai.detectRhythmSignature(userSpeech, markers)
 
 	// 计算独特性
-	uniquenessScore := 70 + ai.random.Intn(30)
+	uniquenessScore := uniquenessScore(userSpeech, markers)
 
 	// 生成个性标签
-	personalityTags := ai.generatePersonalityTags(profile, userSpeech)
+	personalityTags := ai.generatePersonalityTags(profile, userSpeech, markers)
 
 	// 生成独特模式
 	uniquePatterns := ai.generateUniquePatterns(userSpeech)
 
 	// 生成优化建议
-	recommendations := ai.generateRecommendations(userSpeech)
+	recommendations := ai.generateRecommendations(userSpeech, markers)
 
 	// 生成下次挑战
 	nextChallenge := ai.generateNextChallenge(profile)
@@ -218,62 +209,109 @@ func (ai *HanStyleAI) AnalyzeExpressionDNA(userSpeech string, profile UserProfil
 		UniquenessScore: uniquenessScore,
 		Recommendations: recommendations,
 		NextChallenge:   nextChallenge,
+		Language:        language,
 	}
 }
 
 // detectThinkingPattern 检测思维模式
-func (ai *HanStyleAI) detectThinkingPattern(speech string) string {
-	if strings.Contains(speech, "就像") || strings.Contains(speech, "好像") {
+func (ai *HanStyleAI) detectThinkingPattern(speech string, markers *analysis.LanguageProfile) string {
+	if analysis.HasMarker(speech, markers.Analogy) {
 		return "类比思维"
 	}
-	if strings.Contains(speech, "因为") || strings.Contains(speech, "所以") {
+	if analysis.HasMarker(speech, markers.Causal) {
 		return "逻辑推理"
 	}
-	if strings.Contains(speech, "我觉得") || strings.Contains(speech, "我认为") {
+	if analysis.HasMarker(speech, markers.Opinion) {
 		return "主观判断"
 	}
 	return "现象描述"
 }
 
 // detectMetaphorStyle 检测类比风格
-func (ai *HanStyleAI) detectMetaphorStyle(speech string) string {
-	if strings.Contains(speech, "游戏") || strings.Contains(speech, "塞尔达") {
-		return "游戏思维"
-	}
-	if strings.Contains(speech, "电影") || strings.Contains(speech, "音乐") {
-		return "文艺表达"
-	}
-	if strings.Contains(speech, "手机") || strings.Contains(speech, "互联网") {
-		return "科技视角"
+func (ai *HanStyleAI) detectMetaphorStyle(speech string, markers *analysis.LanguageProfile) string {
+	for _, metaphor := range markers.Metaphors {
+		if analysis.HasMarker(speech, metaphor.Cues) {
+			return metaphor.Style
+		}
 	}
 	return "生活类比"
 }
 
 // detectRhythmSignature 检测节奏特征
-func (ai *HanStyleAI) detectRhythmSignature(speech string) string {
-	sentences := strings.Split(speech, "。")
-	if len(sentences) > 3 {
+func (ai *HanStyleAI) detectRhythmSignature(speech string, markers *analysis.LanguageProfile) string {
+	if markers.CountSentences(speech) >= 3 {
 		return "层次递进"
 	}
-	if strings.Contains(speech, "但") || strings.Contains(speech, "却") {
+	if analysis.HasMarker(speech, markers.Contrast) {
 		return "转折对比"
 	}
 	return "直线表达"
 }
 
+// sharpnessScore 犀利指数（0-100）：发问、转折、追问本质和明确表态加分，空洞的程度副词扣分
+func sharpnessScore(speech string, markers *analysis.LanguageProfile) int {
+	score := 40
+	questions := strings.Count(speech, "？") + strings.Count(speech, "?")
+	if questions > 2 {
+		questions = 2
+	}
+	score += 10 * questions
+	for _, cues := range [][]string{markers.Contrast, markers.Essence, markers.Confident} {
+		if analysis.HasMarker(speech, cues) {
+			score += 10
+		}
+	}
+	score -= 5 * analysis.CountMarkers(speech, markers.Intensifiers)
+	return clampScore(score)
+}
+
+// uniquenessScore 独特性分数（0-100）：类比、具体例子、因果推理和多层次表达越丰富分数越高
+func uniquenessScore(speech string, markers *analysis.LanguageProfile) int {
+	score := 40
+	if analysis.HasMarker(speech, markers.Analogy) {
+		score += 20
+	}
+	for _, metaphor := range markers.Metaphors {
+		if analysis.HasMarker(speech, metaphor.Cues) {
+			score += 10
+			break
+		}
+	}
+	for _, cues := range [][]string{markers.Examples, markers.Causal} {
+		if analysis.HasMarker(speech, cues) {
+			score += 10
+		}
+	}
+	if markers.CountSentences(speech) >= 3 {
+		score += 10
+	}
+	return clampScore(score)
+}
+
+// clampScore 把分数限制在0-100
+func clampScore(score int) int {
+	if score > 100 {
+		return 100
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
 // generatePersonalityTags 生成个性标签
-func (ai *HanStyleAI) generatePersonalityTags(profile UserProfile, speech string) []string {
+func (ai *HanStyleAI) generatePersonalityTags(profile UserProfile, speech string, markers *analysis.LanguageProfile) []string {
 	tags := []string{}
 
 	if profile.PrimaryInterest == "游戏" {
 		tags = append(tags, "游戏思维者")
 	}
 
-	if strings.Contains(speech, "本质") || strings.Contains(speech, "真正") {
+	if analysis.HasMarker(speech, markers.Essence) {
 		tags = append(tags, "本质追问者")
 	}
 
-	if strings.Count(speech, "？") > 1 {
+	if strings.Count(speech, "？")+strings.Count(speech, "?") > 1 {
 		tags = append(tags, "犀利发问者")
 	}
 
@@ -297,23 +335,23 @@ func (ai *HanStyleAI) generateUniquePatterns(speech string) []string {
 	return patterns
 }
 
-// generateRecommendations 生成优化建议
-func (ai *HanStyleAI) generateRecommendations(speech string) []string {
+// generateRecommendations 生成优化建议，使用回答语言
+func (ai *HanStyleAI) generateRecommendations(speech string, markers *analysis.LanguageProfile) []string {
 	recommendations := []string{}
 
-	if !strings.Contains(speech, "就像") {
+	if !analysis.HasMarker(speech, markers.Analogy) {
 		recommendations = append(recommendations,
-			"可以尝试加入具体类比，让观点更生动")
+			i18n.T(markers.Locale, "dna.recommend.analogy"))
 	}
 
-	if strings.Count(speech, "很") > 2 {
+	if analysis.CountMarkers(speech, markers.Intensifiers) > 2 {
 		recommendations = append(recommendations,
-			"减少'很'字的使用，尝试更精准的形容词")
+			i18n.T(markers.Locale, "dna.recommend.intensifier"))
 	}
 
 	if len(recommendations) == 0 {
 		recommendations = append(recommendations,
-			"你的表达已经很犀利了，继续保持！")
+			i18n.T(markers.Locale, "dna.recommend.keep"))
 	}
 
 	return recommendations
@@ -328,104 +366,26 @@ func (ai *HanStyleAI) generateNextChallenge(profile UserProfile) string {
 		"你觉得现在的教育像什么？用一个你熟悉的事物来比喻",
 	}
 
-	return challenges[ai.random.Intn(len(challenges))]
+	return challenges[ai.intn(len(challenges))]
 }
 
 // DetectUserProfile 从用户输入中探测用户画像
 func (ai *HanStyleAI) DetectUserProfile(speech string) UserProfile {
-	profile := UserProfile{}
-
-	// 检测主要兴趣
-	if strings.Contains(speech, "游戏") || strings.Contains(speech, "塞尔达") {
-		profile.PrimaryInterest = "游戏"
-	} else if strings.Contains(speech, "动漫") || strings.Contains(speech, "热血") {
-		profile.PrimaryInterest = "动漫"
-	} else if strings.Contains(speech, "足球") || strings.Contains(speech, "篮球") {
-		profile.PrimaryInterest = "体育"
-	} else if strings.Contains(speech, "手机") || strings.Contains(speech, "互联网") {
-		profile.PrimaryInterest = "科技"
-	} else {
-		profile.PrimaryInterest = "文艺"
-	}
-
-	// 检测思维风格
-	profile.ThinkingStyle = ai.detectThinkingPattern(speech)
-	profile.MetaphorStyle = ai.detectMetaphorStyle(speech)
-
-	// 分析优势
-	profile.Strengths = []string{"敢于表达观点", "善于观察生活"}
-
-	return profile
-}
-
-// GenerateStyleResponse 根据指定风格生成回答
-func (ai *HanStyleAI) GenerateStyleResponse(style, question, content string) string {
-	// 移除引号
-	question = strings.Trim(question, "\"")
-
-	switch style {
-	case "kanghui":
-		return ai.generateKanghuiResponse(question, content)
-	case "dongqing":
-		return ai.generateDongqingResponse(question, content)
-	case "hanhan":
-		return ai.generateHanhanResponse(question, content)
-	case "chengming":
-		return ai.generateChengmingResponse(question, content)
-	default:
-		return ai.generateHanhanResponse(question, content)
-	}
-}
-
-// generateKanghuiResponse 生成康辉式回答（专业得体）
-func (ai *HanStyleAI) generateKanghuiResponse(question, content string) string {
-	// 分析问题类型并生成相应回答
-	if strings.Contains(question, "ROI") || strings.Contains(question, "数据") || strings.Contains(question, "业绩") {
-		return "根据我们的统计数据显示，这个项目的投资回报率虽然暂时偏低，但从长期战略角度来看，实际上体现了我们对可持续发展的重视。数据显示，类似的项目在初期投入后，三年内的复合增长率可以达到15%以上。重要的是，我们要从国家战略高度和行业发展趋势来审视这个问题。"
-	}
-
-	if strings.Contains(question, "技术") || strings.Contains(question, "方案") || strings.Contains(question, "可行性") {
-		return "从技术实现的角度来看，我们采用了业界最先进的解决方案。数据显示，类似的技术方案在过去两年的应用中，成功率达到了92%。关键是要建立完整的技术评估体系，从需求分析、架构设计到实施落地的全流程质量控制。"
-	}
-
-	return "这个问题值得我们深入探讨。从数据统计的角度分析，当前的情况既有挑战性，也充满了机遇。我们需要用发展的眼光看待问题，既要看到短期困难，更要把握长期趋势。数据显示，在类似情况下，企业通过技术创新和流程优化，往往能够实现质的飞跃。"
+	return ai.DetectProfileEvidence(speech).Profile()
 }
 
-// generateDongqingResponse 生成董卿式回答（温婉大气）
-func (ai *HanStyleAI) generateDongqingResponse(question, content string) string {
-	if strings.Contains(question, "质疑") || strings.Contains(question, "不同意") || strings.Contains(question, "不切实际") {
-		return "我非常理解您的顾虑和担心。每个人在面对新的想法时，都会有自己的思考和担忧，这是很正常的现象。让我来和您一起探讨这个问题的不同层面。我们能不能先从对方的角度来理解一下，这种担忧背后的真正关切是什么？有时候，表面的分歧往往来自于对彼此需求的误解。"
-	}
-
-	if strings.Contains(question, "ROI") || strings.Contains(question, "数据") || strings.Contains(question, "业绩") {
-		return "我能感受到您对这个数据表现的关注和焦虑。这确实是一个值得我们认真对待的问题。让我来和您分享一下我们在这个过程中的一些思考和体会。有时候，数字背后的故事比数字本身更重要。我们一起看看能不能找到一些温暖人心的解决方案。"
+// DetectProfileEvidence 从一次回答中提取画像证据，可以与之前回答的证据合并
+func (ai *HanStyleAI) DetectProfileEvidence(speech string) *ProfileEvidence {
+	evidence := DefaultProfileDetector().Detect(speech)
+	if evidence.Submissions > 0 {
+		if style := ai.detectMetaphorStyle(speech, analysis.DetectLanguage(speech).Profile()); style != defaultMetaphorStyle {
+			evidence.Metaphors[style]++
+		}
 	}
-
-	return "您的这个问题真的很打动我，它触及到了我们每个人都会面对的现实挑战。生活总是充满了各种不确定性，但也正因如此，我们才有机会去探索、去成长。让我和您一起，从更宽广的角度来看待这个问题，也许我们能找到一些温暖而有力的答案。"
+	return evidence
 }
 
-// generateHanhanResponse 生成韩寒式回答（犀利直接）
-func (ai *HanStyleAI) generateHanhanResponse(question, content string) string {
-	if strings.Contains(question, "质疑") || strings.Contains(question, "不同意") || strings.Contains(question, "不切实际") {
-		return "如果这个想法真的那么不切实际，为什么还有那么多人在做类似的事情？难道成功者都是傻子，而只有质疑者才最清醒？有时候我们质疑的不是方案本身，而是我们内心的恐惧和不愿意改变的惰性。如果大家都像您这么'务实'，那这个世界恐怕早就停止进步了。"
-	}
-
-	if strings.Contains(question, "ROI") || strings.Contains(question, "数据") || strings.Contains(question, "业绩") {
-		return "ROI低？那又怎么样？难道所有的价值都能用数字精确衡量吗？如果乔布斯当年也只看ROI，苹果还会存在吗？有时候，最有价值的投资恰恰是那些短期ROI看起来不那么漂亮的。因为那些数字背后，是对未来的赌注，是对变革的勇气。质疑数据的人，往往最害怕面对真正的创新。"
-	}
-
-	return "你的这个问题让我想起一句话：当你凝视深渊时，深渊也在凝视着你。那些动不动就说'不现实'的人，往往是那些从来没有尝试过改变的人。他们质疑的不是方案，而是自己的能力和勇气。如果大家都像你这么'理性'，那人类恐怕还在茹毛饮血的时代。"
-}
-
-// generateChengmingResponse 生成成铭式回答（逻辑严谨）
-func (ai *HanStyleAI) generateChengmingResponse(question, content string) string {
-	if strings.Contains(question, "质疑") || strings.Contains(question, "不同意") || strings.Contains(question, "不切实际") {
-		return "让我们从逻辑的角度来分析这个问题。您质疑这个方案不切实际，那么我请问：您的'实际'标准是什么？是基于历史数据统计，还是个人经验判断？如果我们承认您的逻辑前提，那么按照同样的推理，我们就应该否定历史上所有的重大创新。因为按照'实际'的标准，电话、互联网、飞机这些东西在发明前都是'不切实际'的。"
-	}
-
-	if strings.Contains(question, "ROI") || strings.Contains(question, "数据") || strings.Contains(question, "业绩") {
-		return "让我们从成本结构和投资回报的本质来分析。表面上看15%的增长似乎不高，但如果我们深入分析这个数字的构成，就会发现其中隐藏着更大的机会。关键不在于数字本身，而在于我们如何重新定义和优化这些变量之间的关系。很多时候，所谓的低ROI，其实是低效运营的反映，而不是战略方向的问题。"
-	}
-
-	return "这个问题很有意思，让我们从几个维度来层层分析。首先从现象层面来看，然后深入到本质原因，最后探讨解决方案的可能性。这样的分析框架能帮助我们避免片面性，避免用战术层面的困难否定战略层面的价值。重要的是建立正确的思维模型，而不是停留在表面现象的判断。"
+// GenerateStyleResponse 根据指定风格生成回答，不依赖大模型
+func (ai *HanStyleAI) GenerateStyleResponse(style, question, content string) string {
+	return ai.composeOfflineResponse(style, ai.AnalyzeQuestion(question))
 }
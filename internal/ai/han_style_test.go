@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestAnalyzeExpressionDNAConcurrent 测试共享的HanStyleAI可被并发调用
+func TestAnalyzeExpressionDNAConcurrent(t *testing.T) {
+	hanAI := NewHanStyleAI()
+	profile := hanAI.DetectUserProfile("这就像游戏里的副本，数量多了质量却稀释了")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				dna := hanAI.AnalyzeExpressionDNA("因为内卷就像游戏刷副本，所以大家都很累", profile)
+				if dna.NextChallenge == "" {
+					t.Error("下次挑战不应为空")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestExpressionDNAScoresFollowMarkers 测试犀利指数和独特性由回答内容决定
+func TestExpressionDNAScoresFollowMarkers(t *testing.T) {
+	hanAI := NewHanStyleAI()
+	profile := hanAI.DetectUserProfile("")
+
+	plain := "我觉得书店挺好的。"
+	sharp := "书店越开越多，真的说明大家爱读书吗？但本质上这就像奶茶店比拼杯子设计，因为装修比书更好卖。所以问题到底出在哪里？"
+
+	first := hanAI.AnalyzeExpressionDNA(sharp, profile)
+	again := hanAI.AnalyzeExpressionDNA(sharp, profile)
+	if first.SharpenessScore != again.SharpenessScore || first.UniquenessScore != again.UniquenessScore {
+		t.Fatalf("同一回答的分数应保持一致: %+v %+v", first, again)
+	}
+
+	flat := hanAI.AnalyzeExpressionDNA(plain, profile)
+	if first.SharpenessScore <= flat.SharpenessScore {
+		t.Errorf("发问和转折应提高犀利指数: %d <= %d", first.SharpenessScore, flat.SharpenessScore)
+	}
+	if first.UniquenessScore <= flat.UniquenessScore {
+		t.Errorf("类比和因果推理应提高独特性: %d <= %d", first.UniquenessScore, flat.UniquenessScore)
+	}
+}
@@ -0,0 +1,303 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Concern 问题的关切类型
+type Concern string
+
+const (
+	ConcernDoubt       Concern = "doubt"       // 质疑方案或想法
+	ConcernValue       Concern = "value"       // 投入产出、业绩数据
+	ConcernDelay       Concern = "delay"       // 进度延期
+	ConcernConflict    Concern = "conflict"    // 团队分歧
+	ConcernRisk        Concern = "risk"        // 风险和后果
+	ConcernFeasibility Concern = "feasibility" // 技术方案和落地
+	ConcernOpinion     Concern = "opinion"     // 询问看法
+	ConcernGeneral     Concern = "general"
+)
+
+// concernRules 关切类型的关键词，按优先级排列：先匹配到的优先
+var concernRules = []struct {
+	concern  Concern
+	label    string // 回答中提到关切时的说法
+	keywords []string
+}{
+	{ConcernDoubt, "可行性", []string{"质疑", "不同意", "不切实际", "不可行", "行不通", "不靠谱", "反对", "怀疑", "没用"}},
+	{ConcernValue, "投入产出", []string{"roi", "回报", "业绩", "数据", "成本", "预算", "投入", "收益", "赚钱", "亏损"}},
+	{ConcernDelay, "进度", []string{"延期", "进度", "来不及", "拖延", "deadline", "赶不上", "推迟"}},
+	{ConcernConflict, "协作", []string{"冲突", "矛盾", "吵架", "分歧", "不配合", "甩锅"}},
+	{ConcernRisk, "风险", []string{"风险", "失败", "担心", "后果", "出问题", "万一"}},
+	{ConcernFeasibility, "落地", []string{"技术", "方案", "可行性", "落地", "实现", "执行"}},
+	{ConcernOpinion, "看法", []string{"怎么看", "如何看待", "看法", "你觉得", "评价"}},
+}
+
+// stakeholderWords 问题中可能出现的相关方，"有人"等泛指统一成"大家"
+var stakeholderWords = []string{
+	"领导", "老板", "上级", "经理", "总监", "客户", "用户", "同事", "团队", "下属",
+	"投资人", "股东", "合作方", "甲方", "评委", "面试官", "老师", "家长", "同学", "有人",
+}
+
+// 问题中的转述（如"领导问我"），之后才是问题本身
+var reportingPattern = regexp.MustCompile(`^.{0,12}?(问我|问|说我|说|质疑|觉得|认为|批评|指出|反对|抱怨)`)
+
+// 提取主题时去掉的开头和结尾，以及主题之后的评价或疑问
+var (
+	subjectLeads   = []string{"我们的", "我的", "你们的", "你的", "我们", "一下"}
+	questionLeads  = []string{"应该如何", "应该怎么", "该如何", "该怎么", "如何", "怎么", "怎样"}
+	decisionLeads  = []string{"要不要", "该不该", "能不能", "是否"}
+	actionLeads    = []string{"处理", "应对", "面对", "汇报", "解决", "看待", "回应", "说服", "做好"}
+	subjectCuts    = []string{"为什么", "什么时候", "怎么", "如何", "是不是", "能不能", "会不会", "不可行", "不切实际", "行不通", "不靠谱", "没用", "有问题", "太", "吗"}
+	subjectTrails  = []string{"了", "的", "吗", "呢", "啊"}
+	maxSubjectRune = 20
+	minClauseRunes = 4
+)
+
+// QuestionAnalysis 从问题中提取的主题、相关方和关切
+type QuestionAnalysis struct {
+	Question     string   `json:"question"`
+	Subject      string   `json:"subject"`      // 问题讨论的对象，如"这个项目的ROI"
+	Stakeholders []string `json:"stakeholders"` // 问题涉及的人，没有时为"大家"
+	Concern      Concern  `json:"concern"`
+	ConcernLabel string   `json:"concern_label"`
+}
+
+// AnalyzeQuestion 用规则提取问题的主题、相关方和关切类型
+func (ai *HanStyleAI) AnalyzeQuestion(question string) QuestionAnalysis {
+	question = strings.TrimSpace(strings.Trim(strings.TrimSpace(question), "\"“”"))
+	analysis := QuestionAnalysis{
+		Question:     question,
+		Subject:      questionSubject(question),
+		Concern:      ConcernGeneral,
+		ConcernLabel: "这个问题",
+	}
+
+	lower := strings.ToLower(question)
+	for _, rule := range concernRules {
+		if containsAny(lower, rule.keywords) {
+			analysis.Concern, analysis.ConcernLabel = rule.concern, rule.label
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, word := range stakeholderWords {
+		if strings.Contains(question, word) {
+			if word == "有人" {
+				word = "大家"
+			}
+			if !seen[word] {
+				seen[word] = true
+				analysis.Stakeholders = append(analysis.Stakeholders, word)
+			}
+		}
+	}
+	if len(analysis.Stakeholders) == 0 {
+		analysis.Stakeholders = []string{"大家"}
+	}
+	return analysis
+}
+
+// questionSubject 提取问题讨论的对象：常见话题用话题框架，其余去掉转述、疑问词和评价
+func questionSubject(question string) string {
+	if frame := matchFrame(question); frame.Theme != "" {
+		return frame.Subject
+	}
+
+	// 问题前面可能有交代场合的短句（如"开会时，领导问我……"），取第一个足够长的子句
+	clauses := strings.FieldsFunc(extractSubject(question), func(r rune) bool {
+		return strings.ContainsRune("，,。？?！!；;：:", r)
+	})
+	subject := ""
+	for _, clause := range clauses {
+		if utf8.RuneCountInString(clause) >= minClauseRunes {
+			subject = clause
+			break
+		}
+	}
+	if subject == "" && len(clauses) > 0 {
+		subject = clauses[0]
+	}
+	if loc := reportingPattern.FindStringIndex(subject); loc != nil && loc[1] < len(subject) {
+		subject = subject[loc[1]:]
+	}
+
+	subject = trimPrefixes(trimPrefixes(subject, subjectLeads), decisionLeads)
+	if trimmed := trimPrefixes(subject, questionLeads); trimmed != subject {
+		subject = trimPrefixes(trimmed, actionLeads)
+	}
+	for _, cut := range subjectCuts {
+		if index := strings.Index(subject, cut); index > 0 {
+			subject = subject[:index]
+		}
+	}
+	for _, trail := range subjectTrails {
+		subject = strings.TrimSuffix(subject, trail)
+	}
+
+	subject = strings.TrimSpace(subject)
+	if subject == "" || utf8.RuneCountInString(subject) > maxSubjectRune {
+		return "这个问题"
+	}
+	return subject
+}
+
+// trimPrefixes 去掉第一个匹配的开头
+func trimPrefixes(text string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(text, prefix) {
+			return strings.TrimPrefix(text, prefix)
+		}
+	}
+	return text
+}
+
+// containsAny 文本中是否出现任意一个关键词
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// offlineSkeleton 一种风格的回答骨架：开场、按关切展开的主体和收尾，开场和收尾随机选择
+// 占位符：{主题} 问题讨论的对象，{对象} 第一个相关方，{关切} 关切的说法
+type offlineSkeleton struct {
+	openings []string
+	bodies   map[Concern]string
+	analogy  string // 引入类比的句式，参数依次为场景和道理
+	closings []string
+}
+
+// offlineSkeletons 四种内置风格的回答骨架
+var offlineSkeletons = map[string]offlineSkeleton{
+	"kanghui": {
+		openings: []string{
+			"关于{主题}，{对象}的关注很有必要，我想从三个方面来回应。",
+			"{对象}提到的{主题}，是我们必须认真对待的问题。",
+			"谢谢{对象}的提问。围绕{主题}，我简要汇报几点。",
+		},
+		bodies: map[Concern]string{
+			ConcernDoubt:       "第一，{主题}不是凭空提出的，前期已经做了充分的调研和论证；第二，任何新方案在起步阶段都会遇到质疑，关键看是否有清晰的评估标准；第三，我们愿意把关键指标公开，接受大家的检验。",
+			ConcernValue:       "从数据来看，{主题}的短期回报确实还没有完全显现，但{关切}需要放在更长的周期里衡量。我们会按季度跟踪核心指标，用事实说话。",
+			ConcernDelay:       "{主题}目前的{关切}与原计划存在差距，原因主要在于需求变化和资源调配。我们已经制定了调整后的时间表，并明确了每个节点的责任人。",
+			ConcernConflict:    "{主题}反映出的是{关切}机制的问题，而不是个人之间的问题。建议明确分工和决策流程，让分歧在规则内得到解决。",
+			ConcernRisk:        "对于{主题}可能带来的{关切}，我们已经做了分级评估，并为关键环节准备了预案，确保风险可知、可控、可应对。",
+			ConcernFeasibility: "在{主题}的{关切}上，我们采取分阶段推进的方式：先小范围验证，再根据数据逐步推广，每一步都有明确的验收标准。",
+			ConcernOpinion:     "看待{主题}，既要看到它带来的新变化，也要看到其中需要规范和引导的地方，客观、全面、理性是基本的态度。",
+			ConcernGeneral:     "对于{主题}，我们的原则是实事求是：先把情况摸清，再把目标定准，最后把责任落实。",
+		},
+		analogy: "打个比方，这就好比%s，%s。",
+		closings: []string{
+			"总之，我们有信心、也有能力把这项工作做好。",
+			"以上是我的汇报，请{对象}批评指正。",
+			"下一步，我们会持续跟进，及时向{对象}汇报进展。",
+		},
+	},
+	"dongqing": {
+		openings: []string{
+			"我特别理解{对象}对{主题}的这份关心。",
+			"{对象}的这个问题，让我想停下来，好好地想一想{主题}。",
+			"谢谢{对象}这么坦诚地说出对{主题}的想法。",
+		},
+		bodies: map[Concern]string{
+			ConcernDoubt:       "每一个新的想法，在最初的时候都像一颗种子，谁也看不见它将来会长成什么样子。质疑并不可怕，它提醒我们把根扎得更深一些。",
+			ConcernValue:       "数字当然重要，它告诉我们走了多远；可数字背后，还有一起熬过的夜、一点点积累起来的信任，这些同样是{主题}的收获。",
+			ConcernDelay:       "慢下来并不一定是坏事。{主题}走得慢一些，也许正是为了走得更稳、更远。",
+			ConcernConflict:    "有分歧，恰恰说明每个人都在用心。{主题}需要的不是谁说服谁，而是彼此多一点倾听。",
+			ConcernRisk:        "面对{主题}的不确定，担心是人之常情。但正因为有{关切}，我们才更要彼此托付、一起面对。",
+			ConcernFeasibility: "{主题}能不能落地，不只取决于方案本身，更取决于参与其中的每一个人是否愿意为它多走一步。",
+			ConcernOpinion:     "{主题}就像一面镜子，照见的是这个时代的期待，也照见了我们每个人内心的选择。",
+			ConcernGeneral:     "{主题}看似是一个具体的问题，其实关乎我们怎样对待工作，也关乎我们怎样对待彼此。",
+		},
+		analogy: "这让我想起%s，%s。",
+		closings: []string{
+			"愿我们都能在这件事里，看见彼此的用心。",
+			"我相信，只要初心还在，答案就会慢慢清晰。",
+			"让我们一起，把这段路走得更温暖一些。",
+		},
+	},
+	"hanhan": {
+		openings: []string{
+			"说到{主题}，我先问一句：{对象}真正担心的是什么？",
+			"{主题}？这个问题本身就挺有意思的。",
+			"很多人一提到{主题}就皱眉，但我想反过来看看。",
+		},
+		bodies: map[Concern]string{
+			ConcernDoubt:       "如果每个新想法都要先证明自己一定成功才能开始，那这个世界上大概什么都不会发生。说{主题}不切实际的人，往往只是没见过它成功的样子。",
+			ConcernValue:       "用{关切}衡量一切当然很方便，可如果只看短期数字，很多今天看起来最划算的选择，明天都会变成最贵的代价。{主题}值不值，不该只由一张报表决定。",
+			ConcernDelay:       "延期不可怕，可怕的是明明知道问题在哪却假装一切正常。与其把{主题}包装成好消息，不如把真实情况摊开来。",
+			ConcernConflict:    "团队里没有冲突，通常不是因为大家想法一致，而是大家都懒得说真话。{主题}吵出来，总比憋出来好。",
+			ConcernRisk:        "不做{主题}也有风险，只是那种风险不会写进报告里。",
+			ConcernFeasibility: "方案能不能落地，从来不是写在PPT上的，是做出来的。{主题}先做一小步，比争论一百次更有说服力。",
+			ConcernOpinion:     "表面上大家在讨论{主题}，实际上是在讨论自己愿不愿意改变。",
+			ConcernGeneral:     "{主题}看起来复杂，其实往往只是因为没人愿意先说那句大实话。",
+		},
+		analogy: "这就像%s——%s。",
+		closings: []string{
+			"难道我们要因为害怕犯错，就什么都不做吗？",
+			"至于结果，让时间去回答。",
+			"说到底，问题从来不在{主题}本身，而在我们怎么看它。",
+		},
+	},
+	"chengming": {
+		openings: []string{
+			"关于{主题}，我想先把问题拆开来看。",
+			"{对象}的问题可以分成两层：事实和判断。我们先从{主题}的事实说起。",
+			"讨论{主题}之前，我们需要先统一判断的标准。",
+		},
+		bodies: map[Concern]string{
+			ConcernDoubt:       "说{主题}不切实际，前提是我们对\"实际\"有明确的标准。如果标准是历史经验，那么所有创新在发生之前都不切实际；如果标准是可验证的小规模结果，那我们完全可以设计一个实验来检验它。",
+			ConcernValue:       "{关切}不理想有两种可能：一是方向错了，二是还在投入期。区分这两者，要看{主题}的关键指标是否在改善。如果边际成本在下降、留存在上升，那么暂时的低回报只是时间问题。",
+			ConcernDelay:       "{主题}的延期需要区分原因：是范围变了、估算错了，还是执行出了问题。原因不同，对策完全不同，不能一概而论。",
+			ConcernConflict:    "{主题}的本质往往不是立场之争，而是目标或信息不对称。先对齐目标，再交换信息，大部分分歧会自然消解。",
+			ConcernRisk:        "评估{主题}的{关切}，要同时看发生概率和影响程度。高概率低影响的接受它，低概率高影响的准备预案，两者都高的才需要重新决策。",
+			ConcernFeasibility: "判断{主题}是否可行，可以从三个维度检验：技术上能不能做，资源上够不够做，收益上值不值得做。三个条件缺一不可。",
+			ConcernOpinion:     "看待{主题}，现象层面是变化本身，原因层面是背后的激励结构，而结论取决于我们用什么标准去评价它。",
+			ConcernGeneral:     "{主题}可以从三个层次分析：现象是什么，原因是什么，以及我们能改变的是什么。",
+		},
+		analogy: "用一个类比来说明：%s，%s。",
+		closings: []string{
+			"所以，问题的关键不是要不要做，而是在什么条件下做。",
+			"结论很清楚：先验证前提，再讨论结论。",
+			"按照这个逻辑框架，答案其实已经很明确了。",
+		},
+	},
+}
+
+// composeOfflineResponse 按风格的骨架组装回答：开场、主体、相关的类比和收尾
+// 未知风格使用韩寒风格，与大模型不可用时的默认行为一致
+func (ai *HanStyleAI) composeOfflineResponse(style string, analysis QuestionAnalysis) string {
+	skeleton, ok := offlineSkeletons[style]
+	if !ok {
+		skeleton = offlineSkeletons["hanhan"]
+	}
+
+	parts := []string{
+		skeleton.openings[ai.intn(len(skeleton.openings))],
+		skeleton.bodies[analysis.Concern],
+	}
+	// 类比库中有与问题概念相关的类比时，按风格的口吻补充一句
+	if relevant := ai.AnalogyBase().Relevant("", analysis.Question); len(relevant) > 0 {
+		best := 1
+		for best < len(relevant) && relevant[best].Score == relevant[0].Score {
+			best++
+		}
+		analogy := relevant[ai.intn(best)]
+		parts = append(parts, fmt.Sprintf(skeleton.analogy, analogy.Image, analogy.Lesson))
+	}
+	parts = append(parts, skeleton.closings[ai.intn(len(skeleton.closings))])
+
+	replacer := strings.NewReplacer(
+		"{主题}", analysis.Subject,
+		"{对象}", analysis.Stakeholders[0],
+		"{关切}", analysis.ConcernLabel,
+	)
+	return replacer.Replace(strings.Join(parts, ""))
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAnalyzeQuestion 测试从问题中提取主题、相关方和关切
+func TestAnalyzeQuestion(t *testing.T) {
+	ai := NewHanStyleAI()
+	cases := []struct {
+		question    string
+		subject     string
+		stakeholder string
+		concern     Concern
+	}{
+		{"领导问我这个项目的ROI为什么这么低？", "这个项目的ROI", "领导", ConcernValue},
+		{"分享会上有人质疑我的技术方案不可行", "技术方案", "大家", ConcernDoubt},
+		{"同事说我这个想法太不切实际了", "这个想法", "同事", ConcernDoubt},
+		{"项目延期了怎么汇报？", "项目延期", "大家", ConcernDelay},
+		{"开会时，客户问新版本什么时候上线", "新版本", "客户", ConcernGeneral},
+		{"我们要不要转型做直播带货", "转型做直播带货", "大家", ConcernGeneral},
+		{"你怎么看网红书店？", "网红书店遍地开花", "大家", ConcernOpinion},
+	}
+	for _, c := range cases {
+		analysis := ai.AnalyzeQuestion(c.question)
+		if analysis.Subject != c.subject || analysis.Stakeholders[0] != c.stakeholder || analysis.Concern != c.concern {
+			t.Errorf("%s: %+v", c.question, analysis)
+		}
+	}
+}
+
+// TestOfflineResponse 测试各风格的离线回答紧扣问题并且有变化
+func TestOfflineResponse(t *testing.T) {
+	ai := NewHanStyleAI()
+	question := "同事说我这个想法太不切实际了"
+
+	for style := range offlineSkeletons {
+		for _, skeleton := range [][]string{offlineSkeletons[style].openings, offlineSkeletons[style].closings} {
+			if len(skeleton) < 2 {
+				t.Fatalf("%s的开场和收尾应有多种选择", style)
+			}
+		}
+		for _, concern := range []Concern{ConcernDoubt, ConcernValue, ConcernDelay, ConcernConflict, ConcernRisk, ConcernFeasibility, ConcernOpinion, ConcernGeneral} {
+			if offlineSkeletons[style].bodies[concern] == "" {
+				t.Fatalf("%s缺少%s的回答主体", style, concern)
+			}
+		}
+
+		seen := make(map[string]bool)
+		for i := 0; i < 30; i++ {
+			response := ai.GenerateStyleResponse(style, question, "")
+			if !strings.Contains(response, "这个想法") || strings.Contains(response, "{") {
+				t.Fatalf("%s的回答应围绕问题的主题: %s", style, response)
+			}
+			seen[response] = true
+		}
+		if len(seen) < 2 {
+			t.Fatalf("%s的回答没有变化", style)
+		}
+	}
+
+	// 不同的关切使用不同的主体
+	if a, b := ai.GenerateStyleResponse("chengming", "项目延期了怎么汇报？", ""), ai.GenerateStyleResponse("chengming", question, ""); !strings.Contains(a, "延期需要区分原因") || strings.Contains(b, "延期需要区分原因") {
+		t.Fatalf("回答主体应随关切变化: %s / %s", a, b)
+	}
+	// 未知风格使用韩寒风格
+	if response := ai.GenerateStyleResponse("unknown", question, ""); !strings.Contains(response, "没见过它成功的样子") {
+		t.Fatalf("未知风格应使用韩寒风格: %s", response)
+	}
+}
@@ -0,0 +1,410 @@
+package ai
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed lexicons/profile.yaml
+var defaultProfileLexiconYAML []byte
+
+// 画像检测参数
+const (
+	maxKeywordHits = 3   // 同一个关键词在一次回答中最多计入的次数
+	evidencePrior  = 3.0 // 置信度的先验分数，证据越少置信度越低
+	aiEvidence     = 3.0 // 大模型判断的兴趣按置信度乘以这个分数计入
+	maxStrengths   = 3   // 画像中最多列出的优势
+	maxKeywords    = 3   // 每个兴趣最多列出的命中关键词
+)
+
+// 默认的思维方式和类比风格，与detectThinkingPattern、detectMetaphorStyle一致
+const (
+	defaultThinkingStyle = "现象描述"
+	defaultMetaphorStyle = "生活类比"
+)
+
+// thinkingOrder 思维方式次数相同时的优先顺序
+var thinkingOrder = []string{"类比思维", "逻辑推理", "主观判断", "现象描述"}
+
+// ProfileLexicon 用户画像词表
+type ProfileLexicon struct {
+	Interests map[string]map[string]float64 `yaml:"interests" json:"interests"` // 兴趣 -> 关键词 -> 权重
+	Thinking  map[string][]string           `yaml:"thinking" json:"thinking"`   // 思维方式 -> 线索词
+	Strengths map[string][]string           `yaml:"strengths" json:"strengths"` // 表达优势 -> 线索词
+}
+
+// DefaultProfileLexicon 返回内置画像词表
+func DefaultProfileLexicon() *ProfileLexicon {
+	lexicon := &ProfileLexicon{}
+	if err := yaml.Unmarshal(defaultProfileLexiconYAML, lexicon); err != nil {
+		// 内置词表有测试保证，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析内置画像词表失败: %v", err))
+	}
+	lexicon.normalize()
+	return lexicon
+}
+
+// LoadProfileLexicon 从YAML文件加载画像词表并合并到内置词表：
+// 关键词加入或覆盖内置权重，权重为0的关键词被删除，线索词追加到内置线索词之后
+func LoadProfileLexicon(path string) (*ProfileLexicon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取画像词表失败: %w", err)
+	}
+	custom := &ProfileLexicon{}
+	if err := yaml.Unmarshal(data, custom); err != nil {
+		return nil, fmt.Errorf("解析画像词表失败: %w", err)
+	}
+	custom.normalize()
+
+	lexicon := DefaultProfileLexicon()
+	for interest, keywords := range custom.Interests {
+		if lexicon.Interests[interest] == nil {
+			lexicon.Interests[interest] = make(map[string]float64)
+		}
+		for keyword, weight := range keywords {
+			if weight <= 0 {
+				delete(lexicon.Interests[interest], keyword)
+			} else {
+				lexicon.Interests[interest][keyword] = weight
+			}
+		}
+		if len(lexicon.Interests[interest]) == 0 {
+			delete(lexicon.Interests, interest)
+		}
+	}
+	for style, cues := range custom.Thinking {
+		lexicon.Thinking[style] = appendUnique(lexicon.Thinking[style], cues...)
+	}
+	for strength, cues := range custom.Strengths {
+		lexicon.Strengths[strength] = appendUnique(lexicon.Strengths[strength], cues...)
+	}
+	return lexicon, nil
+}
+
+// normalize 关键词统一转成小写，并保证各个表不为nil
+func (l *ProfileLexicon) normalize() {
+	interests := make(map[string]map[string]float64, len(l.Interests))
+	for interest, keywords := range l.Interests {
+		interests[interest] = make(map[string]float64, len(keywords))
+		for keyword, weight := range keywords {
+			interests[interest][strings.ToLower(keyword)] = weight
+		}
+	}
+	l.Interests = interests
+	if l.Thinking == nil {
+		l.Thinking = make(map[string][]string)
+	}
+	if l.Strengths == nil {
+		l.Strengths = make(map[string][]string)
+	}
+}
+
+// InterestNames 词表中的全部兴趣，按名称排列
+func (l *ProfileLexicon) InterestNames() []string {
+	return sortedKeys(l.Interests)
+}
+
+// ThinkingStyles 词表中的全部思维方式
+func (l *ProfileLexicon) ThinkingStyles() []string {
+	return sortedKeys(l.Thinking)
+}
+
+// StrengthNames 词表中的全部表达优势
+func (l *ProfileLexicon) StrengthNames() []string {
+	return sortedKeys(l.Strengths)
+}
+
+// InterestScore 用户在某个兴趣上的证据
+type InterestScore struct {
+	Interest string   `json:"interest"`
+	Score    float64  `json:"score"`              // 累计的关键词权重
+	Weight   float64  `json:"weight"`             // 在全部兴趣中的占比，0-1
+	Keywords []string `json:"keywords,omitempty"` // 命中最多的关键词
+}
+
+// ProfileEvidence 从回答中积累的画像证据，多次提交的证据可以合并
+type ProfileEvidence struct {
+	Interests   map[string]float64        // 兴趣 -> 累计分数
+	Keywords    map[string]map[string]int // 兴趣 -> 命中的关键词 -> 次数
+	Thinking    map[string]float64        // 思维方式 -> 线索次数
+	Metaphors   map[string]int            // 类比风格 -> 出现的回答数
+	Strengths   map[string]int            // 表达优势 -> 出现的回答数
+	Submissions int                       // 合并的回答数
+	AIAssisted  int                       // 大模型参与判断的次数
+}
+
+// NewProfileEvidence 创建空的画像证据
+func NewProfileEvidence() *ProfileEvidence {
+	return &ProfileEvidence{
+		Interests: make(map[string]float64),
+		Keywords:  make(map[string]map[string]int),
+		Thinking:  make(map[string]float64),
+		Metaphors: make(map[string]int),
+		Strengths: make(map[string]int),
+	}
+}
+
+// Merge 合并另一份证据
+func (e *ProfileEvidence) Merge(other *ProfileEvidence) {
+	if other == nil {
+		return
+	}
+	for interest, score := range other.Interests {
+		e.Interests[interest] += score
+	}
+	for interest, keywords := range other.Keywords {
+		if e.Keywords[interest] == nil {
+			e.Keywords[interest] = make(map[string]int)
+		}
+		for keyword, count := range keywords {
+			e.Keywords[interest][keyword] += count
+		}
+	}
+	for style, count := range other.Thinking {
+		e.Thinking[style] += count
+	}
+	for style, count := range other.Metaphors {
+		e.Metaphors[style] += count
+	}
+	for strength, count := range other.Strengths {
+		e.Strengths[strength] += count
+	}
+	e.Submissions += other.Submissions
+	e.AIAssisted += other.AIAssisted
+}
+
+// Profile 根据积累的证据生成用户画像，没有兴趣证据时主要兴趣为空
+func (e *ProfileEvidence) Profile() UserProfile {
+	profile := UserProfile{
+		ThinkingStyle: pickMax(e.Thinking, thinkingOrder, defaultThinkingStyle),
+		Strengths:     []string{},
+		Samples:       e.Submissions,
+		AIAssisted:    e.AIAssisted > 0,
+	}
+
+	metaphors := make(map[string]float64, len(e.Metaphors))
+	for style, count := range e.Metaphors {
+		metaphors[style] = float64(count)
+	}
+	profile.MetaphorStyle = pickMax(metaphors, nil, defaultMetaphorStyle)
+
+	total := 0.0
+	for interest, score := range e.Interests {
+		if score > 0 {
+			total += score
+			profile.Interests = append(profile.Interests, InterestScore{
+				Interest: interest,
+				Score:    round2(score),
+				Keywords: topCounts(e.Keywords[interest], maxKeywords),
+			})
+		}
+	}
+	sort.Slice(profile.Interests, func(i, j int) bool {
+		if profile.Interests[i].Score != profile.Interests[j].Score {
+			return profile.Interests[i].Score > profile.Interests[j].Score
+		}
+		return profile.Interests[i].Interest < profile.Interests[j].Interest
+	})
+	for i := range profile.Interests {
+		profile.Interests[i].Weight = round2(profile.Interests[i].Score / total)
+	}
+	if len(profile.Interests) > 0 {
+		top := profile.Interests[0]
+		profile.PrimaryInterest = top.Interest
+		// 主要兴趣的占比，证据少时向0收缩
+		profile.Confidence = round2(top.Score / (total + evidencePrior))
+	}
+
+	profile.Strengths = append(profile.Strengths, topCounts(e.Strengths, maxStrengths)...)
+	return profile
+}
+
+// ProfileDetector 按词表从回答中提取画像证据
+type ProfileDetector struct {
+	lexicon *ProfileLexicon
+}
+
+// NewProfileDetector 创建画像检测器
+func NewProfileDetector(lexicon *ProfileLexicon) *ProfileDetector {
+	return &ProfileDetector{lexicon: lexicon}
+}
+
+// Lexicon 返回检测器使用的词表
+func (d *ProfileDetector) Lexicon() *ProfileLexicon {
+	return d.lexicon
+}
+
+// Detect 统计一次回答中各兴趣关键词、思维方式和表达优势的线索
+func (d *ProfileDetector) Detect(speech string) *ProfileEvidence {
+	evidence := NewProfileEvidence()
+	text := strings.ToLower(strings.TrimSpace(speech))
+	if text == "" {
+		return evidence
+	}
+	evidence.Submissions = 1
+
+	for interest, keywords := range d.lexicon.Interests {
+		for keyword, weight := range keywords {
+			hits := countHits(text, keyword)
+			if hits == 0 {
+				continue
+			}
+			evidence.Interests[interest] += weight * float64(hits)
+			if evidence.Keywords[interest] == nil {
+				evidence.Keywords[interest] = make(map[string]int)
+			}
+			evidence.Keywords[interest][keyword] += hits
+		}
+	}
+	for style, cues := range d.lexicon.Thinking {
+		hits := 0
+		for _, cue := range cues {
+			hits += countHits(text, strings.ToLower(cue))
+		}
+		if hits > 0 {
+			evidence.Thinking[style] += float64(hits)
+		}
+	}
+	for strength, cues := range d.lexicon.Strengths {
+		for _, cue := range cues {
+			if strings.Contains(text, strings.ToLower(cue)) {
+				evidence.Strengths[strength]++
+				break
+			}
+		}
+	}
+	return evidence
+}
+
+// AIEvidence 把大模型的判断转换成画像证据，只保留词表中已有的兴趣、思维方式和优势
+// interests为兴趣到置信度（0-1）的映射
+func (d *ProfileDetector) AIEvidence(interests map[string]float64, thinkingStyle string, strengths []string) *ProfileEvidence {
+	evidence := NewProfileEvidence()
+	for interest, confidence := range interests {
+		if _, ok := d.lexicon.Interests[interest]; ok && confidence > 0 {
+			evidence.Interests[interest] += aiEvidence * math.Min(confidence, 1)
+		}
+	}
+	if _, ok := d.lexicon.Thinking[thinkingStyle]; ok {
+		evidence.Thinking[thinkingStyle]++
+	}
+	for _, strength := range strengths {
+		if _, ok := d.lexicon.Strengths[strength]; ok {
+			evidence.Strengths[strength]++
+		}
+	}
+	evidence.AIAssisted = 1
+	return evidence
+}
+
+var (
+	defaultProfileDetector *ProfileDetector
+	profileDetectorMutex   sync.RWMutex
+)
+
+// DefaultProfileDetector 返回全局画像检测器，首次使用时加载内置词表
+func DefaultProfileDetector() *ProfileDetector {
+	profileDetectorMutex.RLock()
+	detector := defaultProfileDetector
+	profileDetectorMutex.RUnlock()
+	if detector != nil {
+		return detector
+	}
+
+	profileDetectorMutex.Lock()
+	defer profileDetectorMutex.Unlock()
+	if defaultProfileDetector == nil {
+		defaultProfileDetector = NewProfileDetector(DefaultProfileLexicon())
+	}
+	return defaultProfileDetector
+}
+
+// SetDefaultProfileLexicon 替换全局画像检测器的词表
+func SetDefaultProfileLexicon(lexicon *ProfileLexicon) {
+	detector := NewProfileDetector(lexicon)
+	profileDetectorMutex.Lock()
+	defaultProfileDetector = detector
+	profileDetectorMutex.Unlock()
+}
+
+// countHits 统计关键词出现的次数，超过上限时按上限计
+func countHits(text, keyword string) int {
+	if keyword == "" {
+		return 0
+	}
+	hits := strings.Count(text, keyword)
+	if hits > maxKeywordHits {
+		hits = maxKeywordHits
+	}
+	return hits
+}
+
+// pickMax 返回分数最高的取值，分数相同时按order的顺序，其余按名称；都为0时返回fallback
+func pickMax(scores map[string]float64, order []string, fallback string) string {
+	best, bestScore := fallback, 0.0
+	candidates := append([]string(nil), order...)
+	for _, key := range sortedKeys(scores) {
+		if !contains(order, key) {
+			candidates = append(candidates, key)
+		}
+	}
+	for _, key := range candidates {
+		if scores[key] > bestScore {
+			best, bestScore = key, scores[key]
+		}
+	}
+	return best
+}
+
+// topCounts 按次数从高到低返回前n个取值
+func topCounts(counts map[string]int, n int) []string {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// sortedKeys 按名称排列的键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendUnique 追加list中没有的取值
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if !contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// contains 列表中是否有该取值
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
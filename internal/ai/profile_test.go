@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProfileDetection 测试多兴趣打分、置信度和跨回答合并
+func TestProfileDetection(t *testing.T) {
+	detector := NewProfileDetector(DefaultProfileLexicon())
+
+	first := detector.Detect("这就像塞尔达游戏里的神庙，数量多了质量却稀释了。就像篮球比赛只刷数据？")
+	profile := first.Profile()
+	if profile.PrimaryInterest != "游戏" || len(profile.Interests) != 2 || profile.Interests[1].Interest != "体育" {
+		t.Fatalf("应同时识别游戏和体育: %+v", profile.Interests)
+	}
+	if profile.ThinkingStyle != "类比思维" || profile.Samples != 1 {
+		t.Fatalf("思维方式或回答数不正确: %+v", profile)
+	}
+	if !contains(profile.Strengths, "善于类比") || !contains(profile.Strengths, "敢于发问") {
+		t.Fatalf("优势应来自回答内容: %v", profile.Strengths)
+	}
+
+	// 第二次回答继续提到体育，证据合并后主要兴趣改变且置信度上升
+	merged := NewProfileEvidence()
+	merged.Merge(first)
+	merged.Merge(detector.Detect("因为足球是团队运动，所以梅西也需要队友。马拉松也是一样。"))
+	merged.Merge(nil)
+	combined := merged.Profile()
+	if combined.PrimaryInterest != "体育" || combined.Samples != 2 || combined.Confidence <= profile.Confidence {
+		t.Fatalf("合并后应以体育为主且更有把握: %+v", combined)
+	}
+
+	// 没有任何兴趣线索时主要兴趣为空，不再默认成文艺
+	empty := detector.Detect("我发现大家都越来越忙了").Profile()
+	if empty.PrimaryInterest != "" || empty.Confidence != 0 || empty.ThinkingStyle != "现象描述" {
+		t.Fatalf("没有线索时不应猜测兴趣: %+v", empty)
+	}
+
+	// 大模型的判断只保留词表中的取值
+	ai := detector.AIEvidence(map[string]float64{"科技": 0.9, "烹饪": 1}, "逻辑推理", []string{"追问本质", "幽默"})
+	aiProfile := ai.Profile()
+	if aiProfile.PrimaryInterest != "科技" || len(aiProfile.Interests) != 1 || !aiProfile.AIAssisted || aiProfile.Samples != 0 {
+		t.Fatalf("大模型证据不正确: %+v", aiProfile)
+	}
+	if len(aiProfile.Strengths) != 1 || aiProfile.ThinkingStyle != "逻辑推理" {
+		t.Fatalf("应过滤词表外的优势: %+v", aiProfile)
+	}
+}
+
+// TestLoadProfileLexicon 测试自定义词表与内置词表合并
+func TestLoadProfileLexicon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	data := "interests:\n  游戏:\n    升级: 0\n    Switch: 2\n  美食:\n    火锅: 2\nstrengths:\n  善于举例: [打个比方]\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lexicon, err := LoadProfileLexicon(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lexicon.Interests["游戏"]["升级"]; ok {
+		t.Fatal("权重为0的关键词应被删除")
+	}
+	if lexicon.Interests["游戏"]["switch"] != 2 || lexicon.Interests["游戏"]["塞尔达"] != 3 || lexicon.Interests["美食"]["火锅"] != 2 {
+		t.Fatalf("关键词应合并到内置词表: %v", lexicon.Interests["游戏"])
+	}
+	if cues := lexicon.Strengths["善于举例"]; cues[len(cues)-1] != "打个比方" || cues[0] != "比如" {
+		t.Fatalf("线索词应追加: %v", cues)
+	}
+
+	profile := NewProfileDetector(lexicon).Detect("周末玩SWITCH，然后去吃火锅，再吃一次火锅").Profile()
+	if profile.PrimaryInterest != "美食" || profile.Interests[1].Keywords[0] != "switch" {
+		t.Fatalf("新兴趣和英文关键词应生效: %+v", profile.Interests)
+	}
+
+	if _, err := LoadProfileLexicon(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("文件不存在时应返回错误")
+	}
+}
@@ -0,0 +1,251 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 应答框架的四个步骤
+const (
+	StepHook     = "hook"     // 类比切入
+	StepContrast = "contrast" // 对比转折
+	StepEssence  = "essence"  // 现象本质
+	StepQuestion = "question" // 犀利反问
+)
+
+// 提供给大模型参考的类比数
+const maxRelatedAnalogies = 3
+
+// 模板来源
+const (
+	TemplateOffline = "offline" // 本地按话题组装
+	TemplateLLM     = "llm"     // 大模型生成
+)
+
+// TemplateStep 应答框架的一步
+type TemplateStep struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// PersonalizedTemplate 个性化应答模板
+type PersonalizedTemplate struct {
+	Topic         string         `json:"topic"`
+	Subject       string         `json:"subject"` // 从话题中提取的讨论对象
+	Interest      string         `json:"interest"`
+	ThinkingStyle string         `json:"thinking_style,omitempty"`
+	Steps         []TemplateStep `json:"steps"`
+	Source        string         `json:"source"`
+}
+
+// Text 把四步拼成可以直接照着说的模板
+func (t *PersonalizedTemplate) Text() string {
+	lines := make([]string, len(t.Steps))
+	for i, step := range t.Steps {
+		lines[i] = step.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stepLabels 各步骤的名称
+var stepLabels = map[string]string{
+	StepHook:     "类比切入",
+	StepContrast: "对比转折",
+	StepEssence:  "现象本质",
+	StepQuestion: "犀利反问",
+}
+
+// NewPersonalizedTemplate 用四步内容创建模板，例如大模型生成的结果
+func NewPersonalizedTemplate(topic, interest, thinkingStyle, source string, hook, contrast, essence, question string) *PersonalizedTemplate {
+	template := &PersonalizedTemplate{
+		Topic:         topic,
+		Subject:       extractSubject(topic),
+		Interest:      interest,
+		ThinkingStyle: thinkingStyle,
+		Source:        source,
+	}
+	for _, step := range []struct{ name, text string }{
+		{StepHook, hook}, {StepContrast, contrast}, {StepEssence, essence}, {StepQuestion, question},
+	} {
+		template.Steps = append(template.Steps, TemplateStep{Name: step.name, Label: stepLabels[step.name], Text: strings.TrimSpace(step.text)})
+	}
+	return template
+}
+
+// topicFrame 一类话题的分析框架
+type topicFrame struct {
+	Keywords []string
+	Concepts []string // 话题隐含的概念，用于匹配类比的目标概念
+	Subject  string
+	Theme    string
+	Surface  string // 表面现象
+	Reality  string // 实际暴露的问题
+	Essence  string
+	Question string
+}
+
+// topicFrames 常见话题的分析框架，按关键词匹配
+var topicFrames = []topicFrame{
+	{
+		Keywords: []string{"书店", "阅读", "读书"},
+		Concepts: []string{"网红", "打卡", "数量", "质量", "形式", "文化"},
+		Subject:  "网红书店遍地开花",
+		Theme:    "quantity",
+		Surface:  "书店繁荣、文化升温",
+		Reality:  "我们在用「打卡」代替「阅读」",
+		Essence:  "书店卖的不再是书，而是拍照的背景",
+		Question: "我们是在热爱阅读，还是在热爱被看见？",
+	},
+	{
+		Keywords: []string{"短视频", "注意力", "抖音", "刷视频"},
+		Concepts: []string{"捷径", "效率", "算法", "手机"},
+		Subject:  "短视频改变注意力",
+		Theme:    "shortcut",
+		Surface:  "获取信息越来越高效",
+		Reality:  "我们越来越难读完一篇长文章、看完一部电影",
+		Essence:  "算法喂给我们的是刺激，不是知识",
+		Question: "到底是我们在刷视频，还是视频在刷我们？",
+	},
+	{
+		Keywords: []string{"内卷", "竞争", "躺平"},
+		Concepts: []string{"努力", "加班", "攀比", "焦虑"},
+		Subject:  "内卷",
+		Theme:    "competition",
+		Surface:  "大家都比以前更努力了",
+		Reality:  "努力变成了互相消耗，总的收获并没有增加",
+		Essence:  "规则没有变，只是每个人付出的代价更高了",
+		Question: "如果所有人都踮起脚，谁又真的看得更远了？",
+	},
+	{
+		Keywords: []string{"人工智能", "AI", "机器人", "替代"},
+		Concepts: []string{"自动化", "工作", "技术", "判断", "创造"},
+		Subject:  "人工智能替代工作",
+		Theme:    "replacement",
+		Surface:  "AI正在抢走很多人的饭碗",
+		Reality:  "被替代的往往是重复的任务，而不是整个人",
+		Essence:  "真正危险的不是机器会思考，而是人停止了思考",
+		Question: "如果一份工作能被AI完全替代，它当初真的需要一个人吗？",
+	},
+	{
+		Keywords: []string{"校园", "学校"},
+		Concepts: []string{"变化", "成长", "学习", "教育", "回忆"},
+		Subject:  "校园生活的变化",
+		Theme:    "change",
+		Surface:  "条件越来越好、选择越来越多",
+		Reality:  "课余时间被补习班和屏幕填满了",
+		Essence:  "变的是工具，不变的是我们怎样安排自己的时间",
+		Question: "我们拥有的更多了，可留下的回忆真的更多了吗？",
+	},
+}
+
+// matchFrame 按关键词匹配话题框架，匹配不到时根据话题生成通用框架
+func matchFrame(topic string) topicFrame {
+	for _, frame := range topicFrames {
+		for _, keyword := range frame.Keywords {
+			if strings.Contains(topic, keyword) {
+				return frame
+			}
+		}
+	}
+
+	subject := extractSubject(topic)
+	return topicFrame{
+		Subject:  subject,
+		Surface:  fmt.Sprintf("大家都在谈论%s", subject),
+		Reality:  fmt.Sprintf("很少有人认真想过%s到底改变了什么", subject),
+		Essence:  fmt.Sprintf("看待%s，关键不在现象本身，而在它背后每个人的选择", subject),
+		Question: fmt.Sprintf("我们讨论的到底是%s，还是我们自己？", subject),
+	}
+}
+
+// 话题中常见的提问套话
+var (
+	subjectPrefixes = []string{"谈谈你对", "你如何看待", "如何看待", "你对", "你觉得", "你认为", "说说"}
+	subjectSuffixes = []string{"这种现象，怎么看", "这种现象怎么看", "，怎么评价", "怎么评价", "，怎么看", "怎么看", "这个词的理解", "的理解", "有什么看法"}
+)
+
+// extractSubject 去掉引号和提问套话，提取话题讨论的对象
+func extractSubject(topic string) string {
+	subject := strings.Trim(strings.TrimSpace(topic), "\"“”「」'‘’")
+	subject = strings.TrimRight(subject, "？?。！!")
+	for _, prefix := range subjectPrefixes {
+		subject = strings.TrimPrefix(subject, prefix)
+	}
+	for _, suffix := range subjectSuffixes {
+		subject = strings.TrimSuffix(subject, suffix)
+	}
+	subject = strings.Trim(subject, "\"“”「」'‘’，, ")
+	if subject == "" {
+		return "这个现象"
+	}
+	return subject
+}
+
+// pickAnalogy 从兴趣领域中选择与话题最相关的类比，相关度相同时随机选一个，都不相关时在整个领域中随机选
+func (ai *HanStyleAI) pickAnalogy(interest, topic string) Analogy {
+	base := ai.AnalogyBase()
+	relevant := base.Relevant(interest, topic)
+	if len(relevant) > 0 {
+		best := 1
+		for best < len(relevant) && relevant[best].Score == relevant[0].Score {
+			best++
+		}
+		return relevant[ai.intn(best)].Analogy
+	}
+	analogies := base.Analogies(interest)
+	return analogies[ai.intn(len(analogies))]
+}
+
+// RelatedAnalogies 返回兴趣领域中与话题最相关的几个类比，供大模型生成模板时参考；都不相关时返回整个领域
+func (ai *HanStyleAI) RelatedAnalogies(interest, topic string) []string {
+	base := ai.AnalogyBase()
+	var result []string
+	for _, analogy := range base.Relevant(interest, topic) {
+		if len(result) == maxRelatedAnalogies {
+			break
+		}
+		result = append(result, analogy.Text())
+	}
+	if len(result) == 0 {
+		for _, analogy := range base.Analogies(interest) {
+			result = append(result, analogy.Text())
+		}
+	}
+	return result
+}
+
+// ComposeTemplate 按话题、兴趣和思维方式在本地组装四步应答模板
+func (ai *HanStyleAI) ComposeTemplate(profile UserProfile, topic string) *PersonalizedTemplate {
+	frame := matchFrame(topic)
+	interest := profile.PrimaryInterest
+	if len(ai.AnalogyBase().Analogies(interest)) == 0 {
+		interest = DefaultInterest
+	}
+	analogy := ai.pickAnalogy(interest, topic)
+
+	// 不同的思维方式用不同的方式组织同样的四步
+	var hook, contrast, essence string
+	switch profile.ThinkingStyle {
+	case "逻辑推理":
+		hook = fmt.Sprintf("老师，先打个比方：%s，就像%s，%s。", frame.Subject, analogy.Image, analogy.Lesson)
+		contrast = fmt.Sprintf("表面上是%s，但顺着推下去会发现，%s。", frame.Surface, frame.Reality)
+		essence = fmt.Sprintf("所以本质上，%s。", frame.Essence)
+	case "主观判断":
+		hook = fmt.Sprintf("老师，我的看法很直接：%s就像%s——%s。", frame.Subject, analogy.Image, analogy.Lesson)
+		contrast = fmt.Sprintf("很多人觉得是%s，可我觉得%s。", frame.Surface, frame.Reality)
+		essence = fmt.Sprintf("在我看来，%s。", frame.Essence)
+	case "现象描述":
+		hook = fmt.Sprintf("老师，我注意到一个现象：%s，像极了%s——%s。", frame.Subject, analogy.Image, analogy.Lesson)
+		contrast = fmt.Sprintf("表面上是%s，实际上%s。", frame.Surface, frame.Reality)
+		essence = fmt.Sprintf("说到底，%s。", frame.Essence)
+	default:
+		hook = fmt.Sprintf("老师，我觉得%s就像%s——%s。", frame.Subject, analogy.Image, analogy.Lesson)
+		contrast = fmt.Sprintf("表面上是%s，实际上%s。", frame.Surface, frame.Reality)
+		essence = fmt.Sprintf("说到底，%s。", frame.Essence)
+	}
+
+	template := NewPersonalizedTemplate(topic, interest, profile.ThinkingStyle, TemplateOffline, hook, contrast, essence, frame.Question)
+	template.Subject = frame.Subject
+	return template
+}
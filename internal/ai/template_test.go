@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestComposeTemplate 测试按话题、兴趣和思维方式组装模板
+func TestComposeTemplate(t *testing.T) {
+	ai := NewHanStyleAI()
+
+	template := ai.ComposeTemplate(UserProfile{PrimaryInterest: "体育", ThinkingStyle: "逻辑推理"}, "谈谈你对内卷的理解")
+	if template.Subject != "内卷" || template.Interest != "体育" || template.Source != TemplateOffline {
+		t.Fatalf("模板基本信息不正确: %+v", template)
+	}
+	if len(template.Steps) != 4 || template.Steps[0].Name != StepHook || template.Steps[3].Name != StepQuestion {
+		t.Fatalf("应有四个步骤: %+v", template.Steps)
+	}
+	if !strings.Contains(template.Steps[0].Text, "马拉松") || !strings.Contains(template.Steps[1].Text, "顺着推") {
+		t.Fatalf("应选用竞争主题的体育类比并按逻辑推理组织: %s", template.Text())
+	}
+
+	// 同样的话题，不同的思维方式措辞不同
+	subjective := ai.ComposeTemplate(UserProfile{PrimaryInterest: "体育", ThinkingStyle: "主观判断"}, "谈谈你对内卷的理解")
+	if subjective.Steps[1].Text == template.Steps[1].Text || subjective.Steps[3].Text != template.Steps[3].Text {
+		t.Fatalf("思维方式应只改变措辞: %s / %s", subjective.Steps[1].Text, template.Steps[1].Text)
+	}
+
+	// 未知兴趣使用游戏类比，未知话题用话题本身组装
+	generic := ai.ComposeTemplate(UserProfile{PrimaryInterest: "烹饪"}, "你如何看待“city walk”？")
+	if generic.Interest != "游戏" || generic.Subject != "city walk" {
+		t.Fatalf("通用模板不正确: %+v", generic)
+	}
+	for _, step := range generic.Steps {
+		if step.Text == "" {
+			t.Fatalf("步骤不能为空: %+v", generic.Steps)
+		}
+	}
+	if !strings.Contains(generic.Steps[3].Text, "city walk") {
+		t.Fatalf("反问应围绕话题: %s", generic.Steps[3].Text)
+	}
+}
+
+// TestRelatedAnalogies 测试按话题主题筛选类比
+func TestRelatedAnalogies(t *testing.T) {
+	ai := NewHanStyleAI()
+	if got := ai.RelatedAnalogies("科技", "人工智能会替代老师吗"); len(got) != 1 || !strings.Contains(got[0], "AI写代码") {
+		t.Fatalf("应只返回替代主题的类比: %v", got)
+	}
+	if got := ai.RelatedAnalogies("科技", "city walk"); len(got) != 5 {
+		t.Fatalf("没有主题时应返回全部类比: %v", got)
+	}
+}
@@ -0,0 +1,440 @@
+package analysis
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed lexicons/disfluency.yaml
+var defaultLexiconYAML []byte
+
+// Category 赘词类别
+type Category string
+
+const (
+	CategoryFiller      Category = "filler"      // 口头禅、填充词，如"嗯""那个"
+	CategoryHedge       Category = "hedge"       // 犹豫、模糊的表达，如"可能""我觉得"
+	CategoryRepetition  Category = "repetition"  // 重复，如"我我我""这个这个"
+	CategoryIntensifier Category = "intensifier" // 空洞的程度副词，如"很""非常"
+)
+
+// Categories 所有赘词类别
+func Categories() []Category {
+	return []Category{CategoryFiller, CategoryHedge, CategoryRepetition, CategoryIntensifier}
+}
+
+// WordList 一个类别的词表
+type WordList struct {
+	Words      []string `yaml:"words" json:"words"`           // 出现即计入
+	Standalone []string `yaml:"standalone" json:"standalone"` // 仅在独立使用时计入，如"那个，"而不是"那个人"
+	Exclude    []string `yaml:"exclude" json:"exclude"`       // 含有这些词时整体跳过，如"不可能"
+}
+
+// RepetitionRule 重复检测规则
+type RepetitionRule struct {
+	MaxPhrase int `yaml:"max_phrase" json:"max_phrase"` // 最长检测几个字（英文按词）的重复片段
+	MinSingle int `yaml:"min_single" json:"min_single"` // 单个汉字连续出现几次才算重复
+}
+
+// Lexicon 赘词词表
+type Lexicon struct {
+	Filler      WordList       `yaml:"filler" json:"filler"`
+	Hedge       WordList       `yaml:"hedge" json:"hedge"`
+	Intensifier WordList       `yaml:"intensifier" json:"intensifier"`
+	Repetition  RepetitionRule `yaml:"repetition" json:"repetition"`
+}
+
+// DefaultLexicon 返回内置词表
+func DefaultLexicon() *Lexicon {
+	lexicon := &Lexicon{}
+	if err := yaml.Unmarshal(defaultLexiconYAML, lexicon); err != nil {
+		// 内置词表有测试保证，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析内置赘词词表失败: %v", err))
+	}
+	return lexicon
+}
+
+// LoadLexicon 从YAML文件加载词表，文件中没有出现的类别沿用内置词表
+func LoadLexicon(path string) (*Lexicon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取赘词词表失败: %w", err)
+	}
+	lexicon := DefaultLexicon()
+	if err := yaml.Unmarshal(data, lexicon); err != nil {
+		return nil, fmt.Errorf("解析赘词词表失败: %w", err)
+	}
+	return lexicon, nil
+}
+
+// Occurrence 一处赘词，Start/End按Unicode字符（rune）计算，End不包含
+type Occurrence struct {
+	Category Category `json:"category"`
+	Text     string   `json:"text"`
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+}
+
+// Span 原文的一个片段，按顺序拼接即为原文；Category非空的片段需要高亮
+type Span struct {
+	Text     string   `json:"text"`
+	Category Category `json:"category,omitempty"`
+}
+
+// Disfluencies 赘词检测结果
+type Disfluencies struct {
+	Occurrences []Occurrence     `json:"occurrences"`
+	Counts      map[Category]int `json:"counts"`
+	Total       int              `json:"total"`
+	Spans       []Span           `json:"spans"`
+}
+
+// Count 返回某个类别的次数
+func (d *Disfluencies) Count(category Category) int {
+	if d == nil {
+		return 0
+	}
+	return d.Counts[category]
+}
+
+// entry 匹配用的词条，category为空表示排除词
+type entry struct {
+	word       []rune
+	category   Category
+	standalone bool
+	latin      bool // 英文词，需要整词匹配
+}
+
+// Detector 赘词检测器
+type Detector struct {
+	entries    []entry // 按长度从长到短排列，保证最长匹配
+	repetition RepetitionRule
+}
+
+// NewDetector 根据词表创建检测器
+func NewDetector(lexicon *Lexicon) *Detector {
+	detector := &Detector{repetition: lexicon.Repetition}
+	if detector.repetition.MaxPhrase <= 0 {
+		detector.repetition.MaxPhrase = 4
+	}
+	if detector.repetition.MinSingle < 2 {
+		detector.repetition.MinSingle = 3
+	}
+
+	add := func(words []string, category Category, standalone bool) {
+		for _, word := range words {
+			runes := []rune(strings.ToLower(strings.TrimSpace(word)))
+			if len(runes) == 0 {
+				continue
+			}
+			detector.entries = append(detector.entries, entry{
+				word:       runes,
+				category:   category,
+				standalone: standalone,
+				latin:      isLatinRune(runes[0]),
+			})
+		}
+	}
+	for _, list := range []struct {
+		words    WordList
+		category Category
+	}{
+		{lexicon.Filler, CategoryFiller},
+		{lexicon.Hedge, CategoryHedge},
+		{lexicon.Intensifier, CategoryIntensifier},
+	} {
+		add(list.words.Exclude, "", false)
+		add(list.words.Words, list.category, false)
+		add(list.words.Standalone, list.category, true)
+	}
+
+	sort.SliceStable(detector.entries, func(i, j int) bool {
+		return len(detector.entries[i].word) > len(detector.entries[j].word)
+	})
+	return detector
+}
+
+var (
+	defaultDetector *Detector
+	detectorMutex   sync.RWMutex
+)
+
+// DefaultDetector 返回全局检测器，未设置词表时使用内置词表
+func DefaultDetector() *Detector {
+	detectorMutex.RLock()
+	detector := defaultDetector
+	detectorMutex.RUnlock()
+	if detector != nil {
+		return detector
+	}
+
+	detectorMutex.Lock()
+	defer detectorMutex.Unlock()
+	if defaultDetector == nil {
+		defaultDetector = NewDetector(DefaultLexicon())
+	}
+	return defaultDetector
+}
+
+// SetDefaultLexicon 替换全局检测器的词表
+func SetDefaultLexicon(lexicon *Lexicon) {
+	detector := NewDetector(lexicon)
+	detectorMutex.Lock()
+	defaultDetector = detector
+	detectorMutex.Unlock()
+}
+
+// DetectDisfluencies 使用全局检测器检测赘词
+func DetectDisfluencies(text string) *Disfluencies {
+	return DefaultDetector().Detect(text)
+}
+
+// Detect 检测文本中的赘词，返回每一处的位置和类别
+// 词表匹配优先；与之重叠的重复片段会被截断到词表匹配之前
+func (d *Detector) Detect(text string) *Disfluencies {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// 极少数字符小写后长度变化，退回按原文匹配
+		lower = runes
+	}
+
+	occurrences := d.matchLexicon(lower)
+	occurrences = append(occurrences, d.clipRepetitions(d.findRepetitions(lower), occurrences)...)
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Start < occurrences[j].Start
+	})
+
+	result := &Disfluencies{
+		Occurrences: make([]Occurrence, 0, len(occurrences)),
+		Counts:      make(map[Category]int),
+	}
+	for _, category := range Categories() {
+		result.Counts[category] = 0
+	}
+	for _, occurrence := range occurrences {
+		occurrence.Text = string(runes[occurrence.Start:occurrence.End])
+		result.Occurrences = append(result.Occurrences, occurrence)
+		result.Counts[occurrence.Category]++
+	}
+	result.Total = len(result.Occurrences)
+	result.Spans = buildSpans(runes, result.Occurrences)
+	return result
+}
+
+// matchLexicon 从左到右做最长匹配
+func (d *Detector) matchLexicon(runes []rune) []Occurrence {
+	var occurrences []Occurrence
+	for i := 0; i < len(runes); {
+		matched := d.longestMatch(runes, i)
+		if matched == nil {
+			i++
+			continue
+		}
+		end := i + len(matched.word)
+		if matched.category != "" {
+			occurrences = append(occurrences, Occurrence{Category: matched.category, Start: i, End: end})
+		}
+		i = end
+	}
+	return occurrences
+}
+
+// longestMatch 返回在位置i匹配的最长词条
+func (d *Detector) longestMatch(runes []rune, i int) *entry {
+	for k := range d.entries {
+		e := &d.entries[k]
+		end := i + len(e.word)
+		if !hasRunePrefix(runes[i:], e.word) {
+			continue
+		}
+		if e.latin && (i > 0 && isLatinRune(runes[i-1]) || end < len(runes) && isLatinRune(runes[end])) {
+			continue
+		}
+		if e.standalone && !d.standsAlone(runes, end, e.category, e.latin) {
+			continue
+		}
+		return e
+	}
+	return nil
+}
+
+// standsAlone 判断词后面是否是标点、空白、结尾或另一个同类词；
+// 英文词只认后面的逗号或另一个同类词，避免把"I like it""it went well."里的词当成口头禅
+func (d *Detector) standsAlone(runes []rune, end int, category Category, latin bool) bool {
+	if latin {
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		if end < len(runes) && (runes[end] == ',' || runes[end] == '，') {
+			return true
+		}
+	} else if end >= len(runes) || !isWordRune(runes[end]) {
+		return true
+	}
+	for _, e := range d.entries {
+		if e.category == category && hasRunePrefix(runes[end:], e.word) {
+			return true
+		}
+	}
+	return false
+}
+
+// token 重复检测的基本单位：一个汉字或一个英文单词
+type token struct {
+	text       string
+	start, end int
+	han        bool
+}
+
+// findRepetitions 检测连续重复的字词，标点会打断重复
+func (d *Detector) findRepetitions(runes []rune) []Occurrence {
+	var occurrences []Occurrence
+	for _, tokens := range tokenize(runes) {
+		for i := 0; i < len(tokens); {
+			repeated := false
+			for n := min(d.repetition.MaxPhrase, len(tokens)-i); n >= 1; n-- {
+				reps := 1
+				for i+(reps+1)*n <= len(tokens) && sameTokens(tokens[i:i+n], tokens[i+reps*n:i+(reps+1)*n]) {
+					reps++
+				}
+				required := 2
+				if n == 1 && tokens[i].han {
+					required = d.repetition.MinSingle
+				}
+				if reps < required {
+					continue
+				}
+				// 第一次出现是正常表达，高亮之后的重复部分
+				occurrences = append(occurrences, Occurrence{
+					Category: CategoryRepetition,
+					Start:    tokens[i+n].start,
+					End:      tokens[i+reps*n-1].end,
+				})
+				i += reps * n
+				repeated = true
+				break
+			}
+			if !repeated {
+				i++
+			}
+		}
+	}
+	return occurrences
+}
+
+// clipRepetitions 去掉重复片段中与词表匹配重叠的部分
+func (d *Detector) clipRepetitions(repetitions, matches []Occurrence) []Occurrence {
+	var clipped []Occurrence
+	for _, repetition := range repetitions {
+		for _, match := range matches {
+			if match.Start < repetition.End && repetition.Start < match.End {
+				if match.Start > repetition.Start {
+					repetition.End = match.Start
+				} else {
+					repetition.End = repetition.Start
+				}
+			}
+		}
+		if repetition.End > repetition.Start {
+			clipped = append(clipped, repetition)
+		}
+	}
+	return clipped
+}
+
+// tokenize 按标点切分成若干段，每段由汉字和英文单词组成，空白和缩写中的撇号不打断
+func tokenize(runes []rune) [][]token {
+	var runs [][]token
+	var current []token
+	flush := func() {
+		if len(current) > 0 {
+			runs = append(runs, current)
+			current = nil
+		}
+	}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			current = append(current, token{text: string(r), start: i, end: i + 1, han: true})
+			i++
+		case isLatinRune(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (isLatinRune(runes[i]) || unicode.IsDigit(runes[i]) || isContraction(runes, i)) {
+				i++
+			}
+			current = append(current, token{text: string(runes[start:i]), start: start, end: i})
+		case unicode.IsSpace(r):
+			i++
+		default:
+			flush()
+			i++
+		}
+	}
+	flush()
+	return runs
+}
+
+// sameTokens 判断两段字词是否相同
+func sameTokens(a, b []token) bool {
+	for i := range a {
+		if a[i].text != b[i].text {
+			return false
+		}
+	}
+	return true
+}
+
+// buildSpans 把原文按赘词位置切成片段，方便前端直接渲染高亮
+func buildSpans(runes []rune, occurrences []Occurrence) []Span {
+	spans := []Span{}
+	position := 0
+	for _, occurrence := range occurrences {
+		if occurrence.Start > position {
+			spans = append(spans, Span{Text: string(runes[position:occurrence.Start])})
+		}
+		spans = append(spans, Span{Text: string(runes[occurrence.Start:occurrence.End]), Category: occurrence.Category})
+		position = occurrence.End
+	}
+	if position < len(runes) {
+		spans = append(spans, Span{Text: string(runes[position:])})
+	}
+	return spans
+}
+
+// hasRunePrefix 判断runes是否以prefix开头
+func hasRunePrefix(runes, prefix []rune) bool {
+	if len(prefix) > len(runes) {
+		return false
+	}
+	for i, r := range prefix {
+		if runes[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// isLatinRune 判断是否是英文字母
+func isLatinRune(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+// isContraction 判断位置i是否是英文缩写中的撇号，如"it's""don’t"，缩写算一个词
+func isContraction(runes []rune, i int) bool {
+	return (runes[i] == '\'' || runes[i] == '’') && i > 0 && isLatinRune(runes[i-1]) &&
+		i+1 < len(runes) && isLatinRune(runes[i+1])
+}
+
+// isWordRune 判断是否是构成词语的字符
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDetectDisfluencies 测试赘词的类别、位置、独立使用判断和自定义词表
+func TestDetectDisfluencies(t *testing.T) {
+	text := "嗯，那个，我我我觉得这个方案很好，那个人不可能不同意。I think, um, it is really good good."
+	result := NewDetector(DefaultLexicon()).Detect(text)
+
+	want := []Occurrence{
+		{CategoryFiller, "嗯", 0, 1},
+		{CategoryFiller, "那个", 2, 4},
+		{CategoryRepetition, "我", 6, 7},
+		{CategoryHedge, "我觉得", 7, 10},
+		{CategoryIntensifier, "很", 14, 15},
+		{CategoryHedge, "I think", 27, 34},
+		{CategoryFiller, "um", 36, 38},
+		{CategoryIntensifier, "really", 46, 52},
+		{CategoryRepetition, "good", 58, 62},
+	}
+	if len(result.Occurrences) != len(want) {
+		t.Fatalf("期望%d处赘词，实际: %+v", len(want), result.Occurrences)
+	}
+	runes := []rune(text)
+	for i, occurrence := range result.Occurrences {
+		if occurrence != want[i] {
+			t.Errorf("第%d处不正确: 期望%+v，实际%+v", i, want[i], occurrence)
+		}
+		if string(runes[occurrence.Start:occurrence.End]) != occurrence.Text {
+			t.Errorf("偏移与文本不一致: %+v", occurrence)
+		}
+	}
+	if result.Count(CategoryHedge) != 2 || result.Total != len(want) {
+		t.Errorf("统计不正确: %+v", result.Counts)
+	}
+
+	var joined strings.Builder
+	highlighted := 0
+	for _, span := range result.Spans {
+		joined.WriteString(span.Text)
+		if span.Category != "" {
+			highlighted++
+		}
+	}
+	if joined.String() != text || highlighted != len(want) {
+		t.Errorf("片段拼接应还原原文: %q", joined.String())
+	}
+
+	if got := NewDetector(DefaultLexicon()).Detect("天天看看书"); got.Total != 0 {
+		t.Errorf("叠词不应算作重复: %+v", got.Occurrences)
+	}
+
+	path := filepath.Join(t.TempDir(), "lexicon.yaml")
+	if err := os.WriteFile(path, []byte("intensifier:\n  words: [超]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lexicon, err := LoadLexicon(path)
+	if err != nil {
+		t.Fatalf("加载词表失败: %v", err)
+	}
+	custom := NewDetector(lexicon).Detect("嗯，超好，很好")
+	if custom.Count(CategoryIntensifier) != 1 || custom.Count(CategoryFiller) != 1 {
+		t.Errorf("自定义词表应只替换出现的类别: %+v", custom.Occurrences)
+	}
+}
@@ -0,0 +1,192 @@
+package analysis
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"reactedge/internal/i18n"
+)
+
+//go:embed lexicons/languages.yaml
+var defaultLanguagesYAML []byte
+
+// Language 回答使用的语言
+type Language string
+
+const (
+	LanguageChinese Language = "zh"
+	LanguageEnglish Language = "en"
+)
+
+// Range 数值的合适范围
+type Range struct {
+	Min float64 `yaml:"min" json:"min"`
+	Max float64 `yaml:"max" json:"max"`
+}
+
+// StyleCues 一个类比领域及其线索词
+type StyleCues struct {
+	Style string   `yaml:"style" json:"style"`
+	Cues  []string `yaml:"cues" json:"cues"`
+}
+
+// LanguageProfile 一种语言的分析参数和标记词，长度按单位计算（汉字或英文单词）
+type LanguageProfile struct {
+	Locale       string      `yaml:"locale" json:"locale"`             // 对应的界面语言，用于翻译建议
+	Pace         Range       `yaml:"pace" json:"pace"`                 // 合适语速（单位/分钟）
+	Sentence     Range       `yaml:"sentence" json:"sentence"`         // 合适的平均句长
+	Answer       Range       `yaml:"answer" json:"answer"`             // 回答长度的信心阈值
+	Terminators  string      `yaml:"terminators" json:"terminators"`   // 句末标点
+	Confident    []string    `yaml:"confident" json:"confident"`       // 表达确定性的词
+	Examples     []string    `yaml:"examples" json:"examples"`         // 举例
+	Contrast     []string    `yaml:"contrast" json:"contrast"`         // 转折对比
+	Analogy      []string    `yaml:"analogy" json:"analogy"`           // 类比
+	Causal       []string    `yaml:"causal" json:"causal"`             // 因果推理
+	Opinion      []string    `yaml:"opinion" json:"opinion"`           // 主观判断
+	Essence      []string    `yaml:"essence" json:"essence"`           // 追问本质
+	Intensifiers []string    `yaml:"intensifiers" json:"intensifiers"` // 空洞的程度副词
+	Stopwords    []string    `yaml:"stopwords" json:"stopwords"`       // 统计重复词时忽略的虚词
+	Metaphors    []StyleCues `yaml:"metaphors" json:"metaphors"`       // 类比领域，按顺序匹配
+}
+
+// DefaultLanguageProfiles 返回内置的各语言分析参数
+func DefaultLanguageProfiles() map[Language]*LanguageProfile {
+	profiles := make(map[Language]*LanguageProfile)
+	if err := yaml.Unmarshal(defaultLanguagesYAML, &profiles); err != nil {
+		// 内置参数有测试保证，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析内置语言参数失败: %v", err))
+	}
+	return profiles
+}
+
+var (
+	languageProfiles     map[Language]*LanguageProfile
+	languageProfilesOnce sync.Once
+)
+
+// Profile 返回语言的分析参数，不认识的语言使用中文参数
+func (l Language) Profile() *LanguageProfile {
+	languageProfilesOnce.Do(func() {
+		languageProfiles = DefaultLanguageProfiles()
+	})
+	if profile, ok := languageProfiles[l]; ok {
+		return profile
+	}
+	return languageProfiles[LanguageChinese]
+}
+
+// LanguageForLocale 界面语言对应的回答语言
+func LanguageForLocale(locale string) Language {
+	if i18n.IsEnglish(locale) {
+		return LanguageEnglish
+	}
+	return LanguageChinese
+}
+
+// DetectLanguage 按汉字数和英文单词数判断回答语言，中英夹杂时按占多数的语言，相同时按中文
+func DetectLanguage(text string) Language {
+	han, latin := 0, 0
+	for _, tokens := range tokenize([]rune(text)) {
+		for _, t := range tokens {
+			if t.han {
+				han++
+			} else if isLatinRune([]rune(t.text)[0]) {
+				latin++
+			}
+		}
+	}
+	if latin > han {
+		return LanguageEnglish
+	}
+	return LanguageChinese
+}
+
+// countUnits 统计长度单位：每个汉字、每个英文单词或数字算一个
+func countUnits(text string) int {
+	count := 0
+	for _, tokens := range tokenize([]rune(text)) {
+		count += len(tokens)
+	}
+	return count
+}
+
+// CountMarkers 统计文本中标记词出现的次数，不区分大小写，英文标记词按整词匹配
+func CountMarkers(text string, markers []string) int {
+	lower := []rune(normalizeApostrophes(strings.ToLower(text)))
+	count := 0
+	for _, marker := range markers {
+		word := []rune(normalizeApostrophes(strings.ToLower(strings.TrimSpace(marker))))
+		if len(word) == 0 {
+			continue
+		}
+		latin := isLatinRune(word[0])
+		for i := 0; i+len(word) <= len(lower); i++ {
+			if !hasRunePrefix(lower[i:], word) {
+				continue
+			}
+			end := i + len(word)
+			if latin && (i > 0 && isLatinRune(lower[i-1]) || end < len(lower) && isLatinRune(lower[end])) {
+				continue
+			}
+			count++
+			i = end - 1
+		}
+	}
+	return count
+}
+
+// HasMarker 文本中是否出现任一标记词
+func HasMarker(text string, markers []string) bool {
+	return CountMarkers(text, markers) > 0
+}
+
+// CountSentences 按句末标点统计句子数
+func (p *LanguageProfile) CountSentences(text string) int {
+	count := 0
+	for _, r := range text {
+		if strings.ContainsRune(p.Terminators, r) {
+			count++
+		}
+	}
+	return count
+}
+
+// repeatedWords 统计出现超过limit次的英文实词个数，中文按字统计没有意义，不参与
+func (p *LanguageProfile) repeatedWords(text string, limit int) int {
+	stopwords := make(map[string]bool, len(p.Stopwords))
+	for _, word := range p.Stopwords {
+		stopwords[strings.ToLower(word)] = true
+	}
+
+	freq := make(map[string]int)
+	for _, tokens := range tokenize([]rune(strings.ToLower(text))) {
+		for _, t := range tokens {
+			if t.han || !isLatinRune([]rune(t.text)[0]) || stopwords[t.text] {
+				continue
+			}
+			freq[t.text]++
+		}
+	}
+
+	repeated := 0
+	for _, count := range freq {
+		if count > limit {
+			repeated++
+		}
+	}
+	return repeated
+}
+
+// normalizeApostrophes 把弯撇号换成直撇号，语音转写常输出"I’m"
+func normalizeApostrophes(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '’' || r == '‘' {
+			return '\''
+		}
+		return r
+	}, text)
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+// TestDetectLanguage 测试按汉字数和英文单词数判断回答语言
+func TestDetectLanguage(t *testing.T) {
+	cases := []struct {
+		text string
+		want Language
+	}{
+		{"书店变多了，可是没有人真正在看书。", LanguageChinese},
+		{"Bookstores are everywhere, but nobody reads.", LanguageEnglish},
+		{"我觉得这个app很好用", LanguageChinese},
+		{"I think 内卷 is like a game with no end.", LanguageEnglish},
+		{"", LanguageChinese},
+	}
+	for _, c := range cases {
+		if got := DetectLanguage(c.text); got != c.want {
+			t.Errorf("DetectLanguage(%q) = %s，期望%s", c.text, got, c.want)
+		}
+	}
+
+	if got := CountMarkers("But I’m sure. Butter is BUT a detail, but.", []string{"but", "i'm sure"}); got != 4 {
+		t.Errorf("英文标记词应整词、不区分大小写匹配: %d", got)
+	}
+	if got := CountMarkers("因为下雨，所以因为", []string{"因为", "所以"}); got != 3 {
+		t.Errorf("中文标记词计数不正确: %d", got)
+	}
+}
+
+// TestAnalyzeEnglish 测试英文回答使用英文的赘词、信心和举例标记评分
+func TestAnalyzeEnglish(t *testing.T) {
+	analyzer := NewSpeechAnalyzer()
+
+	confident := analyzer.AnalyzeText("I'm sure remote work is here to stay. For example, my team ships faster "+
+		"than ever because nobody loses two hours commuting. Offices will definitely survive, but as meeting "+
+		"places rather than desks. I like that change.", 30*time.Second)
+	if confident.Language != LanguageEnglish || confident.WordCount != 37 {
+		t.Fatalf("应按英文单词统计: %s %d", confident.Language, confident.WordCount)
+	}
+	if confident.Disfluencies.Count(CategoryFiller) != 0 {
+		t.Errorf("\"I like that\"中的like不是口头禅: %+v", confident.Disfluencies.Occurrences)
+	}
+	if confident.WordsPerMinute != 74 || confident.ClarityScore != 75 {
+		t.Errorf("语速或清晰度不正确: %.0f %d", confident.WordsPerMinute, confident.ClarityScore)
+	}
+
+	hesitant := analyzer.AnalyzeText("Um, so, I guess it's, like, maybe fine? You know, kind of.", 10*time.Second)
+	if hesitant.Disfluencies.Count(CategoryFiller) != 4 || hesitant.Disfluencies.Count(CategoryHedge) != 3 {
+		t.Errorf("英文赘词检测不正确: %+v", hesitant.Disfluencies.Counts)
+	}
+	if confident.ConfidenceScore <= hesitant.ConfidenceScore {
+		t.Errorf("确定、举例的回答信心分应更高: %d <= %d", confident.ConfidenceScore, hesitant.ConfidenceScore)
+	}
+
+	// 语速按回答语言判断：英文每分钟180词偏快，即使界面是中文
+	tips := analyzer.GetSpeechTips(&SpeechResult{Language: LanguageEnglish, WordsPerMinute: 180, RhythmScore: 60,
+		ClarityScore: 70, ConfidenceScore: 60}, "zh-CN")
+	if len(tips) != 1 || tips[0] != "语速稍快，建议适当放慢，让听众有时间消化观点" {
+		t.Errorf("英文回答应按英文语速标准: %v", tips)
+	}
+}
@@ -0,0 +1,431 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"time"
+	"unicode"
+
+	"reactedge/pkg/audio"
+)
+
+// 声学分析参数
+const (
+	frameSize        = 20 * time.Millisecond  // 能量分帧长度
+	minPause         = 250 * time.Millisecond // 短于此的静音视为正常换气，不算停顿
+	minSpeechRun     = 60 * time.Millisecond  // 短于此的有声段视为噪声
+	pitchSampleRate  = 8000                   // 基频估计前降采样到的采样率
+	pitchWindow      = 40 * time.Millisecond  // 基频估计窗口
+	minPitch         = 75.0                   // 基频搜索范围(Hz)
+	maxPitch         = 400.0
+	pitchConfidence  = 0.5 // 自相关峰值低于此的帧不计入基频
+	silenceFloorDB   = -50.0
+	speechMarginDB   = 12.0 // 高于背景噪声多少分贝算有声
+	rateWindowLength = 5 * time.Second
+)
+
+// Pause 一次停顿
+type Pause struct {
+	Start    time.Duration `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// RatePoint 某一时间窗内的语速
+type RatePoint struct {
+	Start          time.Duration `json:"start"`
+	CharsPerMinute float64       `json:"chars_per_minute"`
+}
+
+// Prosody 基于录音的声学韵律分析结果
+type Prosody struct {
+	Duration       time.Duration `json:"duration"`
+	SpeechDuration time.Duration `json:"speech_duration"` // 有声部分总时长
+	SpeechRatio    float64       `json:"speech_ratio"`    // 有声时长占比（不含首尾静音）
+	Pauses         []Pause       `json:"pauses"`
+	LongestPause   time.Duration `json:"longest_pause"`
+	MeanPause      time.Duration `json:"mean_pause"`
+
+	// 语速随时间的变化，需要带时间戳的转写片段
+	RateCurve     []RatePoint `json:"rate_curve,omitempty"`
+	RateVariation float64     `json:"rate_variation"` // 各时间窗语速的变异系数
+
+	PitchMean      float64 `json:"pitch_mean"`        // 平均基频(Hz)
+	PitchStdDev    float64 `json:"pitch_std_dev"`     // 基频标准差(半音)，反映语调起伏
+	PitchRange     float64 `json:"pitch_range"`       // 基频5%-95%分位范围(半音)
+	VoicedFrames   int     `json:"voiced_frames"`     // 检测到基频的帧数
+	VolumeMeanDB   float64 `json:"volume_mean_db"`    // 有声帧平均音量(dBFS)
+	VolumeStdDevDB float64 `json:"volume_std_dev_db"` // 有声帧音量标准差
+	VolumeRangeDB  float64 `json:"volume_range_db"`   // 有声帧音量5%-95%分位范围
+
+	RhythmScore int `json:"rhythm_score"` // 根据停顿、语速稳定性和语调起伏计算的节奏分
+}
+
+// AnalyzeProsody 分析录音的停顿、语速、基频和音量；segments为转写片段，可为空
+func AnalyzeProsody(pcm *audio.PCM, segments []audio.Segment) *Prosody {
+	result := &Prosody{Duration: pcm.Duration(), Pauses: []Pause{}}
+	samples := pcm.Mono()
+	frameLen := int(float64(pcm.SampleRate) * frameSize.Seconds())
+	if frameLen == 0 || len(samples) < frameLen {
+		return result
+	}
+
+	energies := frameEnergies(samples, frameLen)
+	speech := detectSpeech(energies)
+	result.analyzePauses(speech)
+	result.analyzeVolume(energies, speech)
+	result.analyzePitch(samples, pcm.SampleRate, speech)
+	result.analyzeRate(segments)
+	result.RhythmScore = result.rhythmScore()
+	return result
+}
+
+// frameEnergies 每帧的均方根音量(dBFS)
+func frameEnergies(samples []float64, frameLen int) []float64 {
+	energies := make([]float64, len(samples)/frameLen)
+	for i := range energies {
+		sum := 0.0
+		for _, sample := range samples[i*frameLen : (i+1)*frameLen] {
+			sum += sample * sample
+		}
+		energies[i] = 20 * math.Log10(math.Sqrt(sum/float64(frameLen))+1e-9)
+	}
+	return energies
+}
+
+// detectSpeech 基于能量的语音活动检测：阈值取背景噪声之上speechMarginDB，并去掉过短的有声段
+func detectSpeech(energies []float64) []bool {
+	noise := percentile(energies, 0.1)
+	threshold := math.Max(noise+speechMarginDB, silenceFloorDB)
+
+	speech := make([]bool, len(energies))
+	for i, energy := range energies {
+		speech[i] = energy > threshold
+	}
+
+	minRun := int(minSpeechRun / frameSize)
+	for start := 0; start < len(speech); {
+		if !speech[start] {
+			start++
+			continue
+		}
+		end := start
+		for end < len(speech) && speech[end] {
+			end++
+		}
+		if end-start < minRun {
+			for i := start; i < end; i++ {
+				speech[i] = false
+			}
+		}
+		start = end
+	}
+	return speech
+}
+
+// analyzePauses 统计首个有声帧和最后一个有声帧之间的停顿
+func (p *Prosody) analyzePauses(speech []bool) {
+	first, last := -1, -1
+	voiced := 0
+	for i, isSpeech := range speech {
+		if isSpeech {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			voiced++
+		}
+	}
+	p.SpeechDuration = time.Duration(voiced) * frameSize
+	if first < 0 {
+		return
+	}
+	p.SpeechRatio = round2(float64(voiced) / float64(last-first+1))
+
+	var total time.Duration
+	for i := first; i <= last; {
+		if speech[i] {
+			i++
+			continue
+		}
+		start := i
+		for i <= last && !speech[i] {
+			i++
+		}
+		duration := time.Duration(i-start) * frameSize
+		if duration < minPause {
+			continue
+		}
+		p.Pauses = append(p.Pauses, Pause{Start: time.Duration(start) * frameSize, Duration: duration})
+		total += duration
+		if duration > p.LongestPause {
+			p.LongestPause = duration
+		}
+	}
+	if len(p.Pauses) > 0 {
+		p.MeanPause = total / time.Duration(len(p.Pauses))
+	}
+}
+
+// analyzeVolume 统计有声帧的音量分布
+func (p *Prosody) analyzeVolume(energies []float64, speech []bool) {
+	var voiced []float64
+	for i, energy := range energies {
+		if speech[i] {
+			voiced = append(voiced, energy)
+		}
+	}
+	if len(voiced) == 0 {
+		return
+	}
+	mean, std := meanStdDev(voiced)
+	p.VolumeMeanDB = round2(mean)
+	p.VolumeStdDevDB = round2(std)
+	p.VolumeRangeDB = round2(percentile(voiced, 0.95) - percentile(voiced, 0.05))
+}
+
+// analyzePitch 在有声帧上用归一化自相关估计基频，统计语调起伏（以半音计）
+func (p *Prosody) analyzePitch(samples []float64, sampleRate int, speech []bool) {
+	// 降采样以减少计算量，先做简单的滑动平均低通
+	step := sampleRate / pitchSampleRate
+	if step < 1 {
+		step = 1
+	}
+	rate := sampleRate / step
+	decimated := make([]float64, 0, len(samples)/step)
+	for i := 0; i+step <= len(samples); i += step {
+		sum := 0.0
+		for _, sample := range samples[i : i+step] {
+			sum += sample
+		}
+		decimated = append(decimated, sum/float64(step))
+	}
+
+	window := int(float64(rate) * pitchWindow.Seconds())
+	hop := int(float64(rate) * frameSize.Seconds())
+	minLag, maxLag := int(float64(rate)/maxPitch), int(float64(rate)/minPitch)
+	if hop == 0 || maxLag >= window {
+		return
+	}
+
+	var pitches []float64
+	for frame, isSpeech := range speech {
+		start := frame * hop
+		if !isSpeech || start+window+maxLag+1 > len(decimated) {
+			continue
+		}
+		if pitch, ok := estimatePitch(decimated[start:start+window+maxLag+1], window, minLag, maxLag, rate); ok {
+			pitches = append(pitches, pitch)
+		}
+	}
+	p.VoicedFrames = len(pitches)
+	if len(pitches) < 3 {
+		return
+	}
+
+	reference := percentile(pitches, 0.5)
+	semitones := make([]float64, len(pitches))
+	sum := 0.0
+	for i, pitch := range pitches {
+		semitones[i] = 12 * math.Log2(pitch/reference)
+		sum += pitch
+	}
+	_, std := meanStdDev(semitones)
+	p.PitchMean = round2(sum / float64(len(pitches)))
+	p.PitchStdDev = round2(std)
+	p.PitchRange = round2(percentile(semitones, 0.95) - percentile(semitones, 0.05))
+}
+
+// estimatePitch 返回自相关峰值对应的基频，峰值不够明显时认为是清音或噪声
+func estimatePitch(frame []float64, window, minLag, maxLag, rate int) (float64, bool) {
+	energy := 0.0
+	for _, sample := range frame[:window] {
+		energy += sample * sample
+	}
+	if energy == 0 {
+		return 0, false
+	}
+
+	correlations := make([]float64, maxLag+2)
+	best := 0.0
+	for lag := minLag; lag <= maxLag+1 && lag+window <= len(frame); lag++ {
+		corr, lagEnergy := 0.0, 0.0
+		for i := 0; i < window; i++ {
+			corr += frame[i] * frame[i+lag]
+			lagEnergy += frame[i+lag] * frame[i+lag]
+		}
+		if lagEnergy > 0 {
+			correlations[lag] = corr / math.Sqrt(energy*lagEnergy)
+		}
+		if lag <= maxLag && correlations[lag] > best {
+			best = correlations[lag]
+		}
+	}
+	if best < pitchConfidence {
+		return 0, false
+	}
+
+	// 周期的整数倍处自相关同样很高，取第一个接近最大值的峰以避免低八度误差
+	for lag := minLag + 1; lag <= maxLag; lag++ {
+		value := correlations[lag]
+		if value >= 0.9*best && value >= correlations[lag-1] && value >= correlations[lag+1] {
+			return float64(rate) / float64(lag), true
+		}
+	}
+	return 0, false
+}
+
+// analyzeRate 按固定时间窗统计转写片段的语速（字/分钟，不含标点和空白）
+func (p *Prosody) analyzeRate(segments []audio.Segment) {
+	if len(segments) == 0 || p.Duration == 0 {
+		return
+	}
+
+	buckets := int(p.Duration/rateWindowLength) + 1
+	chars := make([]float64, buckets)
+	for _, segment := range segments {
+		if segment.End <= segment.Start {
+			continue
+		}
+		count := 0
+		for _, r := range segment.Text {
+			if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+				count++
+			}
+		}
+		// 片段跨越多个时间窗时按时长比例分配字数
+		perSecond := float64(count) / (segment.End - segment.Start).Seconds()
+		for i := int(segment.Start / rateWindowLength); i < buckets; i++ {
+			windowStart := time.Duration(i) * rateWindowLength
+			overlap := minDuration(segment.End, windowStart+rateWindowLength) - maxDuration(segment.Start, windowStart)
+			if overlap <= 0 {
+				break
+			}
+			chars[i] += perSecond * overlap.Seconds()
+		}
+	}
+
+	var rates []float64
+	for i, count := range chars {
+		start := time.Duration(i) * rateWindowLength
+		length := minDuration(rateWindowLength, p.Duration-start)
+		if length < time.Second {
+			continue
+		}
+		rate := round2(count / length.Minutes())
+		p.RateCurve = append(p.RateCurve, RatePoint{Start: start, CharsPerMinute: rate})
+		if rate > 0 {
+			rates = append(rates, rate)
+		}
+	}
+	if mean, std := meanStdDev(rates); mean > 0 {
+		p.RateVariation = round2(std / mean)
+	}
+}
+
+// rhythmScore 节奏分：停顿适度、语速稳定、语调有起伏得分高
+func (p *Prosody) rhythmScore() int {
+	if p.SpeechDuration == 0 {
+		return 0
+	}
+	score := 60
+
+	// 每分钟3-10次停顿最自然，过长的停顿显得卡壳
+	perMinute := float64(len(p.Pauses)) / p.Duration.Minutes()
+	switch {
+	case perMinute >= 3 && perMinute <= 10:
+		score += 10
+	case perMinute > 15:
+		score -= 10
+	}
+	if p.LongestPause > 3*time.Second {
+		score -= 15
+	}
+
+	// 语调起伏：标准差不足1个半音显得单调，超过5个半音显得夸张
+	switch {
+	case p.VoicedFrames == 0:
+	case p.PitchStdDev < 1:
+		score -= 10
+	case p.PitchStdDev <= 5:
+		score += 15
+	}
+
+	// 音量有一定变化说明有重音
+	if p.VolumeStdDevDB >= 3 && p.VolumeStdDevDB <= 10 {
+		score += 5
+	}
+
+	if p.RateVariation > 0.4 {
+		score -= 10
+	} else if len(p.RateCurve) > 1 {
+		score += 10
+	}
+	return clampScore(score)
+}
+
+// meanStdDev 均值和样本标准差
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)-1))
+}
+
+// percentile 分位数（线性插值）
+func percentile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	position := q * float64(len(sorted)-1)
+	lower := int(position)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(position-float64(lower))
+}
+
+// clampScore 把分数限制在0-100
+func clampScore(score int) int {
+	if score > 100 {
+		return 100
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// minDuration 较短的时长
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxDuration 较长的时长
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"reactedge/pkg/audio"
+)
+
+// synthesize 生成测试录音：tone段为从from到to滑动的正弦波，其余为低噪声
+func synthesize(sampleRate int, parts []struct {
+	duration time.Duration
+	from, to float64
+}) *audio.PCM {
+	pcm := &audio.PCM{SampleRate: sampleRate, Channels: 1}
+	random := rand.New(rand.NewSource(1))
+	phase := 0.0
+	for _, part := range parts {
+		n := int(part.duration.Seconds() * float64(sampleRate))
+		for i := 0; i < n; i++ {
+			sample := random.NormFloat64() * 30
+			if part.from > 0 {
+				freq := part.from + (part.to-part.from)*float64(i)/float64(n)
+				phase += 2 * math.Pi * freq / float64(sampleRate)
+				sample += 8000 * math.Sin(phase)
+			}
+			pcm.Samples = append(pcm.Samples, int16(sample))
+		}
+	}
+	return pcm
+}
+
+// TestAnalyzeProsody 测试停顿检测、基频估计、语速曲线以及与文本分析的合并
+func TestAnalyzeProsody(t *testing.T) {
+	pcm := synthesize(16000, []struct {
+		duration time.Duration
+		from, to float64
+	}{
+		{500 * time.Millisecond, 0, 0},
+		{2 * time.Second, 150, 220},
+		{time.Second, 0, 0},
+		{3 * time.Second, 180, 180},
+		{100 * time.Millisecond, 0, 0}, // 换气，不算停顿
+		{2 * time.Second, 200, 160},
+		{500 * time.Millisecond, 0, 0},
+	})
+	segments := []audio.Segment{
+		{Start: 500 * time.Millisecond, End: 2500 * time.Millisecond, Text: "书店变多了，"},
+		{Start: 3500 * time.Millisecond, End: 8600 * time.Millisecond, Text: "可是大家都在拍照，没有人真正在看书。"},
+	}
+
+	prosody := AnalyzeProsody(pcm, segments)
+	if len(prosody.Pauses) != 1 {
+		t.Fatalf("期望1次停顿，实际: %+v", prosody.Pauses)
+	}
+	if pause := prosody.Pauses[0]; pause.Start < 2400*time.Millisecond || pause.Start > 2600*time.Millisecond ||
+		pause.Duration < 900*time.Millisecond || pause.Duration > 1100*time.Millisecond {
+		t.Errorf("停顿位置或时长不正确: %+v", pause)
+	}
+	if prosody.PitchMean < 150 || prosody.PitchMean > 220 {
+		t.Errorf("平均基频应在150-220Hz之间，实际: %.1f", prosody.PitchMean)
+	}
+	if prosody.PitchStdDev < 1 || prosody.PitchRange < 4 {
+		t.Errorf("滑音应有明显的语调起伏: std=%.2f range=%.2f", prosody.PitchStdDev, prosody.PitchRange)
+	}
+	if len(prosody.RateCurve) != 2 || prosody.RateCurve[0].CharsPerMinute <= 0 {
+		t.Errorf("语速曲线不正确: %+v", prosody.RateCurve)
+	}
+
+	result := NewSpeechAnalyzer().AnalyzeRecording("书店变多了，可是大家都在拍照，没有人真正在看书。", pcm, segments)
+	if result.Prosody == nil || result.PauseCount != 1 || result.Duration != pcm.Duration() {
+		t.Errorf("录音分析应合并声学停顿和真实时长: %+v", result)
+	}
+
+	silent := AnalyzeProsody(&audio.PCM{SampleRate: 16000, Channels: 1, Samples: make([]int16, 16000)}, nil)
+	if silent.SpeechDuration != 0 || silent.RhythmScore != 0 {
+		t.Errorf("静音录音不应检测到语音: %+v", silent)
+	}
+}
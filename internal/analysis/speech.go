@@ -2,8 +2,10 @@ package analysis
 
 import (
 	"regexp"
-	"strings"
 	"time"
+
+	"reactedge/internal/i18n"
+	"reactedge/pkg/audio"
 )
 
 // SpeechAnalyzer 语音分析器
@@ -16,11 +18,16 @@ type SpeechAnalyzer struct {
 	commaCount    int
 	duration      time.Duration
 	wordsPerMinute float64
+	detector      *Detector
+	disfluencies  *Disfluencies
+	language      Language
+	profile       *LanguageProfile
 }
 
 // SpeechResult 语音分析结果
 type SpeechResult struct {
 	Text            string         `json:"text"`
+	Language        Language       `json:"language"`          // 检测到的回答语言，决定评分用的标记词和阈值
 	WordCount       int            `json:"word_count"`
 	SentenceCount   int            `json:"sentence_count"`
 	QuestionCount   int            `json:"question_count"`
@@ -30,19 +37,22 @@ type SpeechResult struct {
 	RhythmScore     int            `json:"rhythm_score"`
 	ClarityScore    int            `json:"clarity_score"`
 	ConfidenceScore int            `json:"confidence_score"`
+	Prosody         *Prosody       `json:"prosody,omitempty"` // 有录音时的声学分析
+	Disfluencies    *Disfluencies  `json:"disfluencies"`      // 口头禅、犹豫词等赘词的位置
 }
 
 // NewSpeechAnalyzer 创建语音分析器
 func NewSpeechAnalyzer() *SpeechAnalyzer {
-	return &SpeechAnalyzer{}
+	return &SpeechAnalyzer{detector: DefaultDetector()}
 }
 
-// AnalyzeText 分析文本内容（模拟语音转文字后的分析）
+// AnalyzeText 分析回答文本；录音回答传入转写文本和录音的真实时长
 func (sa *SpeechAnalyzer) AnalyzeText(text string, duration time.Duration) *SpeechResult {
 	sa.analyzeText(text)
 	sa.duration = duration
+	sa.disfluencies = sa.detector.Detect(text)
 
-	// 计算语速（字/分钟）
+	// 计算语速（中文字/分钟，英文词/分钟）
 	if duration.Seconds() > 0 {
 		sa.wordsPerMinute = float64(sa.wordCount) / duration.Minutes()
 	}
@@ -58,6 +68,7 @@ func (sa *SpeechAnalyzer) AnalyzeText(text string, duration time.Duration) *Spee
 
 	return &SpeechResult{
 		Text:            text,
+		Language:        sa.language,
 		WordCount:       sa.wordCount,
 		SentenceCount:   sa.sentenceCount,
 		QuestionCount:   sa.questionCount,
@@ -67,17 +78,33 @@ func (sa *SpeechAnalyzer) AnalyzeText(text string, duration time.Duration) *Spee
 		RhythmScore:     rhythmScore,
 		ClarityScore:    clarityScore,
 		ConfidenceScore: confidenceScore,
+		Disfluencies:    sa.disfluencies,
+	}
+}
+
+// AnalyzeRecording 分析录音回答：文本指标之外合并声学韵律分析
+// 停顿次数改用实际检测到的停顿，节奏分取文本节奏分与声学节奏分的平均
+func (sa *SpeechAnalyzer) AnalyzeRecording(text string, pcm *audio.PCM, segments []audio.Segment) *SpeechResult {
+	result := sa.AnalyzeText(text, pcm.Duration())
+	prosody := AnalyzeProsody(pcm, segments)
+	if prosody.SpeechDuration == 0 {
+		return result
 	}
+
+	result.Prosody = prosody
+	result.PauseCount = len(prosody.Pauses)
+	result.RhythmScore = (result.RhythmScore + prosody.RhythmScore) / 2
+	return result
 }
 
-// analyzeText 分析文本基本特征
+// analyzeText 分析文本基本特征，字数中文按汉字、英文按单词计算
 func (sa *SpeechAnalyzer) analyzeText(text string) {
-	words := strings.Fields(text)
-	sa.wordCount = len(words)
+	sa.language = DetectLanguage(text)
+	sa.profile = sa.language.Profile()
+	sa.wordCount = countUnits(text)
 
 	// 计算句子数
-	sentences := regexp.MustCompile(`[。！？.!?]`).FindAllString(text, -1)
-	sa.sentenceCount = len(sentences)
+	sa.sentenceCount = sa.profile.CountSentences(text)
 
 	// 计算问号数
 	questions := regexp.MustCompile(`[？?]`).FindAllString(text, -1)
@@ -102,7 +129,10 @@ func (sa *SpeechAnalyzer) calculateRhythmScore(text string) int {
 
 	// 根据标点符号密度调整
 	totalPunctuation := sa.questionCount + sa.exclamationCount + sa.periodCount + sa.commaCount
-	punctuationDensity := float64(totalPunctuation) / float64(sa.wordCount) * 100
+	punctuationDensity := 0.0
+	if sa.wordCount > 0 {
+		punctuationDensity = float64(totalPunctuation) / float64(sa.wordCount) * 100
+	}
 
 	if punctuationDensity > 10 {
 		score += 20 // 标点丰富，节奏感强
@@ -110,12 +140,8 @@ func (sa *SpeechAnalyzer) calculateRhythmScore(text string) int {
 		score -= 10 // 标点稀少，可能节奏平淡
 	}
 
-	// 检查是否有停顿词
-	pauseWords := []string{"嗯", "啊", "这个", "那个", "就是说"}
-	pauseCount := 0
-	for _, word := range pauseWords {
-		pauseCount += strings.Count(text, word)
-	}
+	// 检查是否有口头禅和重复
+	pauseCount := sa.disfluencies.Count(CategoryFiller) + sa.disfluencies.Count(CategoryRepetition)
 
 	if pauseCount > 3 {
 		score -= 15 // 太多停顿词
@@ -141,28 +167,17 @@ func (sa *SpeechAnalyzer) calculateClarityScore(text string) int {
 	if sa.sentenceCount > 0 {
 		avgSentenceLength := float64(sa.wordCount) / float64(sa.sentenceCount)
 
-		if avgSentenceLength > 20 {
+		if avgSentenceLength > sa.profile.Sentence.Max {
 			score -= 20 // 句子过长，清晰度下降
-		} else if avgSentenceLength < 5 {
+		} else if avgSentenceLength < sa.profile.Sentence.Min {
 			score -= 10 // 句子过短，可能表达不完整
 		} else {
 			score += 15 // 句子长度适中
 		}
 	}
 
-	// 检查是否有重复词
-	words := strings.Fields(text)
-	wordFreq := make(map[string]int)
-	for _, word := range words {
-		wordFreq[word]++
-	}
-
-	repeatCount := 0
-	for _, count := range wordFreq {
-		if count > 3 {
-			repeatCount++
-		}
-	}
+	// 检查是否有重复词（忽略虚词）
+	repeatCount := sa.profile.repeatedWords(text, 3)
 
 	if repeatCount > 0 {
 		score -= repeatCount * 10 // 重复词过多
@@ -188,33 +203,21 @@ func (sa *SpeechAnalyzer) calculateConfidenceScore(text string) int {
 	score := 55 // 基础分数
 
 	// 检查表达确定性词
-	confidentWords := []string{"我认为", "我相信", "我确定", "绝对", "肯定", "确实"}
-	hesitantWords := []string{"可能", "也许", "大概", "不太确定", "我觉得", "应该是"}
-
-	confidentCount := 0
-	hesitantCount := 0
-
-	for _, word := range confidentWords {
-		confidentCount += strings.Count(text, word)
-	}
-
-	for _, word := range hesitantWords {
-		hesitantCount += strings.Count(text, word)
-	}
+	confidentCount := CountMarkers(text, sa.profile.Confident)
+	hesitantCount := sa.disfluencies.Count(CategoryHedge)
 
 	score += confidentCount * 5
 	score -= hesitantCount * 3
 
 	// 检查表达长度（较长的表达通常更有信心）
-	if sa.wordCount > 50 {
+	if float64(sa.wordCount) > sa.profile.Answer.Max {
 		score += 15
-	} else if sa.wordCount < 20 {
+	} else if float64(sa.wordCount) < sa.profile.Answer.Min {
 		score -= 10
 	}
 
 	// 检查是否有具体例子
-	if strings.Contains(text, "比如") || strings.Contains(text, "例如") ||
-	   strings.Contains(text, "就像") || strings.Contains(text, "比如说") {
+	if HasMarker(text, sa.profile.Examples) {
 		score += 10 // 有具体例子，说明有思考深度
 	}
 
@@ -228,14 +231,9 @@ func (sa *SpeechAnalyzer) calculateConfidenceScore(text string) int {
 	return score
 }
 
-// calculatePauseCount 计算停顿次数（基于标点符号和停顿词）
+// calculatePauseCount 计算停顿次数（基于标点符号和口头禅）
 func (sa *SpeechAnalyzer) calculatePauseCount(text string) int {
-	pauseWords := []string{"嗯", "啊", "这个", "那个", "就是说", "也就是说"}
-	pauseCount := 0
-
-	for _, word := range pauseWords {
-		pauseCount += strings.Count(text, word)
-	}
+	pauseCount := sa.disfluencies.Count(CategoryFiller)
 
 	// 标点符号也算作停顿
 	pauseCount += sa.commaCount + sa.periodCount + sa.questionCount + sa.exclamationCount
@@ -243,38 +241,70 @@ func (sa *SpeechAnalyzer) calculatePauseCount(text string) int {
 	return pauseCount
 }
 
-// GetSpeechTips 获取语音建议
-func (sa *SpeechAnalyzer) GetSpeechTips(result *SpeechResult) []string {
+// GetSpeechTips 获取语音建议，建议按locale翻译；语速标准按回答语言，未知时按locale
+func (sa *SpeechAnalyzer) GetSpeechTips(result *SpeechResult, locale string) []string {
 	tips := []string{}
+	tip := func(key string) {
+		tips = append(tips, i18n.T(locale, key))
+	}
 
 	// 语速建议
-	if result.WordsPerMinute > 200 {
-		tips = append(tips, "语速稍快，建议适当放慢，让听众有时间消化观点")
-	} else if result.WordsPerMinute < 120 {
-		tips = append(tips, "语速稍慢，可以适当加快节奏，增加表现力")
+	language := result.Language
+	if language == "" {
+		language = LanguageForLocale(locale)
+	}
+	pace := language.Profile().Pace
+	if result.WordsPerMinute > pace.Max {
+		tip("speech.tip.pace_fast")
+	} else if result.WordsPerMinute < pace.Min {
+		tip("speech.tip.pace_slow")
 	} else {
-		tips = append(tips, "语速适中，很好地控制了表达节奏")
+		tip("speech.tip.pace_good")
 	}
 
 	// 节奏建议
 	if result.RhythmScore < 50 {
-		tips = append(tips, "节奏可以更丰富，适当使用停顿和语调变化")
+		tip("speech.tip.rhythm_low")
 	} else if result.RhythmScore > 80 {
-		tips = append(tips, "节奏控制很好，有感染力的表达方式")
+		tip("speech.tip.rhythm_high")
+	}
+
+	// 声学建议
+	if prosody := result.Prosody; prosody != nil {
+		if prosody.LongestPause > 3*time.Second {
+			tip("speech.tip.long_pause")
+		}
+		if prosody.VoicedFrames > 0 && prosody.PitchStdDev < 1 {
+			tip("speech.tip.flat_pitch")
+		}
+		if prosody.RateVariation > 0.4 {
+			tip("speech.tip.rate_variation")
+		}
 	}
 
 	// 清晰度建议
 	if result.ClarityScore < 60 {
-		tips = append(tips, "表达可以更清晰，建议使用更简洁的句子结构")
+		tip("speech.tip.clarity_low")
 	} else if result.ClarityScore > 80 {
-		tips = append(tips, "表达清晰明了，逻辑结构良好")
+		tip("speech.tip.clarity_high")
+	}
+
+	// 赘词建议
+	if result.Disfluencies.Count(CategoryFiller) > 3 {
+		tip("speech.tip.filler")
+	}
+	if result.Disfluencies.Count(CategoryRepetition) > 1 {
+		tip("speech.tip.repetition")
+	}
+	if result.Disfluencies.Count(CategoryIntensifier) > 3 {
+		tip("speech.tip.intensifier")
 	}
 
 	// 信心建议
 	if result.ConfidenceScore < 50 {
-		tips = append(tips, "可以更坚定地表达观点，减少犹豫词的使用")
+		tip("speech.tip.confidence_low")
 	} else if result.ConfidenceScore > 75 {
-		tips = append(tips, "自信的表达，观点阐述很有说服力")
+		tip("speech.tip.confidence_high")
 	}
 
 	return tips
@@ -0,0 +1,20 @@
+package analysis
+
+import (
+	"testing"
+)
+
+// TestSpeechTipsLocale 测试表达建议按语言翻译并使用对应语言的语速标准
+func TestSpeechTipsLocale(t *testing.T) {
+	analyzer := NewSpeechAnalyzer()
+	result := &SpeechResult{WordsPerMinute: 180, RhythmScore: 60, ClarityScore: 70, ConfidenceScore: 60}
+
+	zh := analyzer.GetSpeechTips(result, "zh-CN")
+	if len(zh) != 1 || zh[0] != "语速适中，很好地控制了表达节奏" {
+		t.Fatalf("中文每分钟180字应为语速适中: %v", zh)
+	}
+	en := analyzer.GetSpeechTips(result, "en-US")
+	if len(en) != 1 || en[0] != "You are speaking a little fast; slow down so listeners can absorb each point" {
+		t.Fatalf("英文每分钟180词应为偏快: %v", en)
+	}
+}
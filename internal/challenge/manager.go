@@ -1,10 +1,13 @@
 package challenge
 
 import (
-	"fmt"
+	"sync"
 	"time"
 
 	"reactedge/internal/ai"
+	"reactedge/internal/analysis"
+	"reactedge/internal/i18n"
+	"reactedge/pkg/audio"
 )
 
 // ChallengePhase 挑战阶段
@@ -28,7 +31,10 @@ type ChallengeState struct {
 	CurrentTopic    string            `json:"current_topic"`
 	UserSpeech      string            `json:"user_speech"`
 	ExpressionDNA   *ai.ExpressionDNA `json:"expression_dna,omitempty"`
+	SpeechAnalysis  *analysis.SpeechResult `json:"speech_analysis,omitempty"`
+	Transcript      *audio.Transcript  `json:"transcript,omitempty"` // 录音回答的转写结果
 	PersonalizedTemplate string       `json:"personalized_template"`
+	Template        *ai.PersonalizedTemplate `json:"template,omitempty"` // 分步骤的应答模板
 	TimeRemaining   int               `json:"time_remaining"` // 秒
 }
 
@@ -36,6 +42,8 @@ type ChallengeState struct {
 type ChallengeManager struct {
 	hanAI     *ai.HanStyleAI
 	challenges map[string]*ChallengeState
+	profiles  map[string]*ai.ProfileEvidence // 每个用户历次回答积累的画像证据
+	mutex     sync.Mutex
 }
 
 // NewManager 创建挑战管理器
@@ -43,6 +51,7 @@ func NewManager(hanAI *ai.HanStyleAI) *ChallengeManager {
 	return &ChallengeManager{
 		hanAI:     hanAI,
 		challenges: make(map[string]*ChallengeState),
+		profiles:  make(map[string]*ai.ProfileEvidence),
 	}
 }
 
@@ -57,17 +66,30 @@ func (cm *ChallengeManager) StartChallenge(userID string) *ChallengeState {
 		TimeRemaining:  180, // 3分钟
 	}
 
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	// 沿用之前回答积累的用户画像
+	if evidence := cm.profiles[userID]; evidence != nil {
+		state.UserProfile = evidence.Profile()
+	}
 	cm.challenges[userID] = state
-	return state
+	return state.snapshot()
 }
 
 // GetChallengeState 获取挑战状态
 func (cm *ChallengeManager) GetChallengeState(userID string) *ChallengeState {
-	return cm.challenges[userID]
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	return cm.challenges[userID].snapshot()
 }
 
 // AdvancePhase 推进到下一阶段
 func (cm *ChallengeManager) AdvancePhase(userID string) *ChallengeState {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
 	state := cm.challenges[userID]
 	if state == nil {
 		return nil
@@ -84,10 +106,10 @@ func (cm *ChallengeManager) AdvancePhase(userID string) *ChallengeState {
 		state.CurrentPhase = PhasePersonalizedTemplate
 		// 生成个性化模板
 		if state.UserProfile.PrimaryInterest == "" {
-			// 如果还没有用户画像，使用默认值
-			state.UserProfile = ai.UserProfile{PrimaryInterest: "游戏"}
+			// 如果还没有探测到兴趣，使用默认值
+			state.UserProfile.PrimaryInterest = "游戏"
 		}
-		state.PersonalizedTemplate = cm.hanAI.GeneratePersonalizedTemplate(state.UserProfile, state.CurrentTopic)
+		state.setTemplate(cm.hanAI.ComposeTemplate(state.UserProfile, state.CurrentTopic))
 		state.TimeRemaining = 180 - int(now.Sub(state.StartTime).Seconds())
 	case PhasePersonalizedTemplate:
 		state.CurrentPhase = PhaseRecording
@@ -105,26 +127,67 @@ func (cm *ChallengeManager) AdvancePhase(userID string) *ChallengeState {
 		state.TimeRemaining = 0
 	}
 
-	return state
+	return state.snapshot()
 }
 
 // SubmitSpeech 提交用户语音
 func (cm *ChallengeManager) SubmitSpeech(userID, speech string) *ChallengeState {
+	return cm.SubmitSpeechWithDuration(userID, speech, 0)
+}
+
+// SubmitSpeechWithDuration 提交用户语音及其时长，时长用于计算语速
+func (cm *ChallengeManager) SubmitSpeechWithDuration(userID, speech string, duration time.Duration) *ChallengeState {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
 	state := cm.challenges[userID]
 	if state == nil {
 		return nil
 	}
 
 	state.UserSpeech = speech
+	state.Transcript = nil
+	state.SpeechAnalysis = analysis.NewSpeechAnalyzer().AnalyzeText(speech, duration)
+
+	// 从语音中探测用户画像，与之前回答的证据合并
+	state.UserProfile = cm.observeProfile(userID, cm.hanAI.DetectProfileEvidence(speech))
+
+	return state.snapshot()
+}
+
+// SubmitTranscript 提交录音的转写结果，语速按录音的真实时长计算
+// pcm为解码后的录音，不为空时同时做停顿、语调等声学分析；压缩格式无法解码时传nil
+// 声学分析耗时较长，在加锁前完成，避免阻塞其他用户的挑战
+func (cm *ChallengeManager) SubmitTranscript(userID string, transcript *audio.Transcript, pcm *audio.PCM) *ChallengeState {
+	var speechAnalysis *analysis.SpeechResult
+	if pcm != nil {
+		speechAnalysis = analysis.NewSpeechAnalyzer().AnalyzeRecording(transcript.Text, pcm, transcript.Segments)
+	} else {
+		speechAnalysis = analysis.NewSpeechAnalyzer().AnalyzeText(transcript.Text, transcript.Duration)
+	}
+	evidence := cm.hanAI.DetectProfileEvidence(transcript.Text)
+
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
 
-	// 从语音中探测用户画像
-	state.UserProfile = cm.hanAI.DetectUserProfile(speech)
+	state := cm.challenges[userID]
+	if state == nil {
+		return nil
+	}
 
-	return state
+	state.UserSpeech = transcript.Text
+	state.Transcript = transcript
+	state.SpeechAnalysis = speechAnalysis
+	state.UserProfile = cm.observeProfile(userID, evidence)
+
+	return state.snapshot()
 }
 
 // UpdateProfile 更新用户画像
 func (cm *ChallengeManager) UpdateProfile(userID string, profile ai.UserProfile) *ChallengeState {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
 	state := cm.challenges[userID]
 	if state == nil {
 		return nil
@@ -133,68 +196,138 @@ func (cm *ChallengeManager) UpdateProfile(userID string, profile ai.UserProfile)
 	state.UserProfile = profile
 
 	// 重新生成个性化模板
-	state.PersonalizedTemplate = cm.hanAI.GeneratePersonalizedTemplate(profile, state.CurrentTopic)
+	state.setTemplate(cm.hanAI.ComposeTemplate(profile, state.CurrentTopic))
+
+	return state.snapshot()
+}
+
+// MergeProfileEvidence 合并额外的画像证据（例如大模型的判断），并更新当前挑战的用户画像
+func (cm *ChallengeManager) MergeProfileEvidence(userID string, evidence *ai.ProfileEvidence) *ChallengeState {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	profile := cm.observeProfile(userID, evidence)
+	state := cm.challenges[userID]
+	if state == nil {
+		return nil
+	}
+	state.UserProfile = profile
+	return state.snapshot()
+}
+
+// observeProfile 把证据合并到用户的画像证据中，返回合并后的画像，调用方需持有锁
+func (cm *ChallengeManager) observeProfile(userID string, evidence *ai.ProfileEvidence) ai.UserProfile {
+	merged := cm.profiles[userID]
+	if merged == nil {
+		merged = ai.NewProfileEvidence()
+		cm.profiles[userID] = merged
+	}
+	merged.Merge(evidence)
+	return merged.Profile()
+}
+
+// ApplyTemplate 用大模型生成的模板替换本地模板，挑战已经换了话题或离开模板阶段时忽略
+func (cm *ChallengeManager) ApplyTemplate(userID string, template *ai.PersonalizedTemplate) *ChallengeState {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	state := cm.challenges[userID]
+	if state == nil {
+		return nil
+	}
+	if state.CurrentPhase == PhasePersonalizedTemplate && template.Topic == state.CurrentTopic {
+		state.setTemplate(template)
+	}
+	return state.snapshot()
+}
 
-	return state
+// setTemplate 保存分步骤模板和拼好的模板文本
+func (state *ChallengeState) setTemplate(template *ai.PersonalizedTemplate) {
+	state.Template = template
+	state.PersonalizedTemplate = template.Text()
 }
 
-// GetPhaseContent 获取当前阶段的内容
-func (cm *ChallengeManager) GetPhaseContent(state *ChallengeState) map[string]interface{} {
+// snapshot 复制挑战状态，避免调用方在锁外读写共享数据
+func (state *ChallengeState) snapshot() *ChallengeState {
+	if state == nil {
+		return nil
+	}
+	copied := *state
+	return &copied
+}
+
+// GetPhaseContent 获取当前阶段的内容，标题和说明按locale翻译，话题和生成的模板保持原文
+func (cm *ChallengeManager) GetPhaseContent(state *ChallengeState, locale string) map[string]interface{} {
+	t := func(key string, args ...interface{}) string { return i18n.T(locale, key, args...) }
 	content := map[string]interface{}{
 		"phase": state.CurrentPhase,
 		"time_remaining": state.TimeRemaining,
 		"topic": state.CurrentTopic,
+		"locale": locale,
 	}
 
 	switch state.CurrentPhase {
 	case PhaseWelcome:
-		content["title"] = "🎤 欢迎来到【酷表达实验室】· 韩寒特训版"
-		content["subtitle"] = "🎯 今日挑战：课堂突击提问"
-		content["description"] = fmt.Sprintf("📚 场景：语文课上，老师突然点名：%s\n\n⏰ 要求：15秒思考，45秒回答，要有自己的观点\n\n🔄 AI将全程分析你的\"表达DNA\"", state.CurrentTopic)
+		content["title"] = t("challenge.welcome.title")
+		content["subtitle"] = t("challenge.welcome.subtitle")
+		content["description"] = t("challenge.welcome.description", state.CurrentTopic)
 
 	case PhaseAIDeconstruction:
-		content["title"] = "🧠 AI解构【韩寒表达法】三大武器"
+		content["title"] = t("challenge.deconstruction.title")
 		content["weapons"] = []map[string]interface{}{
 			{
-				"name": "反常规视角 🌪️",
-				"description": "普通人：赞美书店变多 → 文化繁荣\n韩寒式：\"当书店开始比拼装修而不是书目，这和奶茶店比杯子颜值有什么区别？\"",
+				"name": t("challenge.weapon.contrarian.name"),
+				"description": t("challenge.weapon.contrarian.description"),
 			},
 			{
-				"name": "精准文化类比 🎬",
-				"description": "把抽象概念变成具体场景：\n\"这就像电影院里全是爆米花味，但没人在意放的是什么电影\"",
+				"name": t("challenge.weapon.analogy.name"),
+				"description": t("challenge.weapon.analogy.description"),
 			},
 			{
-				"name": "节奏打断技巧 ⚡",
-				"description": "在对方预期处突然转折：\n\"很多人说这是好事...(停顿)但好事有时候是最可怕的陷阱\"",
+				"name": t("challenge.weapon.rhythm.name"),
+				"description": t("challenge.weapon.rhythm.description"),
 			},
 		}
 		content["tools"] = []string{
-			"【反问模板】\"难道...就代表...?\"",
-			"【类比模板】\"这就像...其实不过是...\"",
-			"【转折模板】\"表面上看是...实际上暴露了...\"",
+			t("challenge.tool.question"),
+			t("challenge.tool.analogy"),
+			t("challenge.tool.turn"),
 		}
 
 	case PhasePersonalizedTemplate:
-		content["title"] = "🤖 AI为你生成【个性化应答模板】"
-		content["profile_detection"] = fmt.Sprintf("✅ AI探测到你的偏好：%s", state.UserProfile.PrimaryInterest)
-		content["template_title"] = fmt.Sprintf("✅ 为你生成【%s版】应答模板：", cm.getInterestDisplayName(state.UserProfile.PrimaryInterest))
+		content["title"] = t("challenge.template.title")
+		content["profile_detection"] = t("challenge.template.detected", cm.getInterestLabel(state.UserProfile.PrimaryInterest, locale))
+		if state.UserProfile.Samples > 0 {
+			content["profile_detection"] = t("challenge.template.detected_samples",
+				state.UserProfile.Samples, cm.getInterestLabel(state.UserProfile.PrimaryInterest, locale), state.UserProfile.Confidence*100)
+			content["interests"] = state.UserProfile.Interests
+		}
+		content["template_title"] = t("challenge.template.heading", cm.getInterestDisplayName(state.UserProfile.PrimaryInterest, locale))
 		content["template"] = state.PersonalizedTemplate
+		interest := state.UserProfile.PrimaryInterest
+		if state.Template != nil {
+			interest = state.Template.Interest
+		}
 		content["framework"] = []string{
-			"（1）游戏类比切入 → 吸引同龄人",
-			"（2）对比转折 → 展现思辨",
-			"（3）现象本质 → 提升深度",
-			"（4）犀利反问 → 留下印象",
+			t("challenge.framework.analogy", cm.getInterestLabel(interest, locale)),
+			t("challenge.framework.contrast"),
+			t("challenge.framework.essence"),
+			t("challenge.framework.question"),
+		}
+		if state.Template != nil {
+			content["steps"] = state.Template.Steps
+			content["template_source"] = state.Template.Source
 		}
 
 	case PhaseRecording:
-		content["title"] = "🎤 现在请用你的风格回答！"
-		content["instruction"] = "⏱️ 15秒思考 → 45秒发言"
-		content["tips"] = "（思考时AI显示关键词提示：游戏、质量、虚荣、本质...）"
+		content["title"] = t("challenge.recording.title")
+		content["instruction"] = t("challenge.recording.instruction")
+		content["tips"] = t("challenge.recording.tips")
 		content["topic"] = state.CurrentTopic
 
 	case PhaseDNAAnalysis:
 		if state.ExpressionDNA != nil {
-			content["title"] = "📊 你的【表达DNA分析报告】"
+			content["title"] = t("challenge.dna.title")
 			content["sharpeness_score"] = state.ExpressionDNA.SharpenessScore
 			content["personality_tags"] = state.ExpressionDNA.PersonalityTags
 			content["unique_patterns"] = state.ExpressionDNA.UniquePatterns
@@ -203,10 +336,13 @@ func (cm *ChallengeManager) GetPhaseContent(state *ChallengeState) map[string]in
 			content["recommendations"] = state.ExpressionDNA.Recommendations
 			content["next_challenge"] = state.ExpressionDNA.NextChallenge
 		}
+		if state.SpeechAnalysis != nil {
+			content["speech_tips"] = analysis.NewSpeechAnalyzer().GetSpeechTips(state.SpeechAnalysis, locale)
+		}
 
 	case PhaseComplete:
-		content["title"] = "🎉 挑战完成！"
-		content["message"] = "这不止是一次训练。AI发现了你的独特表达天赋，明天的挑战会围绕这个优势继续设计。"
+		content["title"] = t("challenge.complete.title")
+		content["message"] = t("challenge.complete.message")
 	}
 
 	return content
@@ -225,20 +361,20 @@ func (cm *ChallengeManager) getRandomTopic() string {
 	return topics[time.Now().UnixNano()%int64(len(topics))]
 }
 
-// getInterestDisplayName 获取兴趣显示名称
-func (cm *ChallengeManager) getInterestDisplayName(interest string) string {
-	names := map[string]string{
-		"游戏": "游戏玩家",
-		"动漫": "动漫爱好者",
-		"体育": "体育迷",
-		"科技": "科技达人",
-		"文艺": "文艺青年",
+// getInterestDisplayName 获取兴趣对应的身份称呼
+func (cm *ChallengeManager) getInterestDisplayName(interest, locale string) string {
+	if name, ok := i18n.Default().Lookup(locale, "interest.name."+interest); ok {
+		return name
 	}
+	return i18n.T(locale, "interest.name.default")
+}
 
-	if name, ok := names[interest]; ok {
-		return name
+// getInterestLabel 获取兴趣名称，词表中新增的兴趣没有翻译时显示兴趣本身
+func (cm *ChallengeManager) getInterestLabel(interest, locale string) string {
+	if label, ok := i18n.Default().Lookup(locale, "interest."+interest); ok {
+		return label
 	}
-	return "玩家"
+	return interest
 }
 
 // GetCurrentPhaseDuration 获取当前阶段建议时长（秒）
@@ -0,0 +1,109 @@
+package corpus
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// bm25Index 分块的BM25倒排索引
+type bm25Index struct {
+	postings  map[string]map[int]int // 词 -> 分块序号 -> 词频
+	lengths   []int
+	avgLength float64
+}
+
+// newBM25Index 为分块建立索引
+func newBM25Index(chunks []*Chunk) *bm25Index {
+	index := &bm25Index{postings: make(map[string]map[int]int), lengths: make([]int, len(chunks))}
+	total := 0
+	for i, chunk := range chunks {
+		terms := tokenize(chunk.Title + "\n" + chunk.Text)
+		index.lengths[i] = len(terms)
+		total += len(terms)
+		for _, term := range terms {
+			if index.postings[term] == nil {
+				index.postings[term] = make(map[int]int)
+			}
+			index.postings[term][i]++
+		}
+	}
+	if len(chunks) > 0 {
+		index.avgLength = float64(total) / float64(len(chunks))
+	}
+	return index
+}
+
+// score 计算查询与各分块的BM25分数，filter返回false的分块跳过
+func (index *bm25Index) score(query string, filter func(int) bool) map[int]float64 {
+	scores := make(map[int]float64)
+	n := float64(len(index.lengths))
+	seen := make(map[string]bool)
+	for _, term := range tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		postings := index.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for i, tf := range postings {
+			if !filter(i) {
+				continue
+			}
+			frequency := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(index.lengths[i])/index.avgLength
+			scores[i] += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*norm)
+		}
+	}
+	return scores
+}
+
+// tokenize 中文按相邻两字切分（单字句也保留），英文和数字按词切分并转小写
+func tokenize(text string) []string {
+	var terms []string
+	var han []rune
+	var word []rune
+
+	flushHan := func() {
+		if len(han) == 1 {
+			terms = append(terms, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			terms = append(terms, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return terms
+}
@@ -0,0 +1,118 @@
+package corpus
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 分块大小（按字符计）
+const (
+	chunkSize    = 300 // 段落合并到这个长度就开始新的分块
+	maxChunkSize = 500 // 超过这个长度的段落按句子拆开
+)
+
+var (
+	markdownLink     = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownEmphasis = regexp.MustCompile("[*_`~]{1,3}")
+	markdownPrefix   = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s?|[-*+]\s+|\d+\.\s+)`)
+	sentenceEnd      = regexp.MustCompile(`[^。！？!?；;…]*[。！？!?；;…]+["”’」』)）]*|[^。！？!?；;…]+$`)
+)
+
+// parseDocument 清理文本，Markdown去掉标记并取第一个标题作为标题，返回标题和段落
+func parseDocument(text string, markdown bool) (string, []string) {
+	var title string
+	var paragraphs []string
+	var current []string
+	inCode := false
+
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, ""))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if markdown {
+			if strings.HasPrefix(line, "```") {
+				inCode = !inCode
+				flush()
+				continue
+			}
+			if inCode {
+				continue
+			}
+			if title == "" && strings.HasPrefix(line, "# ") {
+				title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+				continue
+			}
+			// 标题和分隔线单独成段
+			if strings.HasPrefix(line, "#") || line == "---" || line == "***" {
+				flush()
+				continue
+			}
+			line = markdownPrefix.ReplaceAllString(line, "")
+			line = markdownLink.ReplaceAllString(line, "$1")
+			line = markdownEmphasis.ReplaceAllString(line, "")
+			line = strings.TrimSpace(line)
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		// 英文按空格拼接，中文直接拼接
+		if len(current) > 0 && isASCIIWordEnd(current[len(current)-1]) {
+			line = " " + line
+		}
+		current = append(current, line)
+	}
+	flush()
+	return title, paragraphs
+}
+
+// splitChunks 把段落合并成长度适中的分块，过长的段落按句子拆开
+func splitChunks(paragraphs []string) []string {
+	var pieces []string
+	for _, paragraph := range paragraphs {
+		if utf8.RuneCountInString(paragraph) <= maxChunkSize {
+			pieces = append(pieces, paragraph)
+			continue
+		}
+		var current strings.Builder
+		for _, sentence := range sentenceEnd.FindAllString(paragraph, -1) {
+			if current.Len() > 0 && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(sentence) > chunkSize {
+				pieces = append(pieces, current.String())
+				current.Reset()
+			}
+			current.WriteString(sentence)
+		}
+		if current.Len() > 0 {
+			pieces = append(pieces, current.String())
+		}
+	}
+
+	var chunks []string
+	var current []string
+	length := 0
+	for _, piece := range pieces {
+		pieceLength := utf8.RuneCountInString(piece)
+		if length > 0 && length+pieceLength > chunkSize {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current, length = nil, 0
+		}
+		current = append(current, piece)
+		length += pieceLength
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
+
+// isASCIIWordEnd 判断一行是否以英文字母或数字结尾
+func isASCIIWordEnd(line string) bool {
+	r, _ := utf8.DecodeLastRuneInString(line)
+	return r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == ',' || r == '.')
+}
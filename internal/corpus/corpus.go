@@ -0,0 +1,555 @@
+// Package corpus 名人风格的真实讲话语料：导入文本和Markdown讲稿，分块建立BM25索引（可选向量检索），
+// 生成回答时检索最相关的片段放进提示词，并返回引用了哪些片段
+package corpus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"reactedge/pkg/style"
+)
+
+// 默认检索的片段数
+const defaultLimit = 3
+
+// 向量化每批最多的分块数
+const embedBatchSize = 64
+
+// 向量缓存最多保存的分块数，超过后其余分块只用BM25检索
+const maxCachedVectors = 20000
+
+// 向量化一批分块的超时
+const embedTimeout = 2 * time.Minute
+
+// builtinName 内置风格语料的文档名
+const builtinName = "builtin"
+
+var (
+	// ErrNotFound 语料不存在
+	ErrNotFound = errors.New("语料不存在")
+	// ErrInvalid 语料参数无效
+	ErrInvalid = errors.New("语料参数无效")
+)
+
+// slugPattern 风格标识和文档名只允许字母、数字、下划线和连字符，避免写出语料目录
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Document 一篇讲稿
+type Document struct {
+	ID        string    `json:"id"` // 风格标识/文档名
+	Persona   string    `json:"persona"`
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	Source    string    `json:"source"` // 文件路径，内置语料为builtin
+	Chunks    int       `json:"chunks"`
+	Builtin   bool      `json:"builtin,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Chunk 讲稿的一个分块
+type Chunk struct {
+	ID         string `json:"id"` // 文档ID#序号
+	DocumentID string `json:"document_id"`
+	Persona    string `json:"persona"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// Passage 检索到的片段
+type Passage struct {
+	Chunk
+	Score float64 `json:"score"`
+}
+
+// Query 检索条件
+type Query struct {
+	Persona   string // 为空时检索所有风格
+	Text      string // 通常是职场问题
+	Reference string // 用户选择的经典内容，标题或文档名匹配的讲稿优先
+	Limit     int
+	Visible   func(persona string) bool // 不为空时只检索返回true的风格，用于隐藏其他用户的私有风格
+}
+
+// Embedder 文本向量化接口，设置后检索结合向量相似度
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Library 语料库，讲稿按 <目录>/<风格标识>/<文档名>.md|.txt 存放
+type Library struct {
+	dir       string
+	mutex     sync.RWMutex
+	documents []*Document
+	chunks    []*Chunk
+	index     *bm25Index
+	embedder  Embedder
+	vectors   map[string][]float32 // 分块文本 -> 向量，重新加载后只保留仍在使用的分块
+
+	warming bool           // 后台正在补齐分块向量
+	rewarm  bool           // 补齐期间语料有变化，完成后需要再补一次
+	warmed  sync.WaitGroup // 测试中等待后台补齐结束
+}
+
+// NewLibrary 加载语料目录，dir为空时只包含内置风格语料
+func NewLibrary(dir string) (*Library, error) {
+	library := &Library{dir: dir, vectors: make(map[string][]float32)}
+	if err := library.Reload(); err != nil {
+		return nil, err
+	}
+	return library, nil
+}
+
+// SetEmbedder 设置向量化器，为nil时只用BM25检索；设置后在后台为已有分块生成向量
+func (l *Library) SetEmbedder(embedder Embedder) {
+	l.mutex.Lock()
+	l.embedder = embedder
+	l.mutex.Unlock()
+	l.warmVectors()
+}
+
+// Reload 重新加载内置语料和语料目录，单个文件读取失败时跳过
+func (l *Library) Reload() error {
+	var documents []*Document
+	var chunks []*Chunk
+
+	for _, corpus := range style.DefaultCorpora() {
+		document := &Document{
+			ID:      corpus.Persona + "/" + builtinName,
+			Persona: corpus.Persona,
+			Name:    builtinName,
+			Title:   corpus.Name + "风格语料",
+			Source:  builtinName,
+			Builtin: true,
+		}
+		documents = append(documents, document)
+		chunks = append(chunks, buildChunks(document, splitChunks(corpus.Samples))...)
+	}
+
+	if l.dir != "" {
+		personas, err := os.ReadDir(l.dir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("读取语料目录失败: %w", err)
+		}
+		for _, persona := range personas {
+			if !persona.IsDir() || !slugPattern.MatchString(persona.Name()) {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(l.dir, persona.Name()))
+			if err != nil {
+				fmt.Printf("⚠️ 跳过无法读取的语料目录%s: %v\n", persona.Name(), err)
+				continue
+			}
+			for _, file := range files {
+				document, documentChunks, err := l.loadFile(persona.Name(), file)
+				if err != nil {
+					fmt.Printf("⚠️ 跳过无法读取的语料%s/%s: %v\n", persona.Name(), file.Name(), err)
+					continue
+				}
+				if document != nil {
+					documents = append(documents, document)
+					chunks = append(chunks, documentChunks...)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool { return documents[i].ID < documents[j].ID })
+	index := newBM25Index(chunks)
+
+	used := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		used[chunk.Text] = true
+	}
+	l.mutex.Lock()
+	l.documents, l.chunks, l.index = documents, chunks, index
+	for text := range l.vectors {
+		if !used[text] {
+			delete(l.vectors, text)
+		}
+	}
+	l.mutex.Unlock()
+
+	// 新讲稿的向量在后台生成，上传和重新加载不等待向量化
+	l.warmVectors()
+	return nil
+}
+
+// warmVectors 在后台分批为还没有向量的分块生成向量，已有补齐任务时在其结束后再补一次
+func (l *Library) warmVectors() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.embedder == nil {
+		return
+	}
+	if l.warming {
+		l.rewarm = true
+		return
+	}
+	l.warming = true
+	l.warmed.Add(1)
+
+	go func() {
+		defer l.warmed.Done()
+		for {
+			l.mutex.Lock()
+			embedder, chunks := l.embedder, l.chunks
+			l.rewarm = false
+			var missing []string
+			capacity := maxCachedVectors - len(l.vectors)
+			for _, chunk := range chunks {
+				if len(missing) >= capacity {
+					break
+				}
+				if _, ok := l.vectors[chunk.Text]; !ok {
+					missing = append(missing, chunk.Text)
+				}
+			}
+			l.mutex.Unlock()
+
+			if embedder != nil && len(missing) > 0 {
+				if err := l.embed(context.Background(), embedder, missing); err != nil {
+					fmt.Printf("⚠️ 语料向量化失败，检索时再补齐: %v\n", err)
+				}
+			}
+
+			l.mutex.Lock()
+			if !l.rewarm {
+				l.warming = false
+				l.mutex.Unlock()
+				return
+			}
+			l.mutex.Unlock()
+		}
+	}()
+}
+
+// embed 分批向量化文本，每批有单独的超时；缓存未满时保存结果
+func (l *Library) embed(ctx context.Context, embedder Embedder, texts []string) error {
+	sort.Strings(texts)
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		batchCtx, cancel := context.WithTimeout(ctx, embedTimeout)
+		vectors, err := embedder.Embed(batchCtx, texts[start:end])
+		cancel()
+		if err != nil {
+			return err
+		}
+		if len(vectors) != end-start {
+			return fmt.Errorf("向量化返回了%d个向量，需要%d个", len(vectors), end-start)
+		}
+
+		l.mutex.Lock()
+		for i, vector := range vectors {
+			if len(l.vectors) < maxCachedVectors {
+				l.vectors[texts[start+i]] = vector
+			}
+		}
+		l.mutex.Unlock()
+	}
+	return nil
+}
+
+// loadFile 读取一篇讲稿，不是.txt或.md的文件返回nil
+func (l *Library) loadFile(persona string, file os.DirEntry) (*Document, []*Chunk, error) {
+	ext := strings.ToLower(filepath.Ext(file.Name()))
+	name := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+	if file.IsDir() || (ext != ".txt" && ext != ".md" && ext != ".markdown") || !slugPattern.MatchString(name) || name == builtinName {
+		return nil, nil, nil
+	}
+
+	path := filepath.Join(l.dir, persona, file.Name())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Info()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	title, paragraphs := parseDocument(string(data), ext != ".txt")
+	if title == "" {
+		title = name
+	}
+	document := &Document{
+		ID:        persona + "/" + name,
+		Persona:   persona,
+		Name:      name,
+		Title:     title,
+		Source:    path,
+		UpdatedAt: info.ModTime(),
+	}
+	return document, buildChunks(document, splitChunks(paragraphs)), nil
+}
+
+// buildChunks 为文档生成分块
+func buildChunks(document *Document, texts []string) []*Chunk {
+	chunks := make([]*Chunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = &Chunk{
+			ID:         fmt.Sprintf("%s#%d", document.ID, i+1),
+			DocumentID: document.ID,
+			Persona:    document.Persona,
+			Title:      document.Title,
+			Text:       text,
+		}
+	}
+	document.Chunks = len(chunks)
+	return chunks
+}
+
+// Add 保存一篇Markdown或纯文本讲稿并重新建立索引，name为空时自动生成
+func (l *Library) Add(persona, name, title, text string) (*Document, error) {
+	if l.dir == "" {
+		return nil, fmt.Errorf("%w: 没有配置语料目录", ErrInvalid)
+	}
+	if name == "" {
+		name = newName()
+	}
+	if !slugPattern.MatchString(persona) || !slugPattern.MatchString(name) || name == builtinName {
+		return nil, fmt.Errorf("%w: 风格标识和文档名只能包含字母、数字、下划线和连字符", ErrInvalid)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: 讲稿内容不能为空", ErrInvalid)
+	}
+	title = strings.TrimSpace(title)
+	if title != "" && !strings.HasPrefix(text, "# ") {
+		text = "# " + title + "\n\n" + text
+	}
+
+	dir := filepath.Join(l.dir, persona)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建语料目录失败: %w", err)
+	}
+	path := filepath.Join(dir, name+".md")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("保存讲稿失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("保存讲稿失败: %w", err)
+	}
+	// 同名的.txt讲稿被新内容取代
+	os.Remove(filepath.Join(dir, name+".txt"))
+
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l.Get(persona + "/" + name)
+}
+
+// Remove 删除一篇讲稿，内置语料不能删除
+func (l *Library) Remove(id string) error {
+	document, err := l.Get(id)
+	if err != nil {
+		return err
+	}
+	if document.Builtin {
+		return fmt.Errorf("%w: 内置语料不能删除", ErrInvalid)
+	}
+	if err := os.Remove(document.Source); err != nil {
+		return fmt.Errorf("删除讲稿失败: %w", err)
+	}
+	return l.Reload()
+}
+
+// Get 按ID获取文档
+func (l *Library) Get(id string) (*Document, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for _, document := range l.documents {
+		if document.ID == id {
+			copied := *document
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Documents 列出文档，persona为空时列出全部
+func (l *Library) Documents(persona string) []Document {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	documents := []Document{}
+	for _, document := range l.documents {
+		if persona == "" || document.Persona == persona {
+			documents = append(documents, *document)
+		}
+	}
+	return documents
+}
+
+// Search 检索与问题最相关的片段；设置了向量化器时结合向量相似度，向量化失败时退回BM25
+func (l *Library) Search(ctx context.Context, query Query) []Passage {
+	l.mutex.RLock()
+	chunks, index, embedder, documents := l.chunks, l.index, l.embedder, l.documents
+	l.mutex.RUnlock()
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	candidates := make(map[int]bool)
+	for i, chunk := range chunks {
+		if (query.Persona == "" || chunk.Persona == query.Persona) && (query.Visible == nil || query.Visible(chunk.Persona)) {
+			candidates[i] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return []Passage{}
+	}
+
+	scores := index.score(query.Text+"\n"+query.Reference, func(i int) bool { return candidates[i] })
+	maxScore := 0.0
+	for _, score := range scores {
+		maxScore = math.Max(maxScore, score)
+	}
+	if maxScore > 0 {
+		for i := range scores {
+			scores[i] /= maxScore
+		}
+	}
+
+	if embedder != nil {
+		if similarities, err := l.similarities(ctx, embedder, chunks, candidates, query.Text); err != nil {
+			fmt.Printf("⚠️ 语料向量检索失败，只使用BM25: %v\n", err)
+		} else {
+			for i, similarity := range similarities {
+				scores[i] = (scores[i] + math.Max(similarity, 0)) / 2
+			}
+		}
+	}
+
+	// 用户选择的经典内容对应的讲稿优先，即使和问题没有共同的词
+	if referenced := referencedDocuments(documents, query.Reference); len(referenced) > 0 {
+		for i := range candidates {
+			if referenced[chunks[i].DocumentID] {
+				scores[i] += 0.5
+			}
+		}
+	}
+
+	passages := make([]Passage, 0, len(scores))
+	for i, score := range scores {
+		if score > 0 {
+			passages = append(passages, Passage{Chunk: *chunks[i], Score: math.Round(score*1000) / 1000})
+		}
+	}
+	sort.SliceStable(passages, func(i, j int) bool {
+		if passages[i].Score != passages[j].Score {
+			return passages[i].Score > passages[j].Score
+		}
+		return passages[i].ID < passages[j].ID
+	})
+	if len(passages) > limit {
+		passages = passages[:limit]
+	}
+	return passages
+}
+
+// similarities 计算查询与已有向量的候选分块的余弦相似度；检索时不向量化分块，
+// 还没有向量的分块只用BM25打分，缓存未满时交给后台补齐
+func (l *Library) similarities(ctx context.Context, embedder Embedder, chunks []*Chunk, candidates map[int]bool, text string) (map[int]float64, error) {
+	vectors := make(map[int][]float32, len(candidates))
+	missing := false
+	l.mutex.RLock()
+	for i := range candidates {
+		if vector, ok := l.vectors[chunks[i].Text]; ok {
+			vectors[i] = vector
+		} else {
+			missing = true
+		}
+	}
+	full := len(l.vectors) >= maxCachedVectors
+	l.mutex.RUnlock()
+
+	if missing && !full {
+		l.warmVectors()
+	}
+	if len(vectors) == 0 {
+		return nil, nil
+	}
+
+	queryVectors, err := embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVectors) != 1 {
+		return nil, fmt.Errorf("查询向量化返回了%d个向量", len(queryVectors))
+	}
+
+	similarities := make(map[int]float64, len(vectors))
+	for i, vector := range vectors {
+		similarities[i] = cosine(queryVectors[0], vector)
+	}
+	return similarities, nil
+}
+
+// referencedDocuments 找出标题或文档名与用户选择的经典内容匹配的文档
+func referencedDocuments(documents []*Document, reference string) map[string]bool {
+	reference = normalizeTitle(reference)
+	if reference == "" {
+		return nil
+	}
+	referenced := make(map[string]bool)
+	for _, document := range documents {
+		if document.Builtin {
+			continue
+		}
+		title := normalizeTitle(document.Title)
+		if strings.EqualFold(document.Name, reference) ||
+			(title != "" && (strings.Contains(title, reference) || strings.Contains(reference, title))) {
+			referenced[document.ID] = true
+		}
+	}
+	return referenced
+}
+
+// normalizeTitle 去掉书名号、括号内容和空白，方便比较标题
+func normalizeTitle(title string) string {
+	if i := strings.IndexAny(title, "（("); i > 0 {
+		title = title[:i]
+	}
+	return strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+		return r == '《' || r == '》' || r == ' ' || r == '　'
+	}), "")
+}
+
+// cosine 计算两个向量的余弦相似度
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// newName 生成随机文档名
+func newName() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic("生成随机数失败: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
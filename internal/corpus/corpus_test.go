@@ -0,0 +1,144 @@
+package corpus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEmbedder 按是否包含"故事"生成二维向量，记录调用次数和向量化的文本数
+type fakeEmbedder struct {
+	mutex sync.Mutex
+	calls int
+	texts int
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mutex.Lock()
+	e.calls++
+	e.texts += len(texts)
+	e.mutex.Unlock()
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "故事") {
+			vectors[i] = []float32{1, 0}
+		} else {
+			vectors[i] = []float32{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+// TestLibrary 测试讲稿导入、Markdown清理、分块、BM25检索、经典内容优先和向量检索
+func TestLibrary(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "dongqing"), 0755)
+	os.WriteFile(filepath.Join(dir, "dongqing", "readers.txt"), []byte("朗读是把文字变成声音。\n\n每一个平凡的人都有值得被听见的故事。"), 0644)
+	os.WriteFile(filepath.Join(dir, "dongqing", "notes.json"), []byte("{}"), 0644)
+
+	library, err := NewLibrary(dir)
+	if err != nil {
+		t.Fatalf("加载语料失败: %v", err)
+	}
+	if documents := library.Documents("dongqing"); len(documents) != 2 || documents[1].Title != "readers" {
+		t.Fatalf("应包含内置语料和readers.txt: %+v", documents)
+	}
+
+	markdown := "# 诗词大会总决赛\n\n> **诗词**让我们在平凡的生活里看到[远方](http://example.com)。\n\n```\n忽略的代码\n```\n\n- 每一句诗，都是古人留给我们的一封信。\n"
+	document, err := library.Add("dongqing", "poetry", "", markdown)
+	if err != nil {
+		t.Fatalf("保存讲稿失败: %v", err)
+	}
+	if document.Title != "诗词大会总决赛" || document.Chunks != 1 {
+		t.Errorf("Markdown标题或分块不正确: %+v", document)
+	}
+
+	passages := library.Search(context.Background(), Query{Persona: "dongqing", Text: "怎么让团队爱上诗词", Limit: 2})
+	if len(passages) == 0 || passages[0].DocumentID != "dongqing/poetry" {
+		t.Fatalf("应检索到诗词讲稿: %+v", passages)
+	}
+	if text := passages[0].Text; strings.Contains(text, "**") || strings.Contains(text, "http") || strings.Contains(text, "忽略") || !strings.Contains(text, "看到远方") {
+		t.Errorf("Markdown标记没有清理干净: %q", text)
+	}
+
+	// 用户选择的经典内容即使与问题没有共同的词也会被检索到
+	passages = library.Search(context.Background(), Query{Persona: "dongqing", Text: "项目延期怎么汇报", Reference: "readers"})
+	if len(passages) == 0 || passages[0].DocumentID != "dongqing/readers" {
+		t.Errorf("选择的经典内容应优先: %+v", passages)
+	}
+	if passages := library.Search(context.Background(), Query{Persona: "nobody", Text: "诗词"}); len(passages) != 0 {
+		t.Errorf("未知风格不应有结果: %+v", passages)
+	}
+	visible := func(persona string) bool { return persona != "dongqing" }
+	for _, passage := range library.Search(context.Background(), Query{Text: "诗词", Visible: visible}) {
+		if passage.Persona == "dongqing" {
+			t.Errorf("不可见风格的讲稿不应被检索到: %+v", passage)
+		}
+	}
+
+	embedder := &fakeEmbedder{}
+	library.SetEmbedder(embedder)
+	library.warmed.Wait()
+	if embedder.calls != 1 || len(library.vectors) != len(library.chunks) {
+		t.Errorf("设置向量化器后应在后台为全部分块生成向量: calls=%d vectors=%d", embedder.calls, len(library.vectors))
+	}
+	passages = library.Search(context.Background(), Query{Persona: "dongqing", Text: "故事", Limit: 1})
+	if len(passages) != 1 || passages[0].Score <= 0 || embedder.calls != 2 {
+		t.Errorf("向量检索结果不正确: %+v calls=%d", passages, embedder.calls)
+	}
+	library.Search(context.Background(), Query{Persona: "dongqing", Text: "故事"})
+	if embedder.calls != 3 {
+		t.Errorf("分块向量应被缓存，只需向量化查询: %d", embedder.calls)
+	}
+
+	// 没有向量的分块在检索时只用BM25打分，由后台补齐
+	library.mutex.Lock()
+	library.vectors = make(map[string][]float32)
+	library.mutex.Unlock()
+	before := embedder.texts
+	if passages := library.Search(context.Background(), Query{Persona: "dongqing", Text: "故事"}); len(passages) == 0 {
+		t.Error("没有向量时应按BM25返回结果")
+	}
+	library.warmed.Wait()
+	if embedded := embedder.texts - before; embedded != len(library.chunks) {
+		t.Errorf("检索时不应向量化分块，分块由后台补齐一次: %d", embedded)
+	}
+	if len(library.vectors) != len(library.chunks) {
+		t.Errorf("后台应补齐全部分块向量: vectors=%d chunks=%d", len(library.vectors), len(library.chunks))
+	}
+
+	if _, err := library.Add("../etc", "x", "", "内容"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("非法的风格标识应被拒绝: %v", err)
+	}
+	if err := library.Remove("dongqing/builtin"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("内置语料不能删除: %v", err)
+	}
+	if err := library.Remove("dongqing/poetry"); err != nil {
+		t.Fatalf("删除讲稿失败: %v", err)
+	}
+	if _, err := library.Get("dongqing/poetry"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除后不应再能找到: %v", err)
+	}
+	library.warmed.Wait()
+	if len(library.vectors) != len(library.chunks) {
+		t.Errorf("删除讲稿后应清理不再使用的向量: vectors=%d chunks=%d", len(library.vectors), len(library.chunks))
+	}
+}
+
+// TestSplitChunks 测试长段落按句子拆分，短段落合并
+func TestSplitChunks(t *testing.T) {
+	long := strings.Repeat("这是一句用来测试分块的话。", 60)
+	chunks := splitChunks([]string{"短段落一。", "短段落二。", long})
+	if len(chunks) < 3 || chunks[0] != "短段落一。\n短段落二。" {
+		t.Fatalf("分块不正确: %d %q", len(chunks), chunks[0])
+	}
+	for _, chunk := range chunks {
+		if n := len([]rune(chunk)); n > maxChunkSize {
+			t.Errorf("分块过长: %d", n)
+		}
+	}
+}
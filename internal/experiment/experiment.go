@@ -0,0 +1,135 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"time"
+)
+
+// 错误定义
+var (
+	ErrNotFound = errors.New("实验不存在")
+	ErrConflict = errors.New("实验冲突")
+)
+
+// idPattern 实验ID和变体名只允许字母、数字、下划线和短横线
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Variant 实验变体
+type Variant struct {
+	Name     string `json:"name"`
+	Weight   int    `json:"weight"`             // 分流权重，0按1处理
+	Template string `json:"template,omitempty"` // 提示词模板名，空表示使用目标的默认模板
+	Model    string `json:"model,omitempty"`    // 模型名，空表示使用客户端默认模型
+}
+
+// Experiment 一个提示词/模型对比实验
+type Experiment struct {
+	ID           string     `json:"id"`
+	Description  string     `json:"description,omitempty"`
+	Target       string     `json:"target"` // 实验作用的提示词模板，如persona_answer
+	Variants     []Variant  `json:"variants"`
+	AutoEvaluate bool       `json:"auto_evaluate"` // 是否用EvaluateReaction为每个回答自动评分
+	Active       bool       `json:"active"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StoppedAt    *time.Time `json:"stopped_at,omitempty"`
+}
+
+// Assignment 用户被分配到的变体
+type Assignment struct {
+	ExperimentID string `json:"experiment_id"`
+	Variant      string `json:"variant"`
+	Template     string `json:"template,omitempty"`
+	Model        string `json:"model,omitempty"`
+	AutoEvaluate bool   `json:"-"`
+}
+
+// Validate 校验实验定义
+func (e *Experiment) Validate() error {
+	if !idPattern.MatchString(e.ID) {
+		return fmt.Errorf("实验ID只能包含字母、数字、下划线和短横线: %q", e.ID)
+	}
+	if e.Target == "" {
+		return fmt.Errorf("实验%s缺少target", e.ID)
+	}
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("实验%s至少需要两个变体", e.ID)
+	}
+
+	seen := make(map[string]bool)
+	for _, variant := range e.Variants {
+		if !idPattern.MatchString(variant.Name) {
+			return fmt.Errorf("变体名只能包含字母、数字、下划线和短横线: %q", variant.Name)
+		}
+		if seen[variant.Name] {
+			return fmt.Errorf("变体名重复: %s", variant.Name)
+		}
+		if variant.Weight < 0 {
+			return fmt.Errorf("变体%s的权重不能为负数", variant.Name)
+		}
+		seen[variant.Name] = true
+	}
+	return nil
+}
+
+// Assign 按用户ID稳定分桶：同一用户在变体不变时总是得到同一变体
+func (e *Experiment) Assign(userID string) *Assignment {
+	total := 0
+	for _, variant := range e.Variants {
+		total += weight(variant)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(e.ID + ":" + userID))
+	bucket := int(hash.Sum32() % uint32(total))
+
+	for _, variant := range e.Variants {
+		bucket -= weight(variant)
+		if bucket < 0 {
+			return &Assignment{
+				ExperimentID: e.ID,
+				Variant:      variant.Name,
+				Template:     variant.Template,
+				Model:        variant.Model,
+				AutoEvaluate: e.AutoEvaluate,
+			}
+		}
+	}
+	return nil
+}
+
+// sameBuckets 判断两组变体的名称、顺序和权重是否相同，相同时用户的分桶结果不变
+func sameBuckets(a, b []Variant) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || weight(a[i]) != weight(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasVariant 判断变体是否存在
+func (e *Experiment) HasVariant(name string) bool {
+	for _, variant := range e.Variants {
+		if variant.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// weight 变体的有效权重
+func weight(variant Variant) int {
+	if variant.Weight == 0 {
+		return 1
+	}
+	return variant.Weight
+}
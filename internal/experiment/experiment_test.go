@@ -0,0 +1,98 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestAssignAndReport 测试稳定分桶、事件持久化和对比报告
+func TestAssignAndReport(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(filepath.Join(dir, "experiments.json"), filepath.Join(dir, "events.jsonl"))
+	if err != nil {
+		t.Fatalf("创建实验管理器失败: %v", err)
+	}
+
+	_, err = manager.Save(&Experiment{
+		ID:     "concise",
+		Target: "persona_answer",
+		Active: true,
+		Variants: []Variant{
+			{Name: "control", Weight: 1},
+			{Name: "short", Weight: 3, Template: "persona_answer.concise"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("保存实验失败: %v", err)
+	}
+	_, err = manager.Save(&Experiment{ID: "other", Target: "persona_answer", Active: true, Variants: []Variant{{Name: "a"}, {Name: "b"}}})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("同一目标的第二个进行中实验应冲突，实际: %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		first := manager.Assign("persona_answer", userID)
+		if again := manager.Assign("persona_answer", userID); again.Variant != first.Variant {
+			t.Fatalf("同一用户分配结果不稳定: %s vs %s", first.Variant, again.Variant)
+		}
+		counts[first.Variant]++
+	}
+	if share := float64(counts["short"]) / 2000; share < 0.7 || share > 0.8 {
+		t.Errorf("权重3:1时short占比应接近75%%，实际: %.2f", share)
+	}
+
+	// 进行中的实验修改权重会把用户换到其他变体，应被拒绝；只改模板不影响分桶
+	before := manager.Assign("persona_answer", "user-7")
+	_, err = manager.Save(&Experiment{ID: "concise", Target: "persona_answer", Active: true, Variants: []Variant{{Name: "control", Weight: 3}, {Name: "short", Weight: 1}}})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("进行中的实验修改权重应冲突，实际: %v", err)
+	}
+	_, err = manager.Save(&Experiment{ID: "concise", Target: "persona_answer", Active: true, Variants: []Variant{{Name: "control", Weight: 1}, {Name: "short", Weight: 3, Template: "persona_answer"}}})
+	if err != nil {
+		t.Fatalf("修改模板应成功: %v", err)
+	}
+	if after := manager.Assign("persona_answer", "user-7"); after.Variant != before.Variant {
+		t.Errorf("更新实验后用户的变体不应改变: %s -> %s", before.Variant, after.Variant)
+	}
+
+	events := []Event{
+		{Variant: "control", UserID: "u1", AttemptID: "a1", Kind: EventExposure},
+		{Variant: "control", UserID: "u1", AttemptID: "a1", Kind: EventVote, Value: 1},
+		{Variant: "control", UserID: "u1", AttemptID: "a1", Kind: EventVote, Value: -1}, // 改票
+		{Variant: "control", UserID: "u1", AttemptID: "a1", Kind: EventScore, Value: 6},
+		{Variant: "short", UserID: "u2", AttemptID: "a2", Kind: EventExposure},
+		{Variant: "short", UserID: "u2", AttemptID: "a2", Kind: EventVote, Value: 1},
+		{Variant: "short", UserID: "u2", AttemptID: "a2", Kind: EventScore, Value: 8},
+		{Variant: "short", UserID: "u3", AttemptID: "a3", Kind: EventScore, Value: 7},
+	}
+	for _, event := range events {
+		event.ExperimentID = "concise"
+		if err := manager.Record(event); err != nil {
+			t.Fatalf("记录事件失败: %v", err)
+		}
+	}
+
+	reloaded, err := NewManager(filepath.Join(dir, "experiments.json"), filepath.Join(dir, "events.jsonl"))
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	report, err := reloaded.Report("concise")
+	if err != nil {
+		t.Fatalf("生成报告失败: %v", err)
+	}
+
+	control, short := report.Variants[0], report.Variants[1]
+	if control.Up != 0 || control.Down != 1 || control.MeanScore != 6 {
+		t.Errorf("对照组统计不正确: %+v", control)
+	}
+	if short.Up != 1 || short.Scores != 2 || short.MeanScore != 7.5 || short.StdErr != 0.5 {
+		t.Errorf("变体统计不正确: %+v", short)
+	}
+	if report.ScoreLift["short"] != 1.5 || report.UpRateLift["short"] != 1 {
+		t.Errorf("相对对照组的差值不正确: %v %v", report.ScoreLift, report.UpRateLift)
+	}
+}
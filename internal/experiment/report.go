@@ -0,0 +1,130 @@
+package experiment
+
+import (
+	"math"
+	"strconv"
+)
+
+// VariantReport 单个变体的统计
+type VariantReport struct {
+	Name      string  `json:"name"`
+	Template  string  `json:"template,omitempty"`
+	Model     string  `json:"model,omitempty"`
+	Exposures int     `json:"exposures"`
+	Users     int     `json:"users"`
+	Up        int     `json:"up"`
+	Down      int     `json:"down"`
+	UpRate    float64 `json:"up_rate"` // 点赞占投票的比例
+	Scores    int     `json:"scores"`
+	MeanScore float64 `json:"mean_score"`
+	StdErr    float64 `json:"std_err"` // 评分均值的标准误
+}
+
+// Report 实验结果对比，第一个变体视为对照组
+type Report struct {
+	Experiment *Experiment     `json:"experiment"`
+	Variants   []VariantReport `json:"variants"`
+	// 相对对照组的差值，键为变体名
+	ScoreLift  map[string]float64 `json:"score_lift"`
+	UpRateLift map[string]float64 `json:"up_rate_lift"`
+}
+
+// variantStats 统计过程中的累加值
+type variantStats struct {
+	users  map[string]bool
+	votes  map[string]float64 // 同一用户对同一回答只保留最后一次投票
+	sum    float64
+	sumSq  float64
+	report VariantReport
+}
+
+// Report 生成实验的对比报告
+func (m *Manager) Report(id string) (*Report, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	experiment, ok := m.experiments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	stats := make(map[string]*variantStats, len(experiment.Variants))
+	for _, variant := range experiment.Variants {
+		stats[variant.Name] = &variantStats{
+			users: make(map[string]bool),
+			votes: make(map[string]float64),
+			report: VariantReport{
+				Name:     variant.Name,
+				Template: variant.Template,
+				Model:    variant.Model,
+			},
+		}
+	}
+
+	for i, event := range m.events {
+		if event.ExperimentID != id {
+			continue
+		}
+		item, ok := stats[event.Variant]
+		if !ok {
+			continue
+		}
+		switch event.Kind {
+		case EventExposure:
+			item.report.Exposures++
+			item.users[event.UserID] = true
+		case EventVote:
+			key := event.UserID + "/" + event.AttemptID
+			if event.AttemptID == "" {
+				key = "#" + strconv.Itoa(i)
+			}
+			item.votes[key] = event.Value
+		case EventScore:
+			item.report.Scores++
+			item.sum += event.Value
+			item.sumSq += event.Value * event.Value
+		}
+	}
+
+	copied := *experiment
+	report := &Report{
+		Experiment: &copied,
+		ScoreLift:  make(map[string]float64),
+		UpRateLift: make(map[string]float64),
+	}
+	for _, variant := range experiment.Variants {
+		item := stats[variant.Name]
+		item.report.Users = len(item.users)
+		for _, vote := range item.votes {
+			if vote > 0 {
+				item.report.Up++
+			} else if vote < 0 {
+				item.report.Down++
+			}
+		}
+		if total := item.report.Up + item.report.Down; total > 0 {
+			item.report.UpRate = round(float64(item.report.Up) / float64(total))
+		}
+		if n := float64(item.report.Scores); n > 0 {
+			mean := item.sum / n
+			item.report.MeanScore = round(mean)
+			if n > 1 {
+				variance := (item.sumSq - n*mean*mean) / (n - 1)
+				item.report.StdErr = round(math.Sqrt(math.Max(variance, 0) / n))
+			}
+		}
+		report.Variants = append(report.Variants, item.report)
+	}
+
+	control := report.Variants[0]
+	for _, variant := range report.Variants[1:] {
+		report.ScoreLift[variant.Name] = round(variant.MeanScore - control.MeanScore)
+		report.UpRateLift[variant.Name] = round(variant.UpRate - control.UpRate)
+	}
+	return report, nil
+}
+
+// round 保留三位小数
+func round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
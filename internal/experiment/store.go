@@ -0,0 +1,267 @@
+package experiment
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventKind 实验事件类型
+type EventKind string
+
+const (
+	EventExposure EventKind = "exposure" // 用户看到了某个变体生成的回答
+	EventVote     EventKind = "vote"     // 用户点赞(1)或点踩(-1)
+	EventScore    EventKind = "score"    // EvaluateReaction给出的综合评分
+)
+
+// Event 实验事件
+type Event struct {
+	ExperimentID string    `json:"experiment_id"`
+	Variant      string    `json:"variant"`
+	UserID       string    `json:"user_id"`
+	AttemptID    string    `json:"attempt_id,omitempty"`
+	Kind         EventKind `json:"kind"`
+	Value        float64   `json:"value,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Manager 实验管理，实验定义保存在JSON文件中，事件追加到JSONL文件
+type Manager struct {
+	path        string
+	eventsPath  string
+	experiments map[string]*Experiment
+	events      []Event
+	mutex       sync.RWMutex
+}
+
+// NewManager 创建实验管理器，路径为空时仅保存在内存中
+func NewManager(path, eventsPath string) (*Manager, error) {
+	manager := &Manager{
+		path:        path,
+		eventsPath:  eventsPath,
+		experiments: make(map[string]*Experiment),
+	}
+
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取实验数据失败: %w", err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(raw, &manager.experiments); err != nil {
+				return nil, fmt.Errorf("解析实验数据失败: %w", err)
+			}
+		}
+	}
+
+	if eventsPath != "" {
+		if err := manager.loadEvents(); err != nil {
+			return nil, err
+		}
+	}
+	return manager, nil
+}
+
+// loadEvents 加载事件文件，跳过损坏的行
+func (m *Manager) loadEvents() error {
+	file, err := os.Open(m.eventsPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开实验事件失败: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			fmt.Printf("⚠️ 跳过第%d行损坏的实验事件: %v\n", line, err)
+			continue
+		}
+		m.events = append(m.events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取实验事件失败: %w", err)
+	}
+	return nil
+}
+
+// Save 创建或更新实验；启用时同一目标不能有其他进行中的实验，
+// 进行中的实验不能修改变体和权重，否则已分配的用户会被换到其他变体
+func (m *Manager) Save(experiment *Experiment) (*Experiment, error) {
+	if err := experiment.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if experiment.Active {
+		for _, other := range m.experiments {
+			if other.ID != experiment.ID && other.Active && other.Target == experiment.Target {
+				return nil, fmt.Errorf("%w: 同一目标已有进行中的实验%s", ErrConflict, other.ID)
+			}
+		}
+	}
+	if existing, ok := m.experiments[experiment.ID]; ok && existing.Active && !sameBuckets(existing.Variants, experiment.Variants) {
+		return nil, fmt.Errorf("%w: 进行中的实验不能修改变体和权重，请先停止实验", ErrConflict)
+	}
+
+	saved := *experiment
+	saved.Variants = append([]Variant(nil), experiment.Variants...)
+	if existing, ok := m.experiments[experiment.ID]; ok {
+		saved.CreatedAt = existing.CreatedAt
+	} else {
+		saved.CreatedAt = time.Now()
+	}
+	saved.StoppedAt = nil
+	if !saved.Active {
+		now := time.Now()
+		saved.StoppedAt = &now
+	}
+
+	m.experiments[saved.ID] = &saved
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+	copied := saved
+	return &copied, nil
+}
+
+// Stop 停止实验，已收集的数据保留
+func (m *Manager) Stop(id string) (*Experiment, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	experiment, ok := m.experiments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if experiment.Active {
+		now := time.Now()
+		experiment.Active = false
+		experiment.StoppedAt = &now
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+	}
+	copied := *experiment
+	return &copied, nil
+}
+
+// Get 获取实验
+func (m *Manager) Get(id string) (*Experiment, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	experiment, ok := m.experiments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *experiment
+	return &copied, nil
+}
+
+// List 按创建时间列出所有实验
+func (m *Manager) List() []*Experiment {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	list := make([]*Experiment, 0, len(m.experiments))
+	for _, experiment := range m.experiments {
+		copied := *experiment
+		list = append(list, &copied)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Assign 为用户分配目标模板上进行中实验的变体，没有实验时返回nil
+func (m *Manager) Assign(target, userID string) *Assignment {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, experiment := range m.experiments {
+		if experiment.Active && experiment.Target == target {
+			return experiment.Assign(userID)
+		}
+	}
+	return nil
+}
+
+// Record 记录实验事件
+func (m *Manager) Record(event Event) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	experiment, ok := m.experiments[event.ExperimentID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !experiment.HasVariant(event.Variant) {
+		return fmt.Errorf("实验%s没有变体%s", event.ExperimentID, event.Variant)
+	}
+
+	if m.eventsPath != "" {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("序列化实验事件失败: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(m.eventsPath), 0o755); err != nil {
+			return fmt.Errorf("创建数据目录失败: %w", err)
+		}
+		file, err := os.OpenFile(m.eventsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("打开实验事件失败: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(raw, '\n')); err != nil {
+			return fmt.Errorf("写入实验事件失败: %w", err)
+		}
+	}
+
+	m.events = append(m.events, event)
+	return nil
+}
+
+// save 写入实验定义，调用方需持有写锁
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(m.experiments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化实验数据失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致数据损坏
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("写入实验数据失败: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return fmt.Errorf("保存实验数据失败: %w", err)
+	}
+	return nil
+}
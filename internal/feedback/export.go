@@ -0,0 +1,74 @@
+package feedback
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader CSV导出的列
+var csvHeader = []string{
+	"id", "attempt_id", "user_id", "created_at", "rating", "tags", "comment",
+	"persona", "prompt", "experiment", "variant", "question", "answer",
+}
+
+// WriteCSV 以CSV格式导出反馈，多个标签用"|"分隔
+func WriteCSV(w io.Writer, items []*Feedback) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("写入CSV失败: %w", err)
+	}
+
+	for _, item := range items {
+		tags := make([]string, len(item.Tags))
+		for i, tag := range item.Tags {
+			tags[i] = string(tag)
+		}
+		rating := ""
+		if item.Rating > 0 {
+			rating = strconv.Itoa(item.Rating)
+		}
+
+		record := []string{
+			item.ID, item.AttemptID, item.UserID, item.CreatedAt.Format(time.RFC3339),
+			rating, strings.Join(tags, "|"), item.Comment,
+			item.Persona, item.Prompt, item.Experiment, item.Variant, item.Question, item.Answer,
+		}
+		for i := range record {
+			record[i] = escapeCell(record[i])
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("写入CSV失败: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("写入CSV失败: %w", err)
+	}
+	return nil
+}
+
+// escapeCell 用户填写的内容以=、+、-、@、制表符或回车开头时加上单引号，避免在表格软件中被当作公式执行
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// WriteJSONL 以JSONL格式导出反馈，每行一条
+func WriteJSONL(w io.Writer, items []*Feedback) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return fmt.Errorf("写入JSONL失败: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package feedback
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Tag 反馈标签
+type Tag string
+
+const (
+	TagGreat          Tag = "great"           // 风格很到位
+	TagOffStyle       Tag = "off-style"       // 不像该名人的说话方式
+	TagTooLong        Tag = "too-long"        // 太长
+	TagTooShort       Tag = "too-short"       // 太短
+	TagFactuallyWrong Tag = "factually-wrong" // 事实错误
+	TagIrrelevant     Tag = "irrelevant"      // 答非所问
+)
+
+// Tags 返回所有可用标签
+func Tags() []Tag {
+	return []Tag{TagGreat, TagOffStyle, TagTooLong, TagTooShort, TagFactuallyWrong, TagIrrelevant}
+}
+
+// maxCommentLength 评论的最大字数
+const maxCommentLength = 2000
+
+// ErrInvalid 反馈内容无效
+var ErrInvalid = errors.New("反馈内容无效")
+
+// Feedback 用户对一条生成回答的反馈
+// 同时保存回答的风格、提示词和实验信息，导出后无需再关联训练历史
+type Feedback struct {
+	ID         string    `json:"id"`
+	AttemptID  string    `json:"attempt_id"`
+	UserID     string    `json:"user_id"`
+	Rating     int       `json:"rating,omitempty"` // 1-5分，0表示未评分
+	Comment    string    `json:"comment,omitempty"`
+	Tags       []Tag     `json:"tags,omitempty"`
+	Persona    string    `json:"persona,omitempty"`
+	Question   string    `json:"question,omitempty"`
+	Answer     string    `json:"answer,omitempty"`
+	Prompt     string    `json:"prompt,omitempty"`
+	Experiment string    `json:"experiment,omitempty"`
+	Variant    string    `json:"variant,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Validate 校验并规范化反馈：评分1-5、标签去重、评论去除首尾空白
+func (f *Feedback) Validate() error {
+	if f.AttemptID == "" {
+		return fmt.Errorf("%w: 缺少attempt_id", ErrInvalid)
+	}
+	if f.Rating < 0 || f.Rating > 5 {
+		return fmt.Errorf("%w: 评分必须在1-5之间", ErrInvalid)
+	}
+
+	f.Comment = strings.TrimSpace(f.Comment)
+	if utf8.RuneCountInString(f.Comment) > maxCommentLength {
+		return fmt.Errorf("%w: 评论不能超过%d字", ErrInvalid, maxCommentLength)
+	}
+
+	valid := make(map[Tag]bool)
+	for _, tag := range Tags() {
+		valid[tag] = true
+	}
+	seen := make(map[Tag]bool)
+	tags := f.Tags[:0]
+	for _, tag := range f.Tags {
+		if !valid[tag] {
+			return fmt.Errorf("%w: 未知的标签%q", ErrInvalid, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	f.Tags = tags
+
+	if f.Rating == 0 && f.Comment == "" && len(f.Tags) == 0 {
+		return fmt.Errorf("%w: 评分、评论和标签至少填写一项", ErrInvalid)
+	}
+	return nil
+}
+
+// HasTag 判断是否包含标签
+func (f *Feedback) HasTag(tag Tag) bool {
+	for _, item := range f.Tags {
+		if item == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Query 反馈查询条件，零值字段表示不限制
+type Query struct {
+	UserID    string
+	AttemptID string
+	Persona   string
+	Tag       Tag
+	MinRating int
+	MaxRating int
+	Since     time.Time
+	Until     time.Time
+}
+
+// Match 判断反馈是否满足查询条件
+func (q Query) Match(f *Feedback) bool {
+	if q.UserID != "" && f.UserID != q.UserID {
+		return false
+	}
+	if q.AttemptID != "" && f.AttemptID != q.AttemptID {
+		return false
+	}
+	if q.Persona != "" && f.Persona != q.Persona {
+		return false
+	}
+	if q.Tag != "" && !f.HasTag(q.Tag) {
+		return false
+	}
+	if q.MinRating > 0 && f.Rating < q.MinRating {
+		return false
+	}
+	if q.MaxRating > 0 && (f.Rating == 0 || f.Rating > q.MaxRating) {
+		return false
+	}
+	if !q.Since.IsZero() && f.CreatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !f.CreatedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Store 基于JSONL文件的反馈存储
+// 同一用户对同一回答多次反馈时，查询只返回最新的一条
+type Store struct {
+	path   string
+	items  []*Feedback
+	latest map[string]int // 用户+回答 -> items中最新反馈的下标
+	mutex  sync.RWMutex
+}
+
+// NewStore 创建反馈存储，path为空时仅保存在内存中
+func NewStore(path string) (*Store, error) {
+	store := &Store{path: path, latest: make(map[string]int)}
+	if path == "" {
+		return store, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开反馈数据失败: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item Feedback
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			fmt.Printf("⚠️ 跳过第%d行损坏的反馈: %v\n", line, err)
+			continue
+		}
+		store.append(&item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取反馈数据失败: %w", err)
+	}
+	return store, nil
+}
+
+// Record 校验并保存一条反馈
+func (s *Store) Record(item *Feedback) error {
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	if item.ID == "" {
+		item.ID = newID()
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.path != "" {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("序列化反馈失败: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+			return fmt.Errorf("创建数据目录失败: %w", err)
+		}
+		file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("打开反馈数据失败: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(raw, '\n')); err != nil {
+			return fmt.Errorf("写入反馈失败: %w", err)
+		}
+	}
+
+	s.append(item)
+	return nil
+}
+
+// List 按时间顺序返回满足条件的最新反馈
+func (s *Store) List(query Query) []*Feedback {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []*Feedback{}
+	for i, item := range s.items {
+		if s.latest[key(item)] != i {
+			continue
+		}
+		if query.Match(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// append 加入内存索引（调用方需持有写锁）
+func (s *Store) append(item *Feedback) {
+	s.items = append(s.items, item)
+	s.latest[key(item)] = len(s.items) - 1
+}
+
+// key 用户+回答的去重键
+func key(item *Feedback) string {
+	return item.UserID + "/" + item.AttemptID
+}
+
+// newID 生成反馈ID
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic("生成随机数失败: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,90 @@
+package feedback
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestStoreLatestAndExport 测试反馈校验、持久化、同一回答只保留最新反馈以及CSV导出
+func TestStoreLatestAndExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.jsonl")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+
+	invalid := []*Feedback{
+		{AttemptID: "a1", UserID: "u1"},
+		{AttemptID: "a1", UserID: "u1", Rating: 6},
+		{AttemptID: "a1", UserID: "u1", Tags: []Tag{"boring"}},
+	}
+	for _, item := range invalid {
+		if err := store.Record(item); !errors.Is(err, ErrInvalid) {
+			t.Errorf("期望校验失败: %+v, err=%v", item, err)
+		}
+	}
+
+	records := []*Feedback{
+		{AttemptID: "a1", UserID: "u1", Rating: 2, Tags: []Tag{TagOffStyle}, Persona: "dongqing"},
+		{AttemptID: "a1", UserID: "u1", Rating: 5, Tags: []Tag{TagGreat, TagGreat}, Persona: "dongqing", Comment: " 很像, \"董卿\" "},
+		{AttemptID: "a2", UserID: "u1", Rating: 1, Tags: []Tag{TagTooLong}, Persona: "chengming"},
+		{AttemptID: "a2", UserID: "u2", Comment: "事实有误", Tags: []Tag{TagFactuallyWrong}, Persona: "chengming"},
+	}
+	for _, item := range records {
+		if err := store.Record(item); err != nil {
+			t.Fatalf("保存反馈失败: %v", err)
+		}
+	}
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	all := reloaded.List(Query{})
+	if len(all) != 3 {
+		t.Fatalf("期望3条最新反馈，实际: %d", len(all))
+	}
+	latest := reloaded.List(Query{AttemptID: "a1"})
+	if len(latest) != 1 || latest[0].Rating != 5 || len(latest[0].Tags) != 1 {
+		t.Errorf("同一回答应只保留最新且去重后的反馈: %+v", latest)
+	}
+	if got := reloaded.List(Query{Tag: TagOffStyle}); len(got) != 0 {
+		t.Errorf("被覆盖的反馈不应被查询到: %+v", got)
+	}
+	if got := reloaded.List(Query{Persona: "chengming", MaxRating: 2}); len(got) != 1 || got[0].UserID != "u1" {
+		t.Errorf("评分过滤不正确: %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, all); err != nil {
+		t.Fatalf("导出CSV失败: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("解析导出的CSV失败: %v", err)
+	}
+	if len(rows) != 4 || len(rows[0]) != len(csvHeader) {
+		t.Fatalf("CSV行列数不正确: %d行", len(rows))
+	}
+	if rows[1][6] != "很像, \"董卿\"" || rows[1][5] != "great" {
+		t.Errorf("CSV内容不正确: %v", rows[1])
+	}
+
+	for value, want := range map[string]string{
+		"=HYPERLINK(\"http://evil\")": "'=HYPERLINK(\"http://evil\")",
+		"+1":                          "'+1",
+		"-2+3":                        "'-2+3",
+		"@SUM(A1)":                    "'@SUM(A1)",
+		"\tcmd":                       "'\tcmd",
+		"\rcmd":                       "'\rcmd",
+		"正常评论":                        "正常评论",
+		"":                            "",
+	} {
+		if got := escapeCell(value); got != want {
+			t.Errorf("单元格%q应导出为%q，实际%q", value, want, got)
+		}
+	}
+}
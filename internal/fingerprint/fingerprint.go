@@ -0,0 +1,305 @@
+package fingerprint
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"reactedge/internal/ai"
+	"reactedge/pkg/style"
+)
+
+// 每个用户最多保留的报告数
+const maxReports = 200
+
+// 漂移检测参数
+const (
+	minWindow      = 3    // 基线和近期至少各需要的报告数
+	recentWindow   = 5    // 近期窗口的最大报告数
+	driftThreshold = 0.25 // 平均JS散度超过这个值认为风格发生了漂移
+)
+
+// Report 一次挑战的表达DNA报告和用户原话
+type Report struct {
+	DNA  *ai.ExpressionDNA
+	Text string
+	At   time.Time
+}
+
+// Share 某个取值在报告中出现的次数和占比
+type Share struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // 0-1
+}
+
+// Drift 近期报告相对于早期基线的变化
+type Drift struct {
+	Baseline   int                `json:"baseline"`   // 基线报告数
+	Recent     int                `json:"recent"`     // 近期报告数
+	Dimensions map[string]float64 `json:"dimensions"` // 各维度的JS散度，0表示分布相同，1表示完全不同
+	Score      float64            `json:"score"`      // 各维度的平均值
+	Drifting   bool               `json:"drifting"`
+	Changes    []string           `json:"changes,omitempty"` // 主要取值发生变化的描述
+}
+
+// Fingerprint 用户的个人表达指纹
+type Fingerprint struct {
+	UserID            string        `json:"user_id"`
+	Reports           int           `json:"reports"`
+	FirstSeen         time.Time     `json:"first_seen"`
+	LastSeen          time.Time     `json:"last_seen"`
+	StableTraits      []Share       `json:"stable_traits"`      // 至少一半报告中出现的个性标签
+	SignaturePatterns []Share       `json:"signature_patterns"` // 至少一半报告中出现的独特模式
+	ThinkingPatterns  []Share       `json:"thinking_patterns"`
+	Rhythms           []Share       `json:"rhythms"`
+	MetaphorDomains   []Share       `json:"metaphor_domains"` // 偏好的类比领域
+	Features          []style.Trait `json:"features"`         // 原话中比各名人风格平均水平更突出的特征
+	Drift             *Drift        `json:"drift,omitempty"`  // 报告太少时为空
+}
+
+// 参与漂移检测的维度
+var dimensions = []struct {
+	name  string
+	label string
+	get   func(*ai.ExpressionDNA) []string
+}{
+	{"thinking_pattern", "思维模式", func(d *ai.ExpressionDNA) []string { return []string{d.ThinkingPattern} }},
+	{"metaphor_style", "类比领域", func(d *ai.ExpressionDNA) []string { return []string{d.MetaphorStyle} }},
+	{"rhythm_signature", "节奏特征", func(d *ai.ExpressionDNA) []string { return []string{d.RhythmSignature} }},
+	{"personality_tags", "个性标签", func(d *ai.ExpressionDNA) []string { return d.PersonalityTags }},
+}
+
+// Tracker 汇总每个用户的表达DNA报告，可并发使用
+type Tracker struct {
+	reports map[string][]Report
+	mutex   sync.RWMutex
+}
+
+// NewTracker 创建指纹跟踪器
+func NewTracker() *Tracker {
+	return &Tracker{reports: make(map[string][]Report)}
+}
+
+// Observe 记录一次报告，报告按时间排序，超过上限时丢弃最早的
+func (t *Tracker) Observe(userID string, report Report) {
+	if report.DNA == nil {
+		return
+	}
+	if report.At.IsZero() {
+		report.At = time.Now()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	reports := append(t.reports[userID], report)
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].At.Before(reports[j].At) })
+	if len(reports) > maxReports {
+		reports = reports[len(reports)-maxReports:]
+	}
+	t.reports[userID] = reports
+}
+
+// Fingerprint 计算用户的表达指纹，没有报告时返回nil
+func (t *Tracker) Fingerprint(userID string) *Fingerprint {
+	reports := t.snapshot(userID)
+	if len(reports) == 0 {
+		return nil
+	}
+
+	dna := make([]*ai.ExpressionDNA, len(reports))
+	for i, report := range reports {
+		dna[i] = report.DNA
+	}
+	fp := &Fingerprint{
+		UserID:            userID,
+		Reports:           len(reports),
+		FirstSeen:         reports[0].At,
+		LastSeen:          reports[len(reports)-1].At,
+		StableTraits:      stable(shares(dna, func(d *ai.ExpressionDNA) []string { return d.PersonalityTags })),
+		SignaturePatterns: stable(shares(dna, func(d *ai.ExpressionDNA) []string { return d.UniquePatterns })),
+		ThinkingPatterns:  shares(dna, dimensions[0].get),
+		MetaphorDomains:   shares(dna, dimensions[1].get),
+		Rhythms:           shares(dna, dimensions[2].get),
+		Features:          style.Default().Traits(joinTexts(reports)),
+		Drift:             detectDrift(dna),
+	}
+	return fp
+}
+
+// Text 返回用户全部原话，用于和名人风格比较
+func (t *Tracker) Text(userID string) string {
+	return joinTexts(t.snapshot(userID))
+}
+
+// snapshot 复制用户的报告列表
+func (t *Tracker) snapshot(userID string) []Report {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return append([]Report(nil), t.reports[userID]...)
+}
+
+// Comparison 用户表达与一种名人风格的对比
+type Comparison struct {
+	Persona    string   `json:"persona"`
+	Name       string   `json:"name"`
+	Similarity float64  `json:"similarity"`        // 0-10
+	Shared     []string `json:"shared,omitempty"`  // 与该风格接近的特征
+	Gaps       []string `json:"gaps,omitempty"`    // 要向该风格靠拢需要调整的地方
+	Phrases    []string `json:"phrases,omitempty"` // 与该风格语料共有的字词
+}
+
+// Compare 把用户原话和每种风格逐一比较，按相似度从高到低排列
+func Compare(classifier *style.Classifier, text string, personas []string) []Comparison {
+	comparisons := []Comparison{}
+	if strings.TrimSpace(text) == "" {
+		return comparisons
+	}
+	for _, persona := range personas {
+		result := classifier.Classify(text, persona)
+		if result.Target == "" {
+			continue
+		}
+		comparison := Comparison{Persona: persona, Similarity: result.Conformity, Gaps: result.Suggestions, Phrases: result.Phrases}
+		for _, score := range result.Scores {
+			if score.Persona == persona {
+				comparison.Name = score.Name
+			}
+		}
+		for _, e := range result.Evidence {
+			if e.Match >= 0.6 && e.Closest == persona {
+				comparison.Shared = append(comparison.Shared, e.Label)
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool { return comparisons[i].Similarity > comparisons[j].Similarity })
+	return comparisons
+}
+
+// detectDrift 比较近期报告和早期基线在各维度上的分布
+func detectDrift(dna []*ai.ExpressionDNA) *Drift {
+	recent := recentWindow
+	if half := len(dna) / 2; half < recent {
+		recent = half
+	}
+	if recent < minWindow || len(dna)-recent < minWindow {
+		return nil
+	}
+	baseline, latest := dna[:len(dna)-recent], dna[len(dna)-recent:]
+
+	drift := &Drift{Baseline: len(baseline), Recent: len(latest), Dimensions: make(map[string]float64)}
+	total := 0.0
+	for _, dimension := range dimensions {
+		before, after := shares(baseline, dimension.get), shares(latest, dimension.get)
+		divergence := round2(jsDivergence(before, after))
+		drift.Dimensions[dimension.name] = divergence
+		total += divergence
+		if divergence >= driftThreshold && len(before) > 0 && len(after) > 0 && before[0].Value != after[0].Value {
+			drift.Changes = append(drift.Changes, dimension.label+"从「"+before[0].Value+"」转向「"+after[0].Value+"」")
+		}
+	}
+	drift.Score = round2(total / float64(len(dimensions)))
+	drift.Drifting = drift.Score >= driftThreshold
+	return drift
+}
+
+// shares 统计各取值出现的次数，占比按报告数计算，按次数从高到低排列
+func shares(dna []*ai.ExpressionDNA, get func(*ai.ExpressionDNA) []string) []Share {
+	counts := make(map[string]int)
+	for _, d := range dna {
+		seen := make(map[string]bool)
+		for _, value := range get(d) {
+			if value = strings.TrimSpace(value); value != "" && !seen[value] {
+				seen[value] = true
+				counts[value]++
+			}
+		}
+	}
+
+	result := make([]Share, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, Share{Value: value, Count: count, Share: round2(float64(count) / float64(len(dna)))})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
+
+// stable 只保留至少一半报告中出现的取值
+func stable(list []Share) []Share {
+	result := []Share{}
+	for _, share := range list {
+		if share.Share >= 0.5 {
+			result = append(result, share)
+		}
+	}
+	return result
+}
+
+// jsDivergence 两个分布的Jensen-Shannon散度（以2为底，取值0-1）
+func jsDivergence(a, b []Share) float64 {
+	p, q := distribution(a), distribution(b)
+	if len(p) == 0 || len(q) == 0 {
+		return 0
+	}
+	divergence := 0.0
+	for value := range union(p, q) {
+		m := (p[value] + q[value]) / 2
+		if p[value] > 0 {
+			divergence += p[value] * math.Log2(p[value]/m) / 2
+		}
+		if q[value] > 0 {
+			divergence += q[value] * math.Log2(q[value]/m) / 2
+		}
+	}
+	return math.Min(1, math.Max(0, divergence))
+}
+
+// distribution 把次数归一化成概率分布
+func distribution(list []Share) map[string]float64 {
+	total := 0
+	for _, share := range list {
+		total += share.Count
+	}
+	result := make(map[string]float64, len(list))
+	for _, share := range list {
+		result[share.Value] = float64(share.Count) / float64(total)
+	}
+	return result
+}
+
+// union 两个分布的取值并集
+func union(a, b map[string]float64) map[string]bool {
+	result := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		result[key] = true
+	}
+	for key := range b {
+		result[key] = true
+	}
+	return result
+}
+
+// joinTexts 拼接报告中的原话
+func joinTexts(reports []Report) string {
+	var texts []string
+	for _, report := range reports {
+		if text := strings.TrimSpace(report.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,96 @@
+package fingerprint
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"reactedge/internal/ai"
+	"reactedge/pkg/style"
+)
+
+// report 构造一份DNA报告
+func report(day int, metaphor, thinking string, tags ...string) Report {
+	return Report{
+		DNA: &ai.ExpressionDNA{
+			MetaphorStyle:   metaphor,
+			ThinkingPattern: thinking,
+			RhythmSignature: "转折对比",
+			PersonalityTags: tags,
+			UniquePatterns:  []string{"敢于挑战常规"},
+		},
+		Text: "表面上是书店繁荣，实际上是打卡代替阅读，这和游戏里的贴图有什么区别？",
+		At:   time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// TestFingerprint 测试指纹汇总和漂移检测
+func TestFingerprint(t *testing.T) {
+	tracker := NewTracker()
+	if tracker.Fingerprint("u1") != nil {
+		t.Fatal("没有报告时应返回nil")
+	}
+
+	// 前三次偏游戏类比，后三次转向科技视角；故意乱序加入
+	tracker.Observe("u1", report(4, "科技视角", "逻辑推理", "本质追问者"))
+	for day := 1; day <= 3; day++ {
+		tracker.Observe("u1", report(day, "游戏思维", "类比思维", "游戏思维者", "本质追问者"))
+	}
+	tracker.Observe("u1", report(5, "科技视角", "逻辑推理", "本质追问者"))
+	tracker.Observe("u1", report(6, "科技视角", "逻辑推理", "本质追问者"))
+	tracker.Observe("u1", Report{}) // 没有DNA的报告忽略
+
+	fp := tracker.Fingerprint("u1")
+	if fp.Reports != 6 || fp.FirstSeen.Day() != 1 || fp.LastSeen.Day() != 6 {
+		t.Fatalf("报告数或时间范围不正确: %+v", fp)
+	}
+	if len(fp.StableTraits) != 2 || fp.StableTraits[0].Value != "本质追问者" || fp.StableTraits[0].Share != 1 {
+		t.Fatalf("稳定特质不正确: %+v", fp.StableTraits)
+	}
+	if len(fp.MetaphorDomains) != 2 || fp.MetaphorDomains[0].Share != 0.5 {
+		t.Fatalf("类比领域不正确: %+v", fp.MetaphorDomains)
+	}
+
+	drift := fp.Drift
+	if drift == nil || drift.Baseline != 3 || drift.Recent != 3 {
+		t.Fatalf("应检测漂移: %+v", drift)
+	}
+	if drift.Dimensions["metaphor_style"] != 1 || drift.Dimensions["rhythm_signature"] != 0 || !drift.Drifting {
+		t.Fatalf("漂移分数不正确: %+v", drift)
+	}
+	if len(drift.Changes) != 2 || drift.Changes[1] != "类比领域从「游戏思维」转向「科技视角」" {
+		t.Fatalf("变化描述不正确: %v", drift.Changes)
+	}
+
+	// 报告太少时不做漂移检测
+	tracker.Observe("u2", report(1, "生活类比", "现象描述"))
+	if tracker.Fingerprint("u2").Drift != nil {
+		t.Fatal("报告太少时不应检测漂移")
+	}
+}
+
+// TestJSDivergence 测试JS散度的边界
+func TestJSDivergence(t *testing.T) {
+	a := []Share{{Value: "x", Count: 1}, {Value: "y", Count: 1}}
+	if jsDivergence(a, a) != 0 {
+		t.Fatal("相同分布的散度应为0")
+	}
+	if got := jsDivergence([]Share{{Value: "x", Count: 2}}, []Share{{Value: "y", Count: 5}}); got != 1 {
+		t.Fatalf("不相交分布的散度应为1: %v", got)
+	}
+	if got := jsDivergence(a, []Share{{Value: "x", Count: 1}}); math.Abs(got-0.31) > 0.01 {
+		t.Fatalf("散度计算不正确: %v", got)
+	}
+}
+
+// TestCompare 测试与名人风格的比较
+func TestCompare(t *testing.T) {
+	text := "凭什么？难道排队两小时就是热爱阅读？表面上是书店繁荣，实际上是打卡代替阅读。"
+	comparisons := Compare(style.Default(), text, []string{"kanghui", "hanhan", "unknown"})
+	if len(comparisons) != 2 || comparisons[0].Persona != "hanhan" || comparisons[0].Name != "韩寒" {
+		t.Fatalf("应最接近韩寒且忽略未知风格: %+v", comparisons)
+	}
+	if len(Compare(style.Default(), " ", []string{"hanhan"})) != 0 {
+		t.Fatal("空文本不应比较")
+	}
+}
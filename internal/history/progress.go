@@ -0,0 +1,225 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Metric 进度指标
+type Metric string
+
+const (
+	MetricOverall     Metric = "overall_score"
+	MetricContent     Metric = "content_quality"
+	MetricStyle       Metric = "style_conformity"
+	MetricSpeed       Metric = "reaction_speed"
+	MetricEffect      Metric = "communication_effect"
+	MetricWordsPerMin Metric = "words_per_minute"
+	MetricRhythm      Metric = "rhythm_score"
+	MetricClarity     Metric = "clarity_score"
+	MetricConfidence  Metric = "confidence_score"
+	MetricSharpness   Metric = "sharpeness_score"
+	MetricUniqueness  Metric = "uniqueness_score"
+)
+
+// metricExtractors 从记录中读取各指标的值，记录缺少对应数据时返回false
+var metricExtractors = map[Metric]func(a *Attempt) (float64, bool){
+	MetricOverall: func(a *Attempt) (float64, bool) {
+		if a.Evaluation == nil {
+			return 0, false
+		}
+		return a.Evaluation.OverallScore, true
+	},
+	MetricContent: func(a *Attempt) (float64, bool) {
+		if a.Evaluation == nil {
+			return 0, false
+		}
+		return a.Evaluation.ContentQuality.Score, true
+	},
+	MetricStyle: func(a *Attempt) (float64, bool) {
+		if a.Evaluation == nil {
+			return 0, false
+		}
+		return a.Evaluation.StyleConformity.Score, true
+	},
+	MetricSpeed: func(a *Attempt) (float64, bool) {
+		if a.Evaluation == nil {
+			return 0, false
+		}
+		return a.Evaluation.ReactionSpeed.Score, true
+	},
+	MetricEffect: func(a *Attempt) (float64, bool) {
+		if a.Evaluation == nil {
+			return 0, false
+		}
+		return a.Evaluation.CommunicationEffect.Score, true
+	},
+	MetricWordsPerMin: func(a *Attempt) (float64, bool) {
+		if a.Speech == nil || a.Speech.WordsPerMinute <= 0 {
+			return 0, false
+		}
+		return a.Speech.WordsPerMinute, true
+	},
+	MetricRhythm: func(a *Attempt) (float64, bool) {
+		if a.Speech == nil {
+			return 0, false
+		}
+		return float64(a.Speech.RhythmScore), true
+	},
+	MetricClarity: func(a *Attempt) (float64, bool) {
+		if a.Speech == nil {
+			return 0, false
+		}
+		return float64(a.Speech.ClarityScore), true
+	},
+	MetricConfidence: func(a *Attempt) (float64, bool) {
+		if a.Speech == nil {
+			return 0, false
+		}
+		return float64(a.Speech.ConfidenceScore), true
+	},
+	MetricSharpness: func(a *Attempt) (float64, bool) {
+		if a.DNA == nil {
+			return 0, false
+		}
+		return float64(a.DNA.SharpenessScore), true
+	},
+	MetricUniqueness: func(a *Attempt) (float64, bool) {
+		if a.DNA == nil {
+			return 0, false
+		}
+		return float64(a.DNA.UniquenessScore), true
+	},
+}
+
+// Metrics 返回所有支持的指标，按名称排序
+func Metrics() []Metric {
+	metrics := make([]Metric, 0, len(metricExtractors))
+	for metric := range metricExtractors {
+		metrics = append(metrics, metric)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i] < metrics[j] })
+	return metrics
+}
+
+// ValidMetric 是否为支持的指标
+func ValidMetric(metric Metric) bool {
+	_, ok := metricExtractors[metric]
+	return ok
+}
+
+// Bucket 时间分桶粒度
+type Bucket string
+
+const (
+	BucketDay  Bucket = "day"
+	BucketWeek Bucket = "week"
+)
+
+// Point 一个时间桶内的指标统计
+type Point struct {
+	Time  time.Time `json:"time"`
+	Mean  float64   `json:"mean"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int       `json:"count"`
+}
+
+// maxSeriesPoints 单个序列最多返回的时间桶数量
+const maxSeriesPoints = 366
+
+// Series 指标的时间序列
+type Series struct {
+	Metric  Metric  `json:"metric"`
+	Persona string  `json:"persona,omitempty"`
+	Points  []Point `json:"points"`
+	Change  float64 `json:"change"` // 最后一个桶相对第一个桶的均值变化
+}
+
+// BuildSeries 按时间桶聚合记录中的指标
+func BuildSeries(attempts []*Attempt, metric Metric, bucket Bucket) Series {
+	series := Series{Metric: metric, Points: []Point{}}
+	extract, ok := metricExtractors[metric]
+	if !ok {
+		return series
+	}
+
+	buckets := make(map[time.Time]*Point)
+	sums := make(map[time.Time]float64)
+	for _, attempt := range attempts {
+		value, ok := extract(attempt)
+		if !ok {
+			continue
+		}
+		key := bucketStart(attempt.CreatedAt, bucket)
+		point, exists := buckets[key]
+		if !exists {
+			point = &Point{Time: key, Min: value, Max: value}
+			buckets[key] = point
+		}
+		point.Count++
+		point.Min = math.Min(point.Min, value)
+		point.Max = math.Max(point.Max, value)
+		sums[key] += value
+	}
+
+	for key, point := range buckets {
+		point.Mean = math.Round(sums[key]/float64(point.Count)*100) / 100
+		series.Points = append(series.Points, *point)
+	}
+	sort.Slice(series.Points, func(i, j int) bool {
+		return series.Points[i].Time.Before(series.Points[j].Time)
+	})
+	if len(series.Points) > maxSeriesPoints {
+		series.Points = series.Points[len(series.Points)-maxSeriesPoints:]
+	}
+	if n := len(series.Points); n > 1 {
+		series.Change = math.Round((series.Points[n-1].Mean-series.Points[0].Mean)*100) / 100
+	}
+	return series
+}
+
+// BuildSeriesByPersona 按名人风格分别聚合指标
+func BuildSeriesByPersona(attempts []*Attempt, metric Metric, bucket Bucket) []Series {
+	groups := make(map[string][]*Attempt)
+	for _, attempt := range attempts {
+		groups[attempt.Persona] = append(groups[attempt.Persona], attempt)
+	}
+
+	personas := make([]string, 0, len(groups))
+	for persona := range groups {
+		personas = append(personas, persona)
+	}
+	sort.Strings(personas)
+
+	var result []Series
+	for _, persona := range personas {
+		series := BuildSeries(groups[persona], metric, bucket)
+		if len(series.Points) == 0 {
+			continue
+		}
+		series.Persona = persona
+		result = append(result, series)
+	}
+	return result
+}
+
+// bucketStart 计算时间所在桶的起点（本地时区，周从周一开始）
+func bucketStart(t time.Time, bucket Bucket) time.Time {
+	year, month, day := t.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	if bucket == BucketWeek {
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+	return start
+}
+
+// ParseBucket 解析分桶参数，未知值使用按天分桶
+func ParseBucket(value string) Bucket {
+	if Bucket(value) == BucketWeek {
+		return BucketWeek
+	}
+	return BucketDay
+}
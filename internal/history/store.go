@@ -0,0 +1,224 @@
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"reactedge/internal/ai"
+	"reactedge/internal/analysis"
+	aiPkg "reactedge/pkg/ai"
+)
+
+// AttemptKind 训练记录类型
+type AttemptKind string
+
+const (
+	KindGenerate   AttemptKind = "generate"   // 名人风格回答生成
+	KindEvaluation AttemptKind = "evaluation" // 训练回答评估
+	KindChallenge  AttemptKind = "challenge"  // 挑战流程的表达DNA分析
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("训练记录不存在")
+
+// Attempt 一次训练记录
+type Attempt struct {
+	ID              string                    `json:"id"`
+	UserID          string                    `json:"user_id"`
+	Kind            AttemptKind               `json:"kind"`
+	Question        string                    `json:"question"`
+	Persona         string                    `json:"persona,omitempty"`
+	Scenario        string                    `json:"scenario,omitempty"`
+	Difficulty      int                       `json:"difficulty,omitempty"`
+	Content         string                    `json:"content,omitempty"` // 参考的经典内容
+	UserAnswer      string                    `json:"user_answer,omitempty"`
+	GeneratedAnswer string                    `json:"generated_answer,omitempty"`
+	Prompt          string                    `json:"prompt,omitempty"`     // 使用的提示词模板，格式为"名称@版本"
+	Experiment      string                    `json:"experiment,omitempty"` // 所属的提示词实验
+	Variant         string                    `json:"variant,omitempty"`    // 实验变体
+	Citations       []string                  `json:"citations,omitempty"`  // 生成回答时引用的语料片段
+	Speech          *analysis.SpeechResult    `json:"speech,omitempty"`
+	DNA             *ai.ExpressionDNA         `json:"dna,omitempty"`
+	Evaluation      *aiPkg.ReactionEvaluation `json:"evaluation,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at"`
+}
+
+// Query 历史记录查询条件，零值字段表示不限制
+type Query struct {
+	UserID  string
+	Kind    AttemptKind
+	Persona string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+// Match 判断记录是否满足查询条件
+func (q Query) Match(a *Attempt) bool {
+	if q.UserID != "" && a.UserID != q.UserID {
+		return false
+	}
+	if q.Kind != "" && a.Kind != q.Kind {
+		return false
+	}
+	if q.Persona != "" && a.Persona != q.Persona {
+		return false
+	}
+	if !q.Since.IsZero() && a.CreatedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !a.CreatedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Store 训练历史存储接口
+type Store interface {
+	// Record 保存一条记录，未设置ID和时间时自动生成
+	Record(attempt *Attempt) error
+	// Get 按ID获取记录
+	Get(id string) (*Attempt, error)
+	// List 按时间升序返回满足条件的记录，Limit限制返回最近的N条
+	List(query Query) ([]*Attempt, error)
+}
+
+// FileStore 基于JSONL文件的历史存储，启动时全部加载到内存
+type FileStore struct {
+	path     string
+	attempts []*Attempt
+	index    map[string]*Attempt
+	mutex    sync.RWMutex
+}
+
+// NewFileStore 创建文件存储，path为空时仅保存在内存中
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:  path,
+		index: make(map[string]*Attempt),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开历史记录失败: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var attempt Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &attempt); err != nil {
+			// 跳过损坏的行（例如写入中断），不影响其他记录
+			fmt.Printf("⚠️ 跳过第%d行损坏的历史记录: %v\n", line, err)
+			continue
+		}
+		store.append(&attempt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+
+	sort.SliceStable(store.attempts, func(i, j int) bool {
+		return store.attempts[i].CreatedAt.Before(store.attempts[j].CreatedAt)
+	})
+	return store, nil
+}
+
+// Record 追加一条记录
+func (s *FileStore) Record(attempt *Attempt) error {
+	if attempt.ID == "" {
+		attempt.ID = newID()
+	}
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.path != "" {
+		raw, err := json.Marshal(attempt)
+		if err != nil {
+			return fmt.Errorf("序列化历史记录失败: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+			return fmt.Errorf("创建数据目录失败: %w", err)
+		}
+		file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("打开历史记录失败: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(raw, '\n')); err != nil {
+			return fmt.Errorf("写入历史记录失败: %w", err)
+		}
+	}
+
+	s.append(attempt)
+	return nil
+}
+
+// Get 按ID获取记录
+func (s *FileStore) Get(id string) (*Attempt, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attempt, ok := s.index[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return attempt, nil
+}
+
+// List 查询记录
+func (s *FileStore) List(query Query) ([]*Attempt, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var result []*Attempt
+	for _, attempt := range s.attempts {
+		if query.Match(attempt) {
+			result = append(result, attempt)
+		}
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result, nil
+}
+
+// append 加入内存索引（调用方需持有写锁）
+func (s *FileStore) append(attempt *Attempt) {
+	s.attempts = append(s.attempts, attempt)
+	s.index[attempt.ID] = attempt
+}
+
+// newID 生成记录ID
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic("生成随机数失败: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
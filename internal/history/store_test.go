@@ -0,0 +1,68 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	aiPkg "reactedge/pkg/ai"
+)
+
+// TestFileStoreProgress 测试记录持久化以及按周、按风格聚合进度
+func TestFileStoreProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+
+	start := time.Date(2025, 3, 3, 10, 0, 0, 0, time.Local) // 周一
+	scores := []struct {
+		persona string
+		offset  int
+		overall float64
+	}{
+		{"hanhan", 0, 5.0},
+		{"hanhan", 2, 6.0},
+		{"kanghui", 3, 7.0},
+		{"hanhan", 8, 8.0},
+	}
+	for _, item := range scores {
+		err := store.Record(&Attempt{
+			UserID:     "u1",
+			Kind:       KindEvaluation,
+			Persona:    item.persona,
+			Evaluation: &aiPkg.ReactionEvaluation{OverallScore: item.overall},
+			CreatedAt:  start.AddDate(0, 0, item.offset),
+		})
+		if err != nil {
+			t.Fatalf("保存记录失败: %v", err)
+		}
+	}
+	store.Record(&Attempt{UserID: "u2", Kind: KindGenerate, Persona: "hanhan"})
+
+	reloaded, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	attempts, _ := reloaded.List(Query{UserID: "u1"})
+	if len(attempts) != 4 {
+		t.Fatalf("期望4条记录，实际: %d", len(attempts))
+	}
+
+	series := BuildSeries(attempts, MetricOverall, BucketWeek)
+	if len(series.Points) != 2 {
+		t.Fatalf("期望2个周数据点，实际: %d", len(series.Points))
+	}
+	if series.Points[0].Mean != 6.0 || series.Points[0].Count != 3 {
+		t.Errorf("第一周统计不正确: %+v", series.Points[0])
+	}
+	if series.Change != 2.0 {
+		t.Errorf("期望进步2.0，实际: %.2f", series.Change)
+	}
+
+	byPersona := BuildSeriesByPersona(attempts, MetricOverall, BucketDay)
+	if len(byPersona) != 2 || byPersona[0].Persona != "hanhan" || len(byPersona[0].Points) != 3 {
+		t.Errorf("按风格聚合不正确: %+v", byPersona)
+	}
+}
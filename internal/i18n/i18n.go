@@ -0,0 +1,287 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var builtinLocales embed.FS
+
+// 内置支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+)
+
+// DefaultLocale 没有协商出语言时使用的语言，也是缺少翻译时的回退语言
+const DefaultLocale = ZhCN
+
+// Catalog 消息目录：语言 → 消息键 → 文本，文本可以包含fmt格式占位符
+type Catalog struct {
+	messages map[string]map[string]string
+	fallback string
+}
+
+// DefaultCatalog 返回内置消息目录
+func DefaultCatalog() *Catalog {
+	catalog := &Catalog{messages: make(map[string]map[string]string), fallback: DefaultLocale}
+	if err := catalog.loadFS(builtinLocales, "locales"); err != nil {
+		// 内置消息目录有测试保证，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析内置消息目录失败: %v", err))
+	}
+	return catalog
+}
+
+// LoadCatalog 加载目录中的消息文件（文件名为语言，如en-US.yaml）并合并到内置目录，
+// 同名消息覆盖内置文本，新语言直接加入；dir为空时只使用内置目录，fallback为空时使用DefaultLocale
+func LoadCatalog(dir, fallback string) (*Catalog, error) {
+	catalog := DefaultCatalog()
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("读取消息目录失败: %w", err)
+		}
+		if err := catalog.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	if fallback != "" {
+		locale := catalog.Match(fallback)
+		if locale == "" {
+			return nil, fmt.Errorf("默认语言%s没有消息文件", fallback)
+		}
+		catalog.fallback = locale
+	}
+	return catalog, nil
+}
+
+// loadFS 加载目录中的消息文件
+func (c *Catalog) loadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("读取消息目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return fmt.Errorf("读取消息文件失败: %w", err)
+		}
+		var messages map[string]string
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("解析消息文件%s失败: %w", entry.Name(), err)
+		}
+
+		locale := strings.TrimSuffix(entry.Name(), ext)
+		if existing := c.Match(locale); existing != "" && strings.EqualFold(existing, locale) {
+			locale = existing
+		}
+		if c.messages[locale] == nil {
+			c.messages[locale] = make(map[string]string)
+		}
+		for key, text := range messages {
+			c.messages[locale][key] = text
+		}
+	}
+	return nil
+}
+
+// Locales 目录支持的全部语言，按名称排列
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Fallback 回退语言
+func (c *Catalog) Fallback() string {
+	return c.fallback
+}
+
+// Match 返回与语言标签匹配的已支持语言，先精确匹配（不区分大小写），
+// 再按基础语言匹配，如en-GB、en匹配en-US，zh-TW、zh-Hans匹配zh-CN；没有匹配时返回空
+func (c *Catalog) Match(tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return ""
+	}
+	locales := c.Locales()
+	for _, locale := range locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+	base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	// 回退语言优先，避免同一基础语言有多个地区时结果取决于文件名
+	if strings.ToLower(strings.SplitN(c.fallback, "-", 2)[0]) == base {
+		return c.fallback
+	}
+	for _, locale := range locales {
+		if strings.ToLower(strings.SplitN(locale, "-", 2)[0]) == base {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Negotiate 协商请求使用的语言：preferred（如请求参数、用户设置）按顺序优先，
+// 其次按Accept-Language的权重选择，都没有匹配时使用回退语言
+func (c *Catalog) Negotiate(acceptLanguage string, preferred ...string) string {
+	for _, tag := range preferred {
+		if locale := c.Match(tag); locale != "" {
+			return locale
+		}
+	}
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			return c.fallback
+		}
+		if locale := c.Match(tag); locale != "" {
+			return locale
+		}
+	}
+	return c.fallback
+}
+
+// Lookup 查找消息文本，当前语言缺少时使用回退语言的文本
+func (c *Catalog) Lookup(locale, key string) (string, bool) {
+	if text, ok := c.messages[locale][key]; ok {
+		return text, true
+	}
+	text, ok := c.messages[c.fallback][key]
+	return text, ok
+}
+
+// Messages 返回键以prefix开头的全部消息，当前语言缺少的使用回退语言的文本，供页面脚本使用
+func (c *Catalog) Messages(locale, prefix string) map[string]string {
+	messages := make(map[string]string)
+	for _, source := range []string{c.fallback, locale} {
+		for key, text := range c.messages[source] {
+			if strings.HasPrefix(key, prefix) {
+				messages[key] = text
+			}
+		}
+	}
+	return messages
+}
+
+// T 翻译消息，有参数时按fmt格式填充；找不到消息时返回消息键本身，便于发现缺失的翻译
+func (c *Catalog) T(locale, key string, args ...interface{}) string {
+	text, ok := c.Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// parseAcceptLanguage 解析Accept-Language，按权重从高到低返回语言标签，忽略权重为0的语言
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+var (
+	defaultCatalog *Catalog
+	catalogMutex   sync.RWMutex
+)
+
+// Default 返回全局消息目录，未设置时使用内置目录
+func Default() *Catalog {
+	catalogMutex.RLock()
+	catalog := defaultCatalog
+	catalogMutex.RUnlock()
+	if catalog != nil {
+		return catalog
+	}
+
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+	if defaultCatalog == nil {
+		defaultCatalog = DefaultCatalog()
+	}
+	return defaultCatalog
+}
+
+// SetDefault 替换全局消息目录
+func SetDefault(catalog *Catalog) {
+	catalogMutex.Lock()
+	defaultCatalog = catalog
+	catalogMutex.Unlock()
+}
+
+// T 使用全局消息目录翻译消息
+func T(locale, key string, args ...interface{}) string {
+	return Default().T(locale, key, args...)
+}
+
+// Negotiate 使用全局消息目录协商语言
+func Negotiate(acceptLanguage string, preferred ...string) string {
+	return Default().Negotiate(acceptLanguage, preferred...)
+}
+
+// IsEnglish 语言是否为英文，用于选择分析指标等与语言相关的规则
+func IsEnglish(locale string) bool {
+	return strings.EqualFold(strings.SplitN(locale, "-", 2)[0], "en")
+}
+
+type contextKey struct{}
+
+// WithLocale 将请求语言写入上下文
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext 从上下文获取请求语言，没有时使用全局目录的回退语言
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return Default().Fallback()
+}
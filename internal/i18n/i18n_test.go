@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuiltinCatalog 测试内置消息目录各语言的消息键一致
+func TestBuiltinCatalog(t *testing.T) {
+	catalog := DefaultCatalog()
+	if locales := catalog.Locales(); len(locales) != 2 || locales[0] != EnUS || locales[1] != ZhCN {
+		t.Fatalf("内置语言不正确: %v", locales)
+	}
+	for key := range catalog.messages[ZhCN] {
+		if _, ok := catalog.messages[EnUS][key]; !ok {
+			t.Errorf("en-US缺少消息: %s", key)
+		}
+	}
+	for key := range catalog.messages[EnUS] {
+		if _, ok := catalog.messages[ZhCN][key]; !ok {
+			t.Errorf("zh-CN缺少消息: %s", key)
+		}
+	}
+
+	if got := catalog.T(EnUS, "challenge.welcome.description", "话题"); !strings.Contains(got, "话题") || strings.Contains(got, "%!") {
+		t.Fatalf("参数应填入消息: %s", got)
+	}
+	if got := catalog.T("fr-FR", "error.login_required"); got != "请先登录" {
+		t.Fatalf("未知语言应回退到zh-CN: %s", got)
+	}
+	if got := catalog.T(EnUS, "no.such.key"); got != "no.such.key" {
+		t.Fatalf("缺失的消息应返回键本身: %s", got)
+	}
+	if messages := catalog.Messages(EnUS, "page.demo."); messages["page.demo.generate"] == "" || messages["page.home.start"] != "" {
+		t.Fatalf("应只返回指定前缀的消息: %v", messages)
+	}
+}
+
+// TestNegotiate 测试按参数、用户设置和Accept-Language协商语言
+func TestNegotiate(t *testing.T) {
+	catalog := DefaultCatalog()
+	cases := []struct {
+		accept    string
+		preferred []string
+		want      string
+	}{
+		{"", nil, ZhCN},
+		{"en-GB,en;q=0.9", nil, EnUS},
+		{"fr-FR,zh-TW;q=0.8,en;q=0.5", nil, ZhCN},
+		{"zh-CN;q=0.3,en-US;q=0.7", nil, EnUS},
+		{"en;q=0,fr", nil, ZhCN},
+		{"de,*;q=0.5", nil, ZhCN},
+		{"zh-CN", []string{"", "EN_us"}, EnUS},
+		{"en-US", []string{"ja"}, EnUS},
+	}
+	for _, c := range cases {
+		if got := catalog.Negotiate(c.accept, c.preferred...); got != c.want {
+			t.Errorf("Negotiate(%q, %v) = %s，期望%s", c.accept, c.preferred, got, c.want)
+		}
+	}
+
+	ctx := WithLocale(context.Background(), EnUS)
+	if FromContext(ctx) != EnUS || FromContext(context.Background()) != DefaultLocale {
+		t.Fatal("上下文中的语言不正确")
+	}
+}
+
+// TestLoadCatalog 测试自定义消息目录合并和默认语言
+func TestLoadCatalog(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en-us.yaml": "page.home.start: \"Let's go\"\n",
+		"ja-JP.yaml": "locale.name: \"日本語\"\nerror.login_required: \"ログインしてください\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog, err := LoadCatalog(dir, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Fallback() != EnUS || len(catalog.Locales()) != 3 {
+		t.Fatalf("默认语言或语言列表不正确: %s %v", catalog.Fallback(), catalog.Locales())
+	}
+	if got := catalog.T(EnUS, "page.home.start"); got != "Let's go" {
+		t.Fatalf("自定义消息应覆盖内置消息: %s", got)
+	}
+	if got := catalog.T("ja-JP", "page.home.start"); got != "Let's go" {
+		t.Fatalf("新语言缺少的消息应回退到默认语言: %s", got)
+	}
+	if catalog.Negotiate("ja") != "ja-JP" || catalog.Negotiate("fr") != EnUS {
+		t.Fatal("新语言应参与协商")
+	}
+
+	if _, err := LoadCatalog(dir, "fr-FR"); err == nil {
+		t.Fatal("默认语言没有消息文件时应报错")
+	}
+	if _, err := LoadCatalog(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Fatal("目录不存在时应返回错误")
+	}
+}
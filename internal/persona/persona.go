@@ -0,0 +1,245 @@
+package persona
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	aiPkg "reactedge/pkg/ai"
+	"reactedge/pkg/style"
+)
+
+// DefaultKey 未指定或找不到风格时使用的风格
+const DefaultKey = "kanghui"
+
+// 样本长度限制（按字符计）
+const (
+	MinSampleLength = 50
+	MaxSampleLength = 20000
+)
+
+// Persona 可供模仿的讲话风格
+type Persona struct {
+	Key          string               `json:"key"`
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Instructions string               `json:"instructions,omitempty"` // 放进提示词的表达要求
+	Phrases      []string             `json:"phrases,omitempty"`      // 样本中的代表性说法
+	Features     map[string]string    `json:"features,omitempty"`     // 风格分析得出的特征，如"语言特点.vocabulary"
+	Tags         []string             `json:"tags,omitempty"`
+	Base         string               `json:"base,omitempty"`  // 最接近的内置风格，AI不可用时用它的本地模拟回答
+	Voice        string               `json:"voice,omitempty"` // 朗读音色，为空时使用通用音色
+	Samples      []string             `json:"samples,omitempty"`
+	Analysis     *aiPkg.StyleAnalysis `json:"analysis,omitempty"`
+	Builtin      bool                 `json:"builtin,omitempty"`
+	OwnerID      string               `json:"owner_id,omitempty"`
+	Shared       bool                 `json:"shared,omitempty"`     // 是否对所有用户可见
+	CreatedAt    *time.Time           `json:"created_at,omitempty"` // 内置风格为空
+}
+
+// VisibleTo 判断用户能否使用这个风格
+func (p *Persona) VisibleTo(userID string, admin bool) bool {
+	return p.Builtin || p.Shared || admin || p.OwnerID == userID
+}
+
+// Corpus 转成风格分类器的训练语料
+func (p *Persona) Corpus() style.Corpus {
+	return style.Corpus{Persona: p.Key, Name: p.Name, Samples: p.Samples}
+}
+
+// builtins 内置的四种名人风格
+var builtins = []Persona{
+	{Key: "kanghui", Name: "康辉", Description: "专业得体，逻辑严谨，数据支撑，权威感强，结构清晰，适合正式场合和汇报答辩"},
+	{Key: "dongqing", Name: "董卿", Description: "温婉大气，情感共鸣，优雅从容，善解人意，注重倾听，创造和谐沟通氛围"},
+	{Key: "hanhan", Name: "韩寒", Description: "犀利穿透，直言不讳，敢于挑战常规，反问拆解，态度鲜明，真诚表达"},
+	{Key: "chengming", Name: "成铭", Description: "逻辑严谨，层层递进，策略性强，归谬反驳，理性分析，掌控局面"},
+}
+
+// Builtins 返回内置风格
+func Builtins() []Persona {
+	result := make([]Persona, len(builtins))
+	for i, p := range builtins {
+		p.Builtin = true
+		p.Base = p.Key
+		result[i] = p
+	}
+	return result
+}
+
+// 分析结果各维度的中文名
+var dimensions = []struct {
+	label string
+	get   func(*aiPkg.StyleAnalysis) map[string]interface{}
+}{
+	{"语言特点", func(a *aiPkg.StyleAnalysis) map[string]interface{} { return a.LanguageFeatures }},
+	{"思维方式", func(a *aiPkg.StyleAnalysis) map[string]interface{} { return a.ThinkingPatterns }},
+	{"沟通策略", func(a *aiPkg.StyleAnalysis) map[string]interface{} { return a.CommunicationStrategy }},
+	{"个人特质", func(a *aiPkg.StyleAnalysis) map[string]interface{} { return a.PersonalTraits }},
+}
+
+// Derive 根据样本和风格分析生成自定义风格
+// analysis为空或是兜底结果时只用本地风格分类器提取特征，不会套用默认分析
+func Derive(name string, samples []string, analysis *aiPkg.StyleAnalysis) *Persona {
+	text := strings.Join(samples, "\n")
+	p := &Persona{
+		Name:     name,
+		Samples:  samples,
+		Phrases:  examplePhrases(samples, 5),
+		Features: make(map[string]string),
+	}
+
+	classifier := style.Default()
+	// 分类器可能已经加入了其他自定义风格，这里只取最接近的内置风格
+	p.Base = DefaultKey
+	for _, score := range classifier.Classify(text, "").Scores {
+		if lookupBuiltin(score.Persona) != nil {
+			p.Base = score.Persona
+			break
+		}
+	}
+	traits := classifier.Traits(text)
+
+	if analysis != nil && !analysis.Output.IsFallback() {
+		p.Analysis = analysis
+		var instructions []string
+		for _, dimension := range dimensions {
+			values := dimension.get(analysis)
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			var parts []string
+			for _, key := range keys {
+				value := describeValue(values[key])
+				if value == "" {
+					continue
+				}
+				p.Features[dimension.label+"."+key] = value
+				parts = append(parts, value)
+			}
+			if len(parts) > 0 {
+				instructions = append(instructions, dimension.label+"："+strings.Join(parts, "，"))
+			}
+		}
+		p.Instructions = strings.Join(instructions, "；")
+		p.Tags = append(p.Tags, analysis.StyleTags...)
+		if labels := describeValue(analysis.PersonalTraits["style_labels"]); labels != "" {
+			p.Tags = appendUnique(p.Tags, strings.Split(labels, "、")...)
+		}
+	}
+
+	// 本地特征总是记录，AI分析不可用时作为唯一依据
+	var labels []string
+	for _, trait := range traits {
+		p.Features["本地特征."+trait.Feature] = fmt.Sprintf("%s（高出平均%.1f个标准差）", trait.Label, trait.Score)
+		labels = append(labels, trait.Label)
+		if len(labels) >= 3 {
+			break
+		}
+	}
+	if p.Instructions == "" {
+		if len(labels) > 0 {
+			p.Instructions = "多使用" + strings.Join(labels, "、")
+		}
+		p.Tags = appendUnique(p.Tags, labels...)
+	}
+
+	if len(p.Tags) > 0 {
+		p.Description = strings.Join(p.Tags, "，")
+	}
+	if baseName := nameOf(p.Base); baseName != "" {
+		if p.Description != "" {
+			p.Description += "；"
+		}
+		p.Description += "整体接近" + baseName + "的风格"
+	}
+	return p
+}
+
+// describeValue 把分析结果中的值转成文字，列表用顿号连接
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case []string:
+		return strings.Join(v, "、")
+	case []interface{}:
+		var parts []string
+		for _, item := range v {
+			if text := describeValue(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "、")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var sentencePattern = regexp.MustCompile(`[^。！？!?；;\n]+[。！？!?]?`)
+
+// examplePhrases 从样本中挑选长度适中、不重复的句子作为代表性说法
+func examplePhrases(samples []string, limit int) []string {
+	var phrases []string
+	seen := make(map[string]bool)
+	for _, sample := range samples {
+		for _, sentence := range sentencePattern.FindAllString(sample, -1) {
+			sentence = strings.TrimSpace(sentence)
+			length := utf8.RuneCountInString(sentence)
+			if length < 8 || length > 40 || seen[sentence] {
+				continue
+			}
+			seen[sentence] = true
+			phrases = append(phrases, sentence)
+			if len(phrases) >= limit {
+				return phrases
+			}
+		}
+	}
+	return phrases
+}
+
+// appendUnique 追加不重复的非空字符串
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		exists := false
+		for _, item := range list {
+			if item == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// lookupBuiltin 按标识查找内置风格
+func lookupBuiltin(key string) *Persona {
+	for _, p := range Builtins() {
+		if p.Key == key {
+			return &p
+		}
+	}
+	return nil
+}
+
+// nameOf 内置风格的名称
+func nameOf(key string) string {
+	if p := lookupBuiltin(key); p != nil {
+		return p.Name
+	}
+	return ""
+}
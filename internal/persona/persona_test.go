@@ -0,0 +1,112 @@
+package persona
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	aiPkg "reactedge/pkg/ai"
+)
+
+var samples = []string{
+	"各位同事，我先讲三点。第一，数据不会说谎，本季度增长了百分之十二。第二，我们要坚持长期主义，不追短期热点。第三，团队比个人重要。",
+	"我一直跟大家说，做产品就像种树，前三年看不到什么，第五年才知道根扎得深不深。所以不要焦虑，把每一个细节做扎实。",
+}
+
+// TestDerive 测试从AI分析和本地特征生成自定义风格
+func TestDerive(t *testing.T) {
+	analysis := &aiPkg.StyleAnalysis{
+		LanguageFeatures: map[string]interface{}{"vocabulary": "口语化", "rhythm": []interface{}{"短句", "排比"}},
+		PersonalTraits:   map[string]interface{}{"style_labels": []string{"务实型"}},
+		StyleTags:        []string{"务实", "比喻"},
+	}
+	p := Derive("王总", samples, analysis)
+	if p.Features["语言特点.vocabulary"] != "口语化" || p.Features["语言特点.rhythm"] != "短句、排比" {
+		t.Fatalf("应展开分析结果: %+v", p.Features)
+	}
+	if !strings.Contains(p.Instructions, "语言特点：短句、排比，口语化") {
+		t.Fatalf("表达要求不正确: %s", p.Instructions)
+	}
+	if strings.Join(p.Tags, ",") != "务实,比喻,务实型" || lookupBuiltin(p.Base) == nil {
+		t.Fatalf("标签或基础风格不正确: %v %s", p.Tags, p.Base)
+	}
+	if len(p.Phrases) == 0 || strings.Contains(p.Phrases[0], "\n") {
+		t.Fatalf("应从样本中挑选代表性说法: %v", p.Phrases)
+	}
+
+	// 兜底分析不能被当成真实分析
+	fallback := &aiPkg.StyleAnalysis{StyleTags: []string{"专业"}, Output: &aiPkg.OutputInfo{Source: aiPkg.OutputFallback}}
+	local := Derive("王总", samples, fallback)
+	if local.Analysis != nil || strings.Contains(local.Description, "专业，") || local.Instructions == "" {
+		t.Fatalf("兜底分析时应只用本地特征: %+v", local)
+	}
+}
+
+// TestRegistry 测试自定义风格的保存、可见性、持久化和删除
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "personas.json")
+	registry, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("创建注册表失败: %v", err)
+	}
+
+	if _, err := registry.Add(&Persona{Name: "太短", Samples: []string{"你好"}}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("样本太短应拒绝: %v", err)
+	}
+	if _, err := registry.Add(&Persona{Key: "hanhan", Name: "冒充", Samples: samples}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("不能占用内置风格标识: %v", err)
+	}
+
+	p := Derive("王总", samples, nil)
+	p.OwnerID = "u1"
+	saved, err := registry.Add(p)
+	if err != nil || !strings.HasPrefix(saved.Key, "custom-") {
+		t.Fatalf("保存失败: %v %+v", err, saved)
+	}
+
+	if list := registry.List("u1", false); len(list) != 5 || list[4].Samples != nil {
+		t.Fatalf("创建者应看到内置风格和自己的风格，且不含样本: %+v", list)
+	}
+	if list := registry.List("u2", false); len(list) != 4 {
+		t.Fatalf("其他用户看不到未共享的风格: %d", len(list))
+	}
+	if registry.Resolve("unknown").Key != DefaultKey || registry.Resolve(saved.Key).Name != "王总" {
+		t.Fatal("查找风格不正确")
+	}
+
+	classifier, err := registry.Classifier()
+	if err != nil || len(classifier.Personas()) != 4 {
+		t.Fatalf("分类器不应包含未共享的风格: %v", err)
+	}
+	shared := Derive("李总", samples, nil)
+	shared.OwnerID, shared.Shared = "u1", true
+	sharedSaved, err := registry.Add(shared)
+	if err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	classifier, err = registry.Classifier()
+	if err != nil || len(classifier.Personas()) != 5 {
+		t.Fatalf("分类器应包含已共享的自定义风格: %v", err)
+	}
+	if result := classifier.Classify(samples[1], ""); result.Closest != sharedSaved.Key {
+		t.Fatalf("样本应被识别为自定义风格: %s", result.Closest)
+	}
+
+	reloaded, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if got, ok := reloaded.Get(saved.Key); !ok || len(got.Samples) != 2 {
+		t.Fatalf("重新加载后应保留样本: %+v", got)
+	}
+	if err := reloaded.Remove("kanghui"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("内置风格不能删除: %v", err)
+	}
+	if err := reloaded.Remove(saved.Key); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := reloaded.Remove(saved.Key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("重复删除应返回不存在: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package persona
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"reactedge/pkg/style"
+)
+
+var (
+	ErrNotFound = errors.New("风格不存在")
+	ErrInvalid  = errors.New("风格参数无效")
+	ErrConflict = errors.New("风格标识已存在")
+)
+
+// keyPattern 自定义风格标识只允许小写字母、数字、下划线和连字符，同时用作语料目录名
+var keyPattern = regexp.MustCompile(`^[a-z0-9_-]{2,32}$`)
+
+// Registry 风格注册表，内置风格固定，自定义风格保存在JSON文件中
+type Registry struct {
+	path   string
+	custom map[string]*Persona
+	mutex  sync.RWMutex
+}
+
+// NewRegistry 创建注册表，路径为空时自定义风格仅保存在内存中
+func NewRegistry(path string) (*Registry, error) {
+	registry := &Registry{path: path, custom: make(map[string]*Persona)}
+	if path == "" {
+		return registry, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取自定义风格失败: %w", err)
+	}
+	if err := json.Unmarshal(raw, &registry.custom); err != nil {
+		return nil, fmt.Errorf("解析自定义风格失败: %w", err)
+	}
+	return registry, nil
+}
+
+// Get 按标识查找风格
+func (r *Registry) Get(key string) (*Persona, bool) {
+	if builtin := lookupBuiltin(key); builtin != nil {
+		return builtin, true
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	p, ok := r.custom[key]
+	if !ok {
+		return nil, false
+	}
+	copied := *p
+	return &copied, true
+}
+
+// Resolve 按标识查找风格，找不到时返回默认风格
+func (r *Registry) Resolve(key string) *Persona {
+	if p, ok := r.Get(key); ok {
+		return p
+	}
+	return lookupBuiltin(DefaultKey)
+}
+
+// List 返回用户可以使用的风格，内置风格在前，自定义风格按创建时间排列
+// 列表不含样本原文和分析详情，需要时用Get查询
+func (r *Registry) List(userID string, admin bool) []Persona {
+	result := Builtins()
+
+	r.mutex.RLock()
+	var custom []Persona
+	for _, p := range r.custom {
+		if p.VisibleTo(userID, admin) {
+			summary := *p
+			summary.Samples, summary.Analysis = nil, nil
+			custom = append(custom, summary)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(custom, func(i, j int) bool {
+		a, b := custom[i].CreatedAt, custom[j].CreatedAt
+		if a == nil || b == nil || a.Equal(*b) {
+			return custom[i].Key < custom[j].Key
+		}
+		return a.Before(*b)
+	})
+	return append(result, custom...)
+}
+
+// Add 保存自定义风格，标识为空时自动生成
+func (r *Registry) Add(p *Persona) (*Persona, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return nil, fmt.Errorf("%w: 缺少名称", ErrInvalid)
+	}
+	length := 0
+	for _, sample := range p.Samples {
+		length += utf8.RuneCountInString(sample)
+	}
+	if length < MinSampleLength || length > MaxSampleLength {
+		return nil, fmt.Errorf("%w: 样本总长度需要在%d到%d字之间", ErrInvalid, MinSampleLength, MaxSampleLength)
+	}
+	if p.Key == "" {
+		p.Key = "custom-" + newKey()
+	}
+	if !keyPattern.MatchString(p.Key) {
+		return nil, fmt.Errorf("%w: 标识只能包含小写字母、数字、下划线和连字符", ErrInvalid)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.custom[p.Key]; exists || lookupBuiltin(p.Key) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrConflict, p.Key)
+	}
+	saved := *p
+	saved.Builtin = false
+	now := time.Now()
+	saved.CreatedAt = &now
+	r.custom[saved.Key] = &saved
+	if err := r.save(); err != nil {
+		delete(r.custom, saved.Key)
+		return nil, err
+	}
+	copied := saved
+	return &copied, nil
+}
+
+// Remove 删除自定义风格，内置风格不能删除
+func (r *Registry) Remove(key string) error {
+	if lookupBuiltin(key) != nil {
+		return fmt.Errorf("%w: 内置风格不能删除", ErrInvalid)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	p, ok := r.custom[key]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.custom, key)
+	if err := r.save(); err != nil {
+		r.custom[key] = p
+		return err
+	}
+	return nil
+}
+
+// Classifier 用内置语料和已共享的自定义风格样本训练风格分类器，私有风格的样本不参与训练
+func (r *Registry) Classifier() (*style.Classifier, error) {
+	corpora := style.DefaultCorpora()
+	r.mutex.RLock()
+	for _, p := range r.custom {
+		if p.Shared {
+			corpora = append(corpora, p.Corpus())
+		}
+	}
+	r.mutex.RUnlock()
+	return style.Train(corpora)
+}
+
+// save 写入自定义风格，先写临时文件再重命名
+func (r *Registry) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(r.custom, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化自定义风格失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("写入自定义风格失败: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("保存自定义风格失败: %w", err)
+	}
+	return nil
+}
+
+// newKey 生成随机标识
+func newKey() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		panic("生成随机数失败: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
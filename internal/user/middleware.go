@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"reactedge/internal/i18n"
+)
+
+// SessionCookie 会话Cookie名称
+const SessionCookie = "reactedge_session"
+
+type contextKey struct{}
+
+// WithUser 将用户写入上下文
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// FromContext 从上下文获取当前用户，未登录时返回nil
+func FromContext(ctx context.Context) *User {
+	u, _ := ctx.Value(contextKey{}).(*User)
+	return u
+}
+
+// Middleware 识别请求用户并写入上下文
+// enabled为false时所有请求都以匿名用户身份处理
+func Middleware(store *Store, enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled {
+				next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), Anonymous())))
+				return
+			}
+
+			if u := Resolve(store, r); u != nil {
+				r = r.WithContext(WithUser(r.Context(), u))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Resolve 依次通过API令牌和会话Cookie识别用户
+func Resolve(store *Store, r *http.Request) *User {
+	if store == nil {
+		return nil
+	}
+
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if u, err := store.UserForToken(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))); err == nil {
+			return u
+		}
+		return nil
+	}
+
+	if cookie, err := r.Cookie(SessionCookie); err == nil && cookie.Value != "" {
+		if u, err := store.UserForSession(cookie.Value); err == nil {
+			return u
+		}
+	}
+	return nil
+}
+
+// Require 要求请求已登录，否则返回401
+func Require(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if FromContext(r.Context()) == nil {
+			http.Error(w, i18n.T(i18n.FromContext(r.Context()), "error.login_required"), http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// RequireAdmin 要求请求用户为管理员，否则返回403
+func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return Require(func(w http.ResponseWriter, r *http.Request) {
+		if !FromContext(r.Context()).IsAdmin() {
+			http.Error(w, i18n.T(i18n.FromContext(r.Context()), "error.admin_required"), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
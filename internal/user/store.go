@@ -0,0 +1,375 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenPrefix API令牌前缀，便于在日志和配置中识别
+const TokenPrefix = "re_"
+
+// storeData 持久化到文件的数据
+type storeData struct {
+	Users    map[string]*User    `json:"users"`
+	Sessions map[string]*Session `json:"sessions"`
+	Tokens   map[string]*Token   `json:"tokens"`
+}
+
+// Store 用户存储，数据保存在单个JSON文件中
+type Store struct {
+	path       string
+	sessionTTL time.Duration
+	data       storeData
+	mutex      sync.RWMutex
+}
+
+// NewStore 创建用户存储，path为空时仅保存在内存中
+func NewStore(path string, sessionTTL time.Duration) (*Store, error) {
+	if sessionTTL <= 0 {
+		sessionTTL = 7 * 24 * time.Hour
+	}
+
+	store := &Store{
+		path:       path,
+		sessionTTL: sessionTTL,
+		data: storeData{
+			Users:    make(map[string]*User),
+			Sessions: make(map[string]*Session),
+			Tokens:   make(map[string]*Token),
+		},
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取用户数据失败: %w", err)
+	}
+	if err := json.Unmarshal(raw, &store.data); err != nil {
+		return nil, fmt.Errorf("解析用户数据失败: %w", err)
+	}
+	if store.data.Users == nil {
+		store.data.Users = make(map[string]*User)
+	}
+	if store.data.Sessions == nil {
+		store.data.Sessions = make(map[string]*Session)
+	}
+	if store.data.Tokens == nil {
+		store.data.Tokens = make(map[string]*Token)
+	}
+
+	return store, nil
+}
+
+// Register 注册新用户，注册的用户都是普通用户
+func (s *Store) Register(username, password string) (*User, error) {
+	return s.create(username, password, RoleUser)
+}
+
+// EnsureAdmin 创建管理员账号，用户名已存在时把该用户设为管理员，不修改密码
+func (s *Store) EnsureAdmin(username, password string) (*User, error) {
+	s.mutex.Lock()
+	if u := s.findByUsername(strings.TrimSpace(username)); u != nil {
+		defer s.mutex.Unlock()
+		if u.Role == RoleAdmin {
+			return u.Public(), nil
+		}
+		u.Role = RoleAdmin
+		if err := s.save(); err != nil {
+			u.Role = RoleUser
+			return nil, err
+		}
+		return u.Public(), nil
+	}
+	s.mutex.Unlock()
+	return s.create(username, password, RoleAdmin)
+}
+
+// HasAdmin 是否已有管理员账号
+func (s *Store) HasAdmin() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, u := range s.data.Users {
+		if u.IsAdmin() {
+			return true
+		}
+	}
+	return false
+}
+
+// create 校验用户名和密码后保存新用户
+func (s *Store) create(username, password string, role Role) (*User, error) {
+	username = strings.TrimSpace(username)
+	if n := utf8.RuneCountInString(username); n < 3 || n > 32 || len(password) < 8 {
+		return nil, ErrInvalidInput
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("密码哈希失败: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.findByUsername(username) != nil {
+		return nil, ErrUserExists
+	}
+
+	u := &User{
+		ID:           randomID(8),
+		Username:     username,
+		Role:         role,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+	s.data.Users[u.ID] = u
+
+	if err := s.save(); err != nil {
+		delete(s.data.Users, u.ID)
+		return nil, err
+	}
+	return u.Public(), nil
+}
+
+// Authenticate 校验用户名和密码
+func (s *Store) Authenticate(username, password string) (*User, error) {
+	s.mutex.RLock()
+	u := s.findByUsername(strings.TrimSpace(username))
+	s.mutex.RUnlock()
+
+	if u == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return u.Public(), nil
+}
+
+// GetUser 按ID获取用户
+func (s *Store) GetUser(userID string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	u, ok := s.data.Users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return u.Public(), nil
+}
+
+// SetLocale 设置用户的语言偏好，locale为空表示清除偏好
+func (s *Store) SetLocale(userID, locale string) (*User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u, ok := s.data.Users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	previous := u.Locale
+	u.Locale = locale
+	if err := s.save(); err != nil {
+		u.Locale = previous
+		return nil, err
+	}
+	return u.Public(), nil
+}
+
+// CreateSession 为用户创建登录会话
+func (s *Store) CreateSession(userID string) (*Session, error) {
+	now := time.Now()
+	session := &Session{
+		ID:        randomID(32),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.data.Users[userID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	// 顺便清理过期会话
+	for id, existing := range s.data.Sessions {
+		if existing.Expired(now) {
+			delete(s.data.Sessions, id)
+		}
+	}
+	s.data.Sessions[session.ID] = session
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// UserForSession 根据会话ID获取用户
+func (s *Store) UserForSession(sessionID string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	session, ok := s.data.Sessions[sessionID]
+	if !ok || session.Expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	u, ok := s.data.Users[session.UserID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return u.Public(), nil
+}
+
+// DeleteSession 注销会话
+func (s *Store) DeleteSession(sessionID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.data.Sessions[sessionID]; !ok {
+		return nil
+	}
+	delete(s.data.Sessions, sessionID)
+	return s.save()
+}
+
+// CreateToken 创建API令牌，明文令牌只在创建时返回一次
+func (s *Store) CreateToken(userID, name string) (string, *Token, error) {
+	plain := TokenPrefix + randomID(24)
+	token := &Token{
+		ID:        randomID(6),
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Hash:      hashToken(plain),
+		CreatedAt: time.Now(),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.data.Users[userID]; !ok {
+		return "", nil, ErrNotFound
+	}
+	s.data.Tokens[token.ID] = token
+
+	if err := s.save(); err != nil {
+		delete(s.data.Tokens, token.ID)
+		return "", nil, err
+	}
+	return plain, publicToken(token), nil
+}
+
+// UserForToken 根据明文API令牌获取用户
+func (s *Store) UserForToken(plain string) (*User, error) {
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		return nil, ErrNotFound
+	}
+	hash := hashToken(plain)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, token := range s.data.Tokens {
+		if token.Hash != hash {
+			continue
+		}
+		u, ok := s.data.Users[token.UserID]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		// 最后使用时间只保存在内存中，避免每次请求都写文件
+		now := time.Now()
+		token.LastUsedAt = &now
+		return u.Public(), nil
+	}
+	return nil, ErrNotFound
+}
+
+// ListTokens 列出用户的API令牌（不含哈希）
+func (s *Store) ListTokens(userID string) []*Token {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var tokens []*Token
+	for _, token := range s.data.Tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, publicToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens
+}
+
+// RevokeToken 吊销用户的API令牌
+func (s *Store) RevokeToken(userID, tokenID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	token, ok := s.data.Tokens[tokenID]
+	if !ok || token.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.data.Tokens, tokenID)
+	return s.save()
+}
+
+// findByUsername 按用户名查找（调用方需持有锁）
+func (s *Store) findByUsername(username string) *User {
+	for _, u := range s.data.Users {
+		if strings.EqualFold(u.Username, username) {
+			return u
+		}
+	}
+	return nil
+}
+
+// save 写入文件（调用方需持有写锁）
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化用户数据失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致数据损坏
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("写入用户数据失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("保存用户数据失败: %w", err)
+	}
+	return nil
+}
+
+// publicToken 返回不含哈希的令牌副本
+func publicToken(token *Token) *Token {
+	copied := *token
+	copied.Hash = ""
+	return &copied
+}
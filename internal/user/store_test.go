@@ -0,0 +1,81 @@
+package user
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestStoreAuthFlow 测试注册、管理员初始化、登录、会话和API令牌的完整流程
+func TestStoreAuthFlow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	store, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+
+	if store.HasAdmin() {
+		t.Fatal("空存储不应有管理员")
+	}
+	admin, err := store.Register("alice", "password123")
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	if admin.IsAdmin() || admin.PasswordHash != "" {
+		t.Errorf("注册的用户应为普通用户且不返回密码哈希: %+v", admin)
+	}
+	if promoted, err := store.EnsureAdmin("alice", "ignored-password"); err != nil || !promoted.IsAdmin() || promoted.ID != admin.ID {
+		t.Fatalf("已有用户应被设为管理员: %v %+v", err, promoted)
+	}
+	if _, err := store.Authenticate("alice", "password123"); err != nil {
+		t.Errorf("设为管理员不应修改密码: %v", err)
+	}
+	if root, err := store.EnsureAdmin("root", "password456"); err != nil || !root.IsAdmin() || !store.HasAdmin() {
+		t.Fatalf("应创建管理员账号: %v %+v", err, root)
+	}
+	if _, err := store.Register("ALICE", "password123"); err != ErrUserExists {
+		t.Errorf("重复用户名应返回ErrUserExists，实际: %v", err)
+	}
+	if _, err := store.Authenticate("alice", "wrong-password"); err != ErrInvalidCredentials {
+		t.Errorf("错误密码应返回ErrInvalidCredentials，实际: %v", err)
+	}
+
+	session, err := store.CreateSession(admin.ID)
+	if err != nil {
+		t.Fatalf("创建会话失败: %v", err)
+	}
+	plain, _, err := store.CreateToken(admin.ID, "script")
+	if err != nil {
+		t.Fatalf("创建令牌失败: %v", err)
+	}
+
+	// 重新加载后会话和令牌仍然有效
+	reloaded, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if u, err := reloaded.UserForSession(session.ID); err != nil || u.ID != admin.ID {
+		t.Errorf("会话识别失败: %v", err)
+	}
+	if u, err := reloaded.UserForToken(plain); err != nil || u.ID != admin.ID {
+		t.Errorf("令牌识别失败: %v", err)
+	}
+
+	if u, err := reloaded.SetLocale(admin.ID, "en-US"); err != nil || u.Locale != "en-US" {
+		t.Fatalf("设置语言失败: %v", err)
+	}
+	if _, err := reloaded.SetLocale("missing", "en-US"); err != ErrNotFound {
+		t.Errorf("不存在的用户应返回ErrNotFound，实际: %v", err)
+	}
+
+	tokens := reloaded.ListTokens(admin.ID)
+	if len(tokens) != 1 || tokens[0].Hash != "" {
+		t.Fatalf("令牌列表不正确: %+v", tokens)
+	}
+	if err := reloaded.RevokeToken(admin.ID, tokens[0].ID); err != nil {
+		t.Fatalf("吊销令牌失败: %v", err)
+	}
+	if _, err := reloaded.UserForToken(plain); err != ErrNotFound {
+		t.Errorf("吊销后的令牌不应再有效")
+	}
+}
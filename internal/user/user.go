@@ -0,0 +1,99 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Role 用户角色
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// AnonymousID 未启用认证时使用的匿名用户ID
+const AnonymousID = "anonymous"
+
+var (
+	// ErrUserExists 用户名已被注册
+	ErrUserExists = errors.New("用户名已存在")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	// ErrNotFound 用户、会话或令牌不存在
+	ErrNotFound = errors.New("记录不存在")
+	// ErrInvalidInput 输入不合法
+	ErrInvalidInput = errors.New("用户名需为3-32个字符，密码至少8个字符")
+)
+
+// User 用户账号
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Role         Role      `json:"role"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	Locale       string    `json:"locale,omitempty"` // 界面和回答语言，为空时按请求协商
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IsAdmin 是否为管理员
+func (u *User) IsAdmin() bool {
+	return u != nil && u.Role == RoleAdmin
+}
+
+// Public 返回不含密码哈希的副本，用于接口输出
+func (u *User) Public() *User {
+	if u == nil {
+		return nil
+	}
+	copied := *u
+	copied.PasswordHash = ""
+	return &copied
+}
+
+// Anonymous 未启用认证时的匿名用户，只有普通用户权限，管理接口需要开启认证后由管理员访问
+func Anonymous() *User {
+	return &User{ID: AnonymousID, Username: AnonymousID, Role: RoleUser}
+}
+
+// Session 浏览器登录会话
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired 会话是否已过期
+func (s *Session) Expired(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+// Token 供脚本调用的API令牌，只保存哈希值
+type Token struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Hash       string     `json:"hash,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// randomID 生成随机十六进制ID
+func randomID(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic("生成随机数失败: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// hashToken 计算令牌哈希，令牌本身有足够熵，无需慢哈希
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
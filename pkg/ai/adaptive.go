@@ -0,0 +1,480 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"reactedge/internal/i18n"
+)
+
+// TrainingDimension 训练评估维度
+type TrainingDimension string
+
+const (
+	DimensionContent TrainingDimension = "content" // 内容质量
+	DimensionStyle   TrainingDimension = "style"   // 风格符合度
+	DimensionSpeed   TrainingDimension = "speed"   // 反应速度
+	DimensionEffect  TrainingDimension = "effect"  // 沟通效果
+)
+
+// AllDimensions 所有评估维度（固定顺序）
+var AllDimensions = []TrainingDimension{DimensionContent, DimensionStyle, DimensionSpeed, DimensionEffect}
+
+const (
+	initialRating    = 1200.0 // 初始能力分
+	difficultyBase   = 1050.0 // 难度1对应的题目分
+	difficultyStep   = 150.0  // 每升一级难度增加的题目分
+	targetSuccess    = 0.7    // 期望答对率（最近发展区）
+	minDifficulty    = 1
+	maxDifficulty    = 5
+	maxHistoryRecord = 50
+)
+
+// dimensionInfo 维度的针对性场景，场景名同时是评估记录里的场景标识
+var dimensionInfo = map[TrainingDimension]struct {
+	Scenarios []string
+}{
+	DimensionContent: {Scenarios: []string{"述职答辩", "方案评审", "数据汇报"}},
+	DimensionStyle:   {Scenarios: []string{"风格复述", "跨部门协调", "客户沟通"}},
+	DimensionSpeed:   {Scenarios: []string{"分享会提问", "突击提问", "限时答辩"}},
+	DimensionEffect:  {Scenarios: []string{"争辩冲突", "谈判说服", "危机应对"}},
+}
+
+// DimensionLabel 获取维度在指定语言下的名称，未知维度返回标识本身
+func DimensionLabel(dim TrainingDimension, locale string) string {
+	if _, ok := dimensionInfo[dim]; ok {
+		return i18n.T(locale, "training.dimension."+string(dim))
+	}
+	return string(dim)
+}
+
+// dimensionFocus 获取维度在指定语言下的训练重点
+func dimensionFocus(dim TrainingDimension, locale string) string {
+	return i18n.T(locale, "training.focus."+string(dim))
+}
+
+// EvaluationRecord 单次评估记录
+type EvaluationRecord struct {
+	Scenario     string                        `json:"scenario"`
+	Difficulty   int                           `json:"difficulty"`
+	Scores       map[TrainingDimension]float64 `json:"scores"` // 0-10分
+	OverallScore float64                       `json:"overall_score"`
+	Timestamp    time.Time                     `json:"timestamp"`
+}
+
+// SkillProfile 用户能力画像（按维度的Elo式能力估计）
+type SkillProfile struct {
+	UserID    string                        `json:"user_id"`
+	Ratings   map[TrainingDimension]float64 `json:"ratings"`
+	Attempts  int                           `json:"attempts"`
+	History   []EvaluationRecord            `json:"history"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+}
+
+// Level 根据平均能力分估算用户等级（1-5）
+func (p *SkillProfile) Level() int {
+	return difficultyForRating(p.meanRating())
+}
+
+// WeakestDimensions 按能力分从低到高排序的维度
+func (p *SkillProfile) WeakestDimensions() []TrainingDimension {
+	dims := make([]TrainingDimension, len(AllDimensions))
+	copy(dims, AllDimensions)
+	sort.SliceStable(dims, func(i, j int) bool {
+		return p.Ratings[dims[i]] < p.Ratings[dims[j]]
+	})
+	return dims
+}
+
+// AverageScores 最近n次评估各维度的平均分
+func (p *SkillProfile) AverageScores(n int) map[TrainingDimension]float64 {
+	averages := make(map[TrainingDimension]float64)
+	records := p.History
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+	if len(records) == 0 {
+		return averages
+	}
+	for _, dim := range AllDimensions {
+		total := 0.0
+		for _, record := range records {
+			total += record.Scores[dim]
+		}
+		averages[dim] = total / float64(len(records))
+	}
+	return averages
+}
+
+// meanRating 各维度能力分的平均值
+func (p *SkillProfile) meanRating() float64 {
+	total := 0.0
+	for _, dim := range AllDimensions {
+		total += p.Ratings[dim]
+	}
+	return total / float64(len(AllDimensions))
+}
+
+// ChallengeRecommendation 下一道题的推荐
+type ChallengeRecommendation struct {
+	Difficulty      int               `json:"difficulty"`       // 1-5
+	DifficultyLabel string            `json:"difficulty_label"` // basic/intermediate/advanced
+	TargetDimension TrainingDimension `json:"target_dimension"`
+	Scenario        string            `json:"scenario"`
+	ExpectedSuccess float64           `json:"expected_success"` // 预计得分率 0-1
+	Reason          string            `json:"reason"`
+}
+
+// AdaptiveEngine 自适应难度引擎
+type AdaptiveEngine struct {
+	profiles map[string]*SkillProfile
+	mutex    sync.RWMutex
+}
+
+// NewAdaptiveEngine 创建自适应难度引擎
+func NewAdaptiveEngine() *AdaptiveEngine {
+	return &AdaptiveEngine{
+		profiles: make(map[string]*SkillProfile),
+	}
+}
+
+// GetProfile 获取用户能力画像的副本，不存在时返回nil
+func (e *AdaptiveEngine) GetProfile(userID string) *SkillProfile {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	profile, exists := e.profiles[userID]
+	if !exists {
+		return nil
+	}
+	return profile.clone()
+}
+
+// RecordEvaluation 记录一次评估并更新能力估计
+func (e *AdaptiveEngine) RecordEvaluation(userID, scenario string, difficulty int, evaluation *ReactionEvaluation) (*SkillProfile, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("用户ID不能为空")
+	}
+	if evaluation == nil {
+		return nil, fmt.Errorf("评估结果不能为空")
+	}
+	return e.RecordScores(userID, scenario, difficulty, EvaluationScores(evaluation), evaluation.OverallScore, time.Now())
+}
+
+// RecordScores 按维度分数记录一次评估（分数范围0-10）
+func (e *AdaptiveEngine) RecordScores(userID, scenario string, difficulty int, scores map[TrainingDimension]float64, overall float64, at time.Time) (*SkillProfile, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("用户ID不能为空")
+	}
+	difficulty = clampDifficulty(difficulty)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	profile := e.profileLocked(userID)
+	questionRating := ratingForDifficulty(difficulty)
+	k := kFactor(profile.Attempts)
+
+	recorded := make(map[TrainingDimension]float64, len(AllDimensions))
+	for _, dim := range AllDimensions {
+		score, ok := scores[dim]
+		if !ok {
+			continue
+		}
+		score = math.Max(0, math.Min(10, score))
+		recorded[dim] = score
+
+		expected := expectedSuccess(profile.Ratings[dim], questionRating)
+		profile.Ratings[dim] += k * (score/10 - expected)
+	}
+
+	profile.Attempts++
+	profile.UpdatedAt = at
+	profile.History = append(profile.History, EvaluationRecord{
+		Scenario:     scenario,
+		Difficulty:   difficulty,
+		Scores:       recorded,
+		OverallScore: overall,
+		Timestamp:    at,
+	})
+	if len(profile.History) > maxHistoryRecord {
+		profile.History = profile.History[len(profile.History)-maxHistoryRecord:]
+	}
+
+	return profile.clone(), nil
+}
+
+// NextChallenge 为用户推荐下一道题的难度和场景，推荐理由使用locale对应的语言
+func (e *AdaptiveEngine) NextChallenge(userID, locale string) *ChallengeRecommendation {
+	profile := e.GetProfile(userID)
+	if profile == nil {
+		profile = newSkillProfile(userID)
+	}
+
+	target := profile.WeakestDimensions()[0]
+	rating := profile.Ratings[target]
+	difficulty := difficultyForRating(rating)
+	scenario := pickScenario(target, profile.History)
+
+	reason := i18n.T(locale, "training.reason.weakest",
+		DimensionLabel(target, locale), expectedSuccess(rating, ratingForDifficulty(difficulty))*100)
+	if profile.Attempts == 0 {
+		reason = i18n.T(locale, "training.reason.baseline")
+	}
+
+	return &ChallengeRecommendation{
+		Difficulty:      difficulty,
+		DifficultyLabel: DifficultyLabel(difficulty),
+		TargetDimension: target,
+		Scenario:        scenario,
+		ExpectedSuccess: expectedSuccess(rating, ratingForDifficulty(difficulty)),
+		Reason:          reason,
+	}
+}
+
+// GenerateWeeklyPlan 根据评估数据生成每周训练计划，训练重点使用locale对应的语言
+func (e *AdaptiveEngine) GenerateWeeklyPlan(userID, locale string) []WeeklySession {
+	profile := e.GetProfile(userID)
+	if profile == nil {
+		profile = newSkillProfile(userID)
+	}
+	if profile.Attempts == 0 {
+		return BaselinePlan(profile.Level(), locale)
+	}
+
+	weakest := profile.WeakestDimensions()
+	level := profile.Level()
+	baseDuration := 10 + 5*level
+
+	first, second := weakest[0], weakest[1]
+	stretch := clampDifficulty(difficultyForRating(profile.Ratings[first]) + 1)
+
+	return []WeeklySession{
+		{
+			Day:             1,
+			Focus:           dimensionFocus(first, locale),
+			Duration:        baseDuration + 5,
+			Scenarios:       scenariosFor(first, 2),
+			Difficulty:      difficultyForRating(profile.Ratings[first]),
+			TargetDimension: first,
+		},
+		{
+			Day:             2,
+			Focus:           dimensionFocus(second, locale),
+			Duration:        baseDuration,
+			Scenarios:       scenariosFor(second, 2),
+			Difficulty:      difficultyForRating(profile.Ratings[second]),
+			TargetDimension: second,
+		},
+		{
+			Day:             3,
+			Focus:           i18n.T(locale, "training.focus.stretch", DimensionLabel(first, locale)),
+			Duration:        baseDuration + 10,
+			Scenarios:       scenariosFor(first, 3)[2:],
+			Difficulty:      stretch,
+			TargetDimension: first,
+		},
+		{
+			Day:             4,
+			Focus:           i18n.T(locale, "training.focus.review"),
+			Duration:        baseDuration - 5,
+			Scenarios:       lowScoreScenarios(profile.History, 2),
+			Difficulty:      level,
+			TargetDimension: first,
+		},
+		{
+			Day:             5,
+			Focus:           i18n.T(locale, "training.focus.combined"),
+			Duration:        baseDuration + 15,
+			Scenarios:       []string{pickScenario(weakest[2], profile.History), pickScenario(weakest[3], profile.History)},
+			Difficulty:      clampDifficulty(level + 1),
+			TargetDimension: weakest[2],
+		},
+	}
+}
+
+// BaselinePlan 无评估记录时的基线测评计划：按等级逐一测评各维度，最后一天综合实战
+func BaselinePlan(level int, locale string) []WeeklySession {
+	level = clampDifficulty(level)
+	baseDuration := 10 + 5*level
+
+	sessions := make([]WeeklySession, 0, len(AllDimensions)+1)
+	mixed := make([]string, 0, len(AllDimensions))
+	for i, dim := range AllDimensions {
+		scenarios := scenariosFor(dim, 3)
+		sessions = append(sessions, WeeklySession{
+			Day:             i + 1,
+			Focus:           i18n.T(locale, "training.focus.baseline", DimensionLabel(dim, locale)),
+			Duration:        baseDuration,
+			Scenarios:       scenarios[:2],
+			Difficulty:      level,
+			TargetDimension: dim,
+		})
+		mixed = append(mixed, scenarios[2])
+	}
+	sessions = append(sessions, WeeklySession{
+		Day:        len(AllDimensions) + 1,
+		Focus:      i18n.T(locale, "training.focus.combined"),
+		Duration:   baseDuration + 15,
+		Scenarios:  mixed,
+		Difficulty: level,
+	})
+	return sessions
+}
+
+// profileLocked 获取或创建用户画像（调用方需持有写锁）
+func (e *AdaptiveEngine) profileLocked(userID string) *SkillProfile {
+	profile, exists := e.profiles[userID]
+	if !exists {
+		profile = newSkillProfile(userID)
+		e.profiles[userID] = profile
+	}
+	return profile
+}
+
+// newSkillProfile 创建初始能力画像
+func newSkillProfile(userID string) *SkillProfile {
+	ratings := make(map[TrainingDimension]float64, len(AllDimensions))
+	for _, dim := range AllDimensions {
+		ratings[dim] = initialRating
+	}
+	return &SkillProfile{
+		UserID:  userID,
+		Ratings: ratings,
+	}
+}
+
+// clone 深拷贝能力画像
+func (p *SkillProfile) clone() *SkillProfile {
+	copied := *p
+	copied.Ratings = make(map[TrainingDimension]float64, len(p.Ratings))
+	for dim, rating := range p.Ratings {
+		copied.Ratings[dim] = rating
+	}
+	copied.History = make([]EvaluationRecord, len(p.History))
+	copy(copied.History, p.History)
+	return &copied
+}
+
+// EvaluationScores 将评估结果转换为各训练维度的分数
+func EvaluationScores(evaluation *ReactionEvaluation) map[TrainingDimension]float64 {
+	return map[TrainingDimension]float64{
+		DimensionContent: evaluation.ContentQuality.Score,
+		DimensionStyle:   evaluation.StyleConformity.Score,
+		DimensionSpeed:   evaluation.ReactionSpeed.Score,
+		DimensionEffect:  evaluation.CommunicationEffect.Score,
+	}
+}
+
+// expectedSuccess Elo期望得分率
+func expectedSuccess(rating, questionRating float64) float64 {
+	return 1 / (1 + math.Pow(10, (questionRating-rating)/400))
+}
+
+// kFactor 随练习次数递减的学习率，早期快速收敛，后期保持稳定
+func kFactor(attempts int) float64 {
+	return math.Max(16, 48-2*float64(attempts))
+}
+
+// ratingForDifficulty 难度等级对应的题目分
+func ratingForDifficulty(difficulty int) float64 {
+	return difficultyBase + difficultyStep*float64(difficulty-1)
+}
+
+// difficultyForRating 选择期望得分率最接近目标值的难度
+func difficultyForRating(rating float64) int {
+	best := minDifficulty
+	bestGap := math.MaxFloat64
+	for d := minDifficulty; d <= maxDifficulty; d++ {
+		gap := math.Abs(expectedSuccess(rating, ratingForDifficulty(d)) - targetSuccess)
+		if gap < bestGap {
+			best, bestGap = d, gap
+		}
+	}
+	return best
+}
+
+// clampDifficulty 限制难度范围
+func clampDifficulty(difficulty int) int {
+	if difficulty < minDifficulty {
+		return minDifficulty
+	}
+	if difficulty > maxDifficulty {
+		return maxDifficulty
+	}
+	return difficulty
+}
+
+// DifficultyLabel 难度等级对应的题目难度标签（与Question.Difficulty一致）
+func DifficultyLabel(difficulty int) string {
+	switch {
+	case difficulty <= 2:
+		return "basic"
+	case difficulty <= 3:
+		return "intermediate"
+	default:
+		return "advanced"
+	}
+}
+
+// scenariosFor 获取维度对应的前n个场景
+func scenariosFor(dim TrainingDimension, n int) []string {
+	scenarios := dimensionInfo[dim].Scenarios
+	if n > len(scenarios) {
+		n = len(scenarios)
+	}
+	result := make([]string, n)
+	copy(result, scenarios[:n])
+	return result
+}
+
+// pickScenario 选择维度下最久未练习的场景
+func pickScenario(dim TrainingDimension, history []EvaluationRecord) string {
+	scenarios := dimensionInfo[dim].Scenarios
+	lastUsed := make(map[string]int)
+	for i, record := range history {
+		lastUsed[record.Scenario] = i + 1
+	}
+
+	best := scenarios[0]
+	bestIndex := math.MaxInt
+	for _, scenario := range scenarios {
+		if lastUsed[scenario] < bestIndex {
+			best, bestIndex = scenario, lastUsed[scenario]
+		}
+	}
+	return best
+}
+
+// lowScoreScenarios 历史得分最低的n个场景，用于复盘
+func lowScoreScenarios(history []EvaluationRecord, n int) []string {
+	if len(history) == 0 {
+		return []string{"自我评估"}
+	}
+
+	records := make([]EvaluationRecord, len(history))
+	copy(records, history)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].OverallScore < records[j].OverallScore
+	})
+
+	seen := make(map[string]bool)
+	scenarios := []string{}
+	for _, record := range records {
+		if record.Scenario == "" || seen[record.Scenario] {
+			continue
+		}
+		seen[record.Scenario] = true
+		scenarios = append(scenarios, record.Scenario)
+		if len(scenarios) >= n {
+			break
+		}
+	}
+	if len(scenarios) == 0 {
+		scenarios = append(scenarios, "自我评估")
+	}
+	return scenarios
+}
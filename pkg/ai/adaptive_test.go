@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAdaptiveEngineTargetsWeakestDimension 测试推荐会针对最薄弱的维度
+func TestAdaptiveEngineTargetsWeakestDimension(t *testing.T) {
+	engine := NewAdaptiveEngine()
+
+	for i := 0; i < 5; i++ {
+		_, err := engine.RecordScores("u1", "述职答辩", 2, map[TrainingDimension]float64{
+			DimensionContent: 8.5,
+			DimensionStyle:   8.0,
+			DimensionSpeed:   3.0,
+			DimensionEffect:  7.5,
+		}, 6.8, time.Now())
+		if err != nil {
+			t.Fatalf("记录评估失败: %v", err)
+		}
+	}
+
+	next := engine.NextChallenge("u1", "zh-CN")
+	if next.TargetDimension != DimensionSpeed {
+		t.Errorf("期望针对反应速度，实际: %s", next.TargetDimension)
+	}
+
+	plan := engine.GenerateWeeklyPlan("u1", "zh-CN")
+	if len(plan) != 5 {
+		t.Fatalf("期望5天计划，实际: %d", len(plan))
+	}
+	if plan[0].TargetDimension != DimensionSpeed {
+		t.Errorf("第一天应针对反应速度，实际: %s", plan[0].TargetDimension)
+	}
+}
+
+// TestAdaptiveEngineDifficultyFollowsSkill 测试难度随能力变化
+func TestAdaptiveEngineDifficultyFollowsSkill(t *testing.T) {
+	engine := NewAdaptiveEngine()
+	start := engine.NextChallenge("u2", "zh-CN").Difficulty
+
+	strong := map[TrainingDimension]float64{
+		DimensionContent: 9.5, DimensionStyle: 9.5, DimensionSpeed: 9.5, DimensionEffect: 9.5,
+	}
+	for i := 0; i < 20; i++ {
+		difficulty := engine.NextChallenge("u2", "zh-CN").Difficulty
+		engine.RecordScores("u2", "争辩冲突", difficulty, strong, 9.5, time.Now())
+	}
+
+	if got := engine.NextChallenge("u2", "zh-CN").Difficulty; got <= start {
+		t.Errorf("持续高分后难度应上升: 初始%d，当前%d", start, got)
+	}
+	if profile := engine.GetProfile("u2"); profile.Attempts != 20 {
+		t.Errorf("期望20次记录，实际: %d", profile.Attempts)
+	}
+}
+
+// TestBaselinePlanCoversAllDimensions 测试无评估记录时按等级逐一测评各维度
+func TestBaselinePlanCoversAllDimensions(t *testing.T) {
+	engine := NewAdaptiveEngine()
+
+	plan := engine.GenerateWeeklyPlan("new-user", "zh-CN")
+	if len(plan) != len(AllDimensions)+1 {
+		t.Fatalf("期望%d天计划，实际: %d", len(AllDimensions)+1, len(plan))
+	}
+	for i, dim := range AllDimensions {
+		if plan[i].TargetDimension != dim {
+			t.Errorf("第%d天应测评%s，实际: %s", i+1, dim, plan[i].TargetDimension)
+		}
+	}
+
+	beginner, expert := BaselinePlan(1, "zh-CN"), BaselinePlan(4, "zh-CN")
+	if beginner[0].Difficulty != 1 || expert[0].Difficulty != 4 {
+		t.Errorf("基线计划难度应跟随等级: %d, %d", beginner[0].Difficulty, expert[0].Difficulty)
+	}
+	if expert[0].Duration <= beginner[0].Duration {
+		t.Errorf("等级越高训练时长应越长: %d, %d", beginner[0].Duration, expert[0].Duration)
+	}
+}
+
+// TestAdaptiveEngineLocalizesPlan 测试推荐理由和训练重点使用请求的语言
+func TestAdaptiveEngineLocalizesPlan(t *testing.T) {
+	engine := NewAdaptiveEngine()
+
+	if reason := engine.NextChallenge("u3", "en-US").Reason; !strings.HasPrefix(reason, "No evaluations yet") {
+		t.Errorf("英文推荐理由不正确: %s", reason)
+	}
+	if focus := engine.GenerateWeeklyPlan("u3", "en-US")[0].Focus; focus != "Baseline assessment: Content quality" {
+		t.Errorf("英文训练重点不正确: %s", focus)
+	}
+
+	engine.RecordScores("u3", "述职答辩", 2, map[TrainingDimension]float64{
+		DimensionContent: 8, DimensionStyle: 8, DimensionSpeed: 3, DimensionEffect: 8,
+	}, 6.8, time.Now())
+	if reason := engine.NextChallenge("u3", "en-US").Reason; !strings.HasPrefix(reason, "Reaction speed is currently your weakest dimension") {
+		t.Errorf("英文推荐理由应包含维度名称: %s", reason)
+	}
+	if focus := engine.GenerateWeeklyPlan("u3", "zh-CN")[0].Focus; focus != "限时快速反应训练" {
+		t.Errorf("中文训练重点不正确: %s", focus)
+	}
+}
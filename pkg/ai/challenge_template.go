@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"context"
+
+	"reactedge/pkg/prompt"
+)
+
+// ChallengeTemplate 挑战的四步应答模板：类比切入、对比转折、现象本质、犀利反问
+type ChallengeTemplate struct {
+	Hook     string      `json:"hook"`
+	Contrast string      `json:"contrast"`
+	Essence  string      `json:"essence"`
+	Question string      `json:"question"`
+	Output   *OutputInfo `json:"output,omitempty" schema:"-"`
+}
+
+// complete 四步是否都有内容
+func (t *ChallengeTemplate) complete() bool {
+	return t.Hook != "" && t.Contrast != "" && t.Essence != "" && t.Question != ""
+}
+
+// GenerateChallengeTemplate 生成挑战应答模板，失败时返回只带降级信息的空模板，由调用方使用本地模板
+func (t *structuredTasks) GenerateChallengeTemplate(ctx context.Context, params prompt.ChallengeTemplateParams) *ChallengeTemplate {
+	var result ChallengeTemplate
+	info := t.generate(ctx, prompt.ChallengeTemplate, "text_generation", "", params, &result)
+	if !info.IsFallback() && !result.complete() {
+		info = &OutputInfo{Source: OutputFallback, Error: "模板缺少步骤"}
+	}
+	result.Output = info
+	return &result
+}
+
+// GenerateChallengeTemplate 生成挑战应答模板
+func (c *TALClient) GenerateChallengeTemplate(ctx context.Context, params prompt.ChallengeTemplateParams) *ChallengeTemplate {
+	return c.tasks.GenerateChallengeTemplate(ctx, params)
+}
+
+// GenerateChallengeTemplate 生成挑战应答模板
+func (c *OpenAIClient) GenerateChallengeTemplate(ctx context.Context, params prompt.ChallengeTemplateParams) *ChallengeTemplate {
+	return c.tasks.GenerateChallengeTemplate(ctx, params)
+}
+
+// GenerateChallengeTemplate 生成挑战应答模板，优先使用默认服务商，都不支持时返回降级结果
+func (m *Manager) GenerateChallengeTemplate(ctx context.Context, params prompt.ChallengeTemplateParams) *ChallengeTemplate {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	type templater interface {
+		GenerateChallengeTemplate(ctx context.Context, params prompt.ChallengeTemplateParams) *ChallengeTemplate
+	}
+	if client, ok := m.client.(templater); ok {
+		return client.GenerateChallengeTemplate(ctx, params)
+	}
+	for _, provider := range m.config.GetAvailableProviders() {
+		if client, ok := m.providers[provider].(templater); ok {
+			return client.GenerateChallengeTemplate(ctx, params)
+		}
+	}
+	return &ChallengeTemplate{Output: &OutputInfo{Source: OutputFallback, Error: "没有支持生成应答模板的AI服务"}}
+}
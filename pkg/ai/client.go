@@ -3,8 +3,8 @@ package ai
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+
+	"reactedge/pkg/audio"
 )
 
 // Client AI服务客户端接口
@@ -76,6 +78,7 @@ type TALClient struct {
 	baseURL    string
 	authToken  string
 	client     *openai.Client // OpenAI兼容客户端
+	tasks      *structuredTasks // 结构化输出任务
 
 	// 请求限流
 	requestMutex   sync.Mutex
@@ -128,7 +131,7 @@ func NewTALClient(config TALConfig) (*TALClient, error) {
 	openaiConfig.BaseURL = baseURL
 	client := openai.NewClientWithConfig(openaiConfig)
 
-	return &TALClient{
+	talClient := &TALClient{
 		BaseClient: &BaseClient{
 			provider: ProviderTAL,
 			config:   &Config{TAL: config},
@@ -140,7 +143,38 @@ func NewTALClient(config TALConfig) (*TALClient, error) {
 		client:         client,
 		minInterval:    minInterval, // 每秒最多1个请求
 		lastRequestTime: time.Now().Add(-minInterval * 2), // 初始化为过去的时间
-	}, nil
+	}
+	// TAL服务兼容json_object，不支持json_schema
+	talClient.tasks = newStructuredTasks(newStructuredGenerator(client, formatJSONObject), talClient.GetModelForTask, config.MaxTokens, config.Temperature)
+
+	return talClient, nil
+}
+
+// OutputStats 返回结构化输出统计
+func (c *TALClient) OutputStats() map[string]OutputCounter {
+	return c.tasks.Stats()
+}
+
+// Transcriber 返回使用TAL语音转写接口的转写器
+func (c *TALClient) Transcriber() audio.Transcriber {
+	return audio.NewOpenAITranscriber(c.client, c.config.Models.Transcription)
+}
+
+// Synthesizer 返回使用TAL语音合成接口的合成器
+func (c *TALClient) Synthesizer() audio.Synthesizer {
+	return audio.NewOpenAISynthesizer(c.client, c.config.Models.Speech)
+}
+
+// ErrSpeechNotSupported 服务商不支持语音合成
+var ErrSpeechNotSupported = errors.New("暂不支持语音合成")
+
+// synthesize 用合成器实现Client.TextToSpeech
+func synthesize(ctx context.Context, synthesizer audio.Synthesizer, text, voice string, speed float64) ([]byte, string, error) {
+	speech, err := synthesizer.Synthesize(ctx, audio.SpeechRequest{Text: text, Voice: voice, Speed: speed})
+	if err != nil {
+		return nil, "", err
+	}
+	return speech.Data, string(speech.Format), nil
 }
 
 // TALTransport TAL认证传输层
@@ -203,292 +237,22 @@ func (c *TALClient) GetModelForTask(task string) string {
 
 // AnalyzeImage 图像分析
 func (c *TALClient) AnalyzeImage(ctx context.Context, imageURL, prompt string) (*ImageAnalysisResult, error) {
-	model := c.GetModelForTask("image_analysis")
-
-	contentParts := []openai.ChatMessagePart{
-		{
-			Type: openai.ChatMessagePartTypeText,
-			Text: prompt,
-		},
-		{
-			Type: openai.ChatMessagePartTypeImageURL,
-			ImageURL: &openai.ChatMessageImageURL{
-				URL: imageURL,
-			},
-		},
-	}
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:         openai.ChatMessageRoleUser,
-				MultiContent: contentParts,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		// AI服务不可用时，返回默认响应
-		return getDefaultImageAnalysis(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultImageAnalysis(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	result := &ImageAnalysisResult{
-		ObjectName:     extractObjectName(content),
-		Category:       extractCategory(content),
-		Description:    content,
-		Confidence:     0.95,
-		KeyFeatures:    extractKeyFeatures(content),
-		ScientificName: extractScientificName(content),
-	}
-
-	return result, nil
+	return c.tasks.AnalyzeImage(ctx, imageURL, prompt), nil
 }
 
 // GenerateQuestions 生成问题
 func (c *TALClient) GenerateQuestions(ctx context.Context, contextInfo string, category string) ([]Question, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`基于以下信息为用户生成3个引导性的反应训练问题：
-
-上下文信息：%s
-训练类别：%s
-
-要求：
-1. 问题要适合职场沟通场景
-2. 问题要激发思考和反应能力
-3. 问题难度要循序渐进（从简单到深入）
-4. 每个问题都要有明确的类型标注
-5. 确保所有内容适合职场培训场景
-
-请以JSON格式返回，包含以下字段：
-- content: 问题内容
-- type: 问题类型（scenario场景, strategy策略, evaluation评估）
-- difficulty: 难度（basic基本, intermediate中级, advanced高级）
-- purpose: 问题目的说明`, contextInfo, category)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "你是一个职场沟通训练助手，专门为用户设计反应训练问题。请以JSON格式返回包含questions数组的结果。",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultQuestions(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultQuestions(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-	questions := parseQuestionsFromJSON(content)
-	if len(questions) == 0 {
-		return getDefaultQuestions(), nil
-	}
-
-	return questions, nil
+	return c.tasks.GenerateQuestions(ctx, contextInfo, category), nil
 }
 
 // PolishNote 润色笔记（这里用于润色反应记录）
 func (c *TALClient) PolishNote(ctx context.Context, rawContent, contextInfo string) (*PolishedNote, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`请帮用户润色他们的反应训练记录，让它更清晰、有逻辑性。
-
-原始内容：%s
-
-上下文信息：%s
-
-要求：
-1. 保持用户的原意和表达特色
-2. 让表达更清晰准确
-3. 添加适当的沟通技巧解释
-4. 指出可能的改进方向
-5. 确保所有内容适合职场培训场景
-
-请严格按照以下JSON格式返回结果：
-
-{
-  "title": "记录标题",
-  "summary": "内容总结",
-  "key_points": ["关键要点1", "关键要点2"],
-  "communication_tips": ["沟通技巧1"],
-  "questions": ["问题1"],
-  "improvements": ["改进建议1"],
-  "formatted_text": "格式化的文本内容"
-}
-
-请确保返回的是有效的JSON格式。`, rawContent, contextInfo)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultPolishedNote(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultPolishedNote(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// 处理markdown格式的JSON代码块
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	var jsonResult PolishedNote
-	if err := json.Unmarshal([]byte(jsonContent), &jsonResult); err != nil {
-		jsonResult = PolishedNote{
-			Title:       "反应训练记录",
-			Summary:     extractSummaryFromText(jsonContent),
-			FormattedText: jsonContent,
-			KeyPoints:   extractKeyPointsFromText(jsonContent),
-		}
-	}
-
-	// 确保必需字段有值
-	if jsonResult.Title == "" {
-		jsonResult.Title = "反应训练记录"
-	}
-	if jsonResult.Summary == "" {
-		jsonResult.Summary = "这是反应训练的记录总结"
-	}
-	if len(jsonResult.KeyPoints) == 0 {
-		jsonResult.KeyPoints = []string{"记录了训练过程", "总结了经验教训"}
-	}
-	if jsonResult.FormattedText == "" {
-		jsonResult.FormattedText = content
-	}
-
-	return &jsonResult, nil
+	return c.tasks.PolishNote(ctx, rawContent, contextInfo), nil
 }
 
-// TextToSpeech 文字转语音
+// TextToSpeech 文字转语音，调用OpenAI兼容的/audio/speech接口，返回mp3音频
 func (c *TALClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	model := c.GetModelForTask("voice_interaction")
-
-	prompt := fmt.Sprintf(`请将以下文字转换为语音：
-
-文字内容：%s
-语音类型：%s
-语言：%s
-语速：%.1f倍速
-
-要求：
-1. 生成自然流畅的语音
-2. 保持专业语调
-3. 语速适中，易于理解
-4. 发音准确，表达清晰
-
-请按照以下JSON格式返回结果：
-{
-  "audio_data": "base64编码的音频数据",
-  "format": "wav"
-}
-
-不要包含任何其他文字，只返回有效的JSON。`, text, voice, language, speed)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultAudioData(), "wav", nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultAudioData(), "wav", nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// 处理音频数据
-	var audioResult struct {
-		AudioData string `json:"audio_data"`
-		Format    string `json:"format"`
-	}
-
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &audioResult); err != nil {
-		return getDefaultAudioData(), "wav", nil
-	}
-
-	if audioResult.AudioData == "" {
-		return getDefaultAudioData(), "wav", nil
-	}
-
-	audioBytes, err := base64.StdEncoding.DecodeString(audioResult.AudioData)
-	if err != nil {
-		return getDefaultAudioData(), "wav", nil
-	}
-
-	format := audioResult.Format
-	if format == "" {
-		format = "wav"
-	}
-
-	return audioBytes, format, nil
+	return synthesize(ctx, c.Synthesizer(), text, voice, speed)
 }
 
 // AnalyzeVideo 视频分析
@@ -505,194 +269,17 @@ func (c *TALClient) GenerateVideo(ctx context.Context, script, style string, dur
 
 // GenerateReactionTemplates 生成反应模板
 func (c *TALClient) GenerateReactionTemplates(ctx context.Context, scenario, style string) ([]ReactionTemplate, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`基于以下场景和风格，为用户生成临场反应训练模板：
-
-场景：%s
-风格：%s
-
-要求：
-1. 生成3-5个实用的反应模板
-2. 每个模板包含触发情境、反应步骤、关键话术
-3. 模板要贴合职场实际场景
-4. 风格要符合指定的沟通风格
-
-请以JSON格式返回，包含templates数组，每个模板包含：
-- scenario: 触发情境
-- steps: 反应步骤数组
-- key_phrases: 关键话术数组
-- style_notes: 风格要点`, scenario, style)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "你是一个职场沟通教练，专门设计临场反应训练模板。",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// 解析JSON响应
-	var result struct {
-		Templates []ReactionTemplate `json:"templates"`
-	}
-
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	return result.Templates, nil
+	return c.tasks.GenerateReactionTemplates(ctx, scenario, style), nil
 }
 
 // AnalyzeExpressionStyle 分析表达风格
 func (c *TALClient) AnalyzeExpressionStyle(ctx context.Context, personName string, sampleText string) (*StyleAnalysis, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请分析%s的表达风格：
-
-样本文本：%s
-
-请从以下维度进行分析：
-1. 语言特点（词汇、句式、修辞手法）
-2. 思维模式（逻辑结构、论证方式）
-3. 沟通策略（立场表达、冲突处理）
-4. 个人特色（独特标识、风格标签）
-
-请返回JSON格式的分析结果。`, personName, sampleText)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result StyleAnalysis
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	return &result, nil
+	return c.tasks.AnalyzeExpressionStyle(ctx, personName, sampleText), nil
 }
 
 // SimulateDebate 模拟辩论
 func (c *TALClient) SimulateDebate(ctx context.Context, scenario string, difficulty int, userStyle string) (*DebateSimulation, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请模拟一个%s场景的辩论训练：
-
-场景：%s
-难度等级：%d
-用户风格：%s
-
-请生成：
-1. 对手的开场陈述
-2. 3轮交互对话
-3. 关键的反应机会点
-4. 风格适配建议
-
-返回JSON格式的结果。`, scenario, scenario, difficulty, userStyle)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result DebateSimulation
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	return &result, nil
+	return c.tasks.SimulateDebate(ctx, scenario, difficulty, userStyle), nil
 }
 
 // GenerateResponseWithModel 使用指定模型生成回答
@@ -844,62 +431,5 @@ func (c *TALClient) GenerateResponseWithModel(ctx context.Context, prompt, model
 
 // EvaluateReaction 评估反应
 func (c *TALClient) EvaluateReaction(ctx context.Context, userResponse, scenario, expectedStyle string) (*ReactionEvaluation, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请评估用户的反应表现：
-
-用户反应：%s
-场景：%s
-期望风格：%s
-
-请从以下维度评估：
-1. 内容质量（逻辑性、相关性）
-2. 风格符合度（是否符合期望风格）
-3. 反应速度（思考-反应的时间合理性）
-4. 沟通效果（说服力、感染力）
-5. 改进建议
-
-返回JSON格式的评估结果。`, userResponse, scenario, expectedStyle)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result ReactionEvaluation
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	return &result, nil
+	return c.tasks.EvaluateReaction(ctx, userResponse, scenario, expectedStyle), nil
 }
@@ -16,6 +16,9 @@ type Config struct {
 	// 默认服务商
 	DefaultProvider string `json:"defaultProvider" yaml:"defaultProvider"`
 
+	// 提示词模板覆盖目录，目录中的同名.tmpl文件会替换内置模板
+	PromptDir string `json:"promptDir" yaml:"promptDir"`
+
 	// OpenAI兼容服务配置
 	OpenAI OpenAIConfig `json:"openai" yaml:"openai"`
 
@@ -105,6 +108,9 @@ type ModelMapping struct {
 	VoiceInteraction  string `json:"voiceInteraction" yaml:"voiceInteraction"`
 	VideoAnalysis     string `json:"videoAnalysis" yaml:"videoAnalysis"`
 	VideoGeneration   string `json:"videoGeneration" yaml:"videoGeneration"`
+	Transcription     string `json:"transcription" yaml:"transcription"`
+	Speech            string `json:"speech" yaml:"speech"`
+	Embedding         string `json:"embedding" yaml:"embedding"`
 }
 
 // ProviderType AI服务商类型
@@ -124,6 +130,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		AIMode:          "internal",              // 默认对内模式
 		DefaultProvider: string(ProviderTAL),     // 默认使用TAL内部服务
+		PromptDir:       "config/prompts",
 		TAL: TALConfig{
 			BaseURL:     "http://ai-service.tal.com/openai-compatible/v1",
 			Timeout:     30,
@@ -136,6 +143,9 @@ func DefaultConfig() *Config {
 				VoiceInteraction:  "gpt-4o",           // GPT-4o支持多模态
 				VideoAnalysis:     "gpt-4o",           // GPT-4o支持多模态
 				VideoGeneration:   "doubao-pro-128k",  // Doubao模型支持
+				Transcription:     "whisper-1",        // 语音转文字
+				Speech:            "tts-1",            // 语音合成
+				Embedding:         "text-embedding-3-small", // 文本向量化
 			},
 		},
 		OpenAI: OpenAIConfig{
@@ -148,6 +158,9 @@ func DefaultConfig() *Config {
 				TextGeneration:    "gpt-4",
 				AdvancedReasoning: "gpt-4",
 				VoiceInteraction:  "gpt-4o",
+				Transcription:     "whisper-1",
+				Speech:            "tts-1",
+				Embedding:         "text-embedding-3-small",
 			},
 		},
 		Claude: ClaudeConfig{
@@ -232,6 +245,10 @@ func validateAIConfig(config *Config) error {
 
 // loadFromEnv 从环境变量加载配置
 func loadFromEnv(config *Config) {
+	if promptDir := os.Getenv("PROMPT_DIR"); promptDir != "" {
+		config.PromptDir = promptDir
+	}
+
 	// TAL配置
 	if talAppID := os.Getenv("TAL_MLOPS_APP_ID"); talAppID != "" {
 		config.TAL.TAL_MLOPS_APP_ID = talAppID
@@ -350,6 +367,12 @@ func (c *Config) GetModelForTask(provider ProviderType, task string) string {
 		return models.VideoAnalysis
 	case "video_generation":
 		return models.VideoGeneration
+	case "transcription":
+		return models.Transcription
+	case "speech":
+		return models.Speech
+	case "embedding":
+		return models.Embedding
 	default:
 		return models.TextGeneration // 默认使用文本生成模型
 	}
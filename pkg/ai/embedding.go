@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Embedder 文本向量化接口
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder 调用OpenAI兼容的/embeddings接口
+type OpenAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIEmbedder 创建向量化器，model为空时使用text-embedding-3-small
+func NewOpenAIEmbedder(client *openai.Client, model string) *OpenAIEmbedder {
+	if model == "" {
+		model = string(openai.SmallEmbedding3)
+	}
+	return &OpenAIEmbedder{client: client, model: model}
+}
+
+// Embed 批量向量化文本，返回的向量与输入顺序一致
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("文本向量化失败: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("文本向量化返回了%d个向量，期望%d个", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("文本向量化返回了无效的序号: %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// Embedder 返回使用TAL向量化接口的向量化器
+func (c *TALClient) Embedder() Embedder {
+	return NewOpenAIEmbedder(c.client, c.config.Models.Embedding)
+}
+
+// Embedder 返回使用OpenAI向量化接口的向量化器
+func (c *OpenAIClient) Embedder() Embedder {
+	return NewOpenAIEmbedder(c.client, c.config.Models.Embedding)
+}
+
+// Embedder 获取向量化器，优先使用默认服务商，都不支持时返回nil
+func (m *Manager) Embedder() Embedder {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	type embedding interface {
+		Embedder() Embedder
+	}
+	if client, ok := m.client.(embedding); ok {
+		return client.Embedder()
+	}
+	for _, provider := range m.config.GetAvailableProviders() {
+		if client, ok := m.providers[provider].(embedding); ok {
+			return client.Embedder()
+		}
+	}
+	return nil
+}
@@ -135,6 +135,7 @@ func (h *AIErrorHandler) defaultImageAnalysis() *ImageAnalysisResult {
 		Confidence:     0.5,
 		KeyFeatures:    []string{"模拟分析"},
 		ScientificName: "未知",
+		Output:         degradedOutput(),
 	}
 }
 
@@ -156,6 +157,7 @@ func (h *AIErrorHandler) defaultPolishedNote() *PolishedNote {
 		KeyPoints:   []string{"记录已保存"},
 		Questions:   []string{"稍后重试AI分析"},
 		FormattedText: "原始内容已保存",
+		Output:        degradedOutput(),
 	}
 }
 
@@ -187,6 +189,7 @@ func (h *AIErrorHandler) defaultStyleAnalysis() *StyleAnalysis {
 		},
 		OverallScore: 7.0,
 		StyleTags:    []string{"分析中"},
+		Output:       degradedOutput(),
 	}
 }
 
@@ -205,6 +208,7 @@ func (h *AIErrorHandler) defaultDebateSimulation() *DebateSimulation {
 		KeyReactionPoints: []string{"关键点"},
 		StyleSuggestions:  []string{"专业回应"},
 		Difficulty:        1,
+		Output:            degradedOutput(),
 	}
 }
 
@@ -233,9 +237,15 @@ func (h *AIErrorHandler) defaultReactionEvaluation() *ReactionEvaluation {
 		OverallScore: 7.0,
 		Strengths:     []string{"基础扎实"},
 		Improvements:  []string{"细节优化"},
+		Output:        degradedOutput(),
 	}
 }
 
+// degradedOutput 熔断降级时的结果来源信息
+func degradedOutput() *OutputInfo {
+	return &OutputInfo{Source: OutputFallback, Error: "AI服务暂时不可用"}
+}
+
 func (h *AIErrorHandler) defaultResponse() interface{} {
 	return map[string]string{
 		"status":  "degraded",
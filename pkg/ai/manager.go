@@ -3,8 +3,13 @@ package ai
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
+
+	"reactedge/internal/i18n"
+	"reactedge/pkg/audio"
+	"reactedge/pkg/prompt"
 )
 
 // Manager AI服务管理器
@@ -15,6 +20,7 @@ type Manager struct {
 	providers   map[ProviderType]Client
 	errorHandler *AIErrorHandler
 	circuitBreaker *AICircuitBreaker
+	adaptive    *AdaptiveEngine
 	mutex       sync.RWMutex
 }
 
@@ -29,6 +35,11 @@ func NewManager(configPath string) (*Manager, error) {
 		return nil, fmt.Errorf("AI配置验证失败: %w", err)
 	}
 
+	// 加载提示词模板覆盖，失败时继续使用内置模板
+	if err := prompt.Default().SetOverrideDir(config.PromptDir); err != nil {
+		fmt.Printf("⚠️ 加载提示词模板失败: %v\n", err)
+	}
+
 	// 创建AI工厂
 	factory := NewAIFactory(config)
 
@@ -42,6 +53,7 @@ func NewManager(configPath string) (*Manager, error) {
 		providers:      make(map[ProviderType]Client),
 		errorHandler:   errorHandler,
 		circuitBreaker: circuitBreaker,
+		adaptive:       NewAdaptiveEngine(),
 	}
 
 	// 初始化可用的AI客户端
@@ -142,6 +154,65 @@ func (m *Manager) GetAvailableProviders() []ProviderType {
 	return providers
 }
 
+// GetOutputStats 获取各服务商的结构化输出统计
+func (m *Manager) GetOutputStats() map[ProviderType]map[string]OutputCounter {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats := make(map[ProviderType]map[string]OutputCounter)
+	for provider, client := range m.providers {
+		if reporter, ok := client.(interface {
+			OutputStats() map[string]OutputCounter
+		}); ok {
+			stats[provider] = reporter.OutputStats()
+		}
+	}
+	return stats
+}
+
+// Transcriber 获取语音转写器，优先使用默认服务商，都不支持时返回nil
+func (m *Manager) Transcriber() audio.Transcriber {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	type transcribing interface {
+		Transcriber() audio.Transcriber
+	}
+	if client, ok := m.client.(transcribing); ok {
+		return client.Transcriber()
+	}
+	for _, provider := range m.config.GetAvailableProviders() {
+		if client, ok := m.providers[provider].(transcribing); ok {
+			return client.Transcriber()
+		}
+	}
+	return nil
+}
+
+// Synthesizer 获取语音合成器，优先使用默认服务商，都不支持时返回nil
+func (m *Manager) Synthesizer() audio.Synthesizer {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	type synthesizing interface {
+		Synthesizer() audio.Synthesizer
+	}
+	if client, ok := m.client.(synthesizing); ok {
+		return client.Synthesizer()
+	}
+	for _, provider := range m.config.GetAvailableProviders() {
+		if client, ok := m.providers[provider].(synthesizing); ok {
+			return client.Synthesizer()
+		}
+	}
+	return nil
+}
+
+// Prompts 获取提示词模板库
+func (m *Manager) Prompts() *prompt.Library {
+	return prompt.Default()
+}
+
 // GetConfig 获取配置
 func (m *Manager) GetConfig() *Config {
 	return m.config
@@ -189,9 +260,22 @@ func (m *Manager) PolishNote(ctx context.Context, rawContent, contextInfo string
 	return m.client.PolishNote(ctx, rawContent, contextInfo)
 }
 
-// TextToSpeech 文字转语音（使用默认客户端）
+// TextToSpeech 文字转语音，默认服务商不支持时使用其他支持语音合成的服务商
 func (m *Manager) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	return m.client.TextToSpeech(ctx, text, voice, language, speed)
+	speech, err := m.Synthesize(ctx, audio.SpeechRequest{Text: text, Voice: voice, Speed: speed})
+	if err != nil {
+		return nil, "", err
+	}
+	return speech.Data, string(speech.Format), nil
+}
+
+// Synthesize 按请求的音色、语速和格式合成语音
+func (m *Manager) Synthesize(ctx context.Context, req audio.SpeechRequest) (*audio.Speech, error) {
+	synthesizer := m.Synthesizer()
+	if synthesizer == nil {
+		return nil, audio.ErrNoSynthesizer
+	}
+	return synthesizer.Synthesize(ctx, req)
 }
 
 // AnalyzeVideo 视频分析（使用默认客户端）
@@ -219,70 +303,104 @@ func (m *Manager) SimulateDebate(ctx context.Context, scenario string, difficult
 	return m.client.SimulateDebate(ctx, scenario, difficulty, userStyle)
 }
 
-// EvaluateReaction 评估反应（使用默认客户端）
+// EvaluateReaction 评估反应（使用默认客户端），并附加本地风格分类结果
 func (m *Manager) EvaluateReaction(ctx context.Context, userResponse, scenario, expectedStyle string) (*ReactionEvaluation, error) {
-	return m.client.EvaluateReaction(ctx, userResponse, scenario, expectedStyle)
+	evaluation, err := m.client.EvaluateReaction(ctx, userResponse, scenario, expectedStyle)
+	if err != nil {
+		return nil, err
+	}
+	applyLocalStyle(evaluation, userResponse, expectedStyle)
+	return evaluation, nil
 }
 
 // ReactEdge增强功能
 
+// Adaptive 获取自适应难度引擎
+func (m *Manager) Adaptive() *AdaptiveEngine {
+	return m.adaptive
+}
+
+// EvaluateUserReaction 评估用户反应并记录到该用户的能力画像
+func (m *Manager) EvaluateUserReaction(ctx context.Context, userID, userResponse, scenario, expectedStyle string, difficulty int) (*ReactionEvaluation, *SkillProfile, error) {
+	evaluation, err := m.EvaluateReaction(ctx, userResponse, scenario, expectedStyle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 默认评估结果不是真实评分，不计入能力画像
+	if evaluation.Output.IsFallback() {
+		return evaluation, m.adaptive.GetProfile(userID), nil
+	}
+
+	profile, err := m.adaptive.RecordEvaluation(userID, scenario, difficulty, evaluation)
+	if err != nil {
+		return evaluation, nil, err
+	}
+
+	return evaluation, profile, nil
+}
+
 // GeneratePersonalizedTraining 生成个性化训练计划
+// userProfile中的user_id用于查找评估历史；有历史时按薄弱维度生成计划，否则按currentLevel生成各维度的基线测评计划
+// 计划文本使用ctx中的回答语言
 func (m *Manager) GeneratePersonalizedTraining(ctx context.Context, userProfile map[string]interface{}, currentLevel int) (*PersonalizedTraining, error) {
+	userID, _ := userProfile["user_id"].(string)
+	locale := prompt.LanguageFromContext(ctx)
+
+	profile := m.adaptive.GetProfile(userID)
+	if profile == nil || profile.Attempts == 0 {
+		if currentLevel <= 0 {
+			currentLevel = 1
+		}
+		return m.getDefaultPersonalizedTraining(userID, currentLevel, locale), nil
+	}
+
+	weakest := profile.WeakestDimensions()
+	averages := profile.AverageScores(10)
+
+	mainFocus := []string{}
+	scenarios := []string{}
+	outcomes := []string{}
+	for _, dim := range weakest[:2] {
+		mainFocus = append(mainFocus, DimensionLabel(dim, locale))
+		scenarios = append(scenarios, scenariosFor(dim, 2)...)
+		current := averages[dim]
+		outcomes = append(outcomes, i18n.T(locale, "training.outcome.improve", DimensionLabel(dim, locale), current, math.Min(10, current+1)))
+	}
+
+	level := profile.Level()
+	if currentLevel > 0 && currentLevel != level {
+		fmt.Printf("📈 用户%s的自评等级为%d，根据评估历史估算为%d\n", userID, currentLevel, level)
+	}
 
-	// 这里简化为基于问题的训练计划
 	training := &PersonalizedTraining{
-		UserLevel:      currentLevel,
-		MainFocus:      []string{"反应速度", "内容质量", "风格适应"},
-		RecommendedScenarios: []string{"述职答辩", "分享会提问", "争辩冲突"},
-		WeeklyPlan:     m.generateWeeklyPlan(currentLevel),
-		ExpectedOutcomes: []string{"提升反应速度20%", "增强内容逻辑性", "掌握多种沟通风格"},
+		UserLevel:            level,
+		MainFocus:            mainFocus,
+		RecommendedScenarios: scenarios,
+		WeeklyPlan:           m.adaptive.GenerateWeeklyPlan(userID, locale),
+		ExpectedOutcomes:     outcomes,
+		NextChallenge:        m.adaptive.NextChallenge(userID, locale),
 	}
 
 	return training, nil
 }
 
-// generateWeeklyPlan 生成每周训练计划
-func (m *Manager) generateWeeklyPlan(level int) []WeeklySession {
-	sessions := []WeeklySession{}
-
-	switch level {
-	case 1:
-		sessions = []WeeklySession{
-			{Day: 1, Focus: "基础反应训练", Duration: 15, Scenarios: []string{"简单问答"}},
-			{Day: 2, Focus: "风格适应训练", Duration: 20, Scenarios: []string{"正式场合"}},
-			{Day: 3, Focus: "压力模拟训练", Duration: 15, Scenarios: []string{"时间限制"}},
-			{Day: 4, Focus: "反馈分析", Duration: 10, Scenarios: []string{"自我评估"}},
-			{Day: 5, Focus: "综合训练", Duration: 25, Scenarios: []string{"混合场景"}},
-		}
-	case 2:
-		sessions = []WeeklySession{
-			{Day: 1, Focus: "高级反应训练", Duration: 20, Scenarios: []string{"复杂问题"}},
-			{Day: 2, Focus: "多风格切换", Duration: 25, Scenarios: []string{"不同场合"}},
-			{Day: 3, Focus: "辩论模拟", Duration: 30, Scenarios: []string{"观点冲突"}},
-			{Day: 4, Focus: "实时反馈", Duration: 15, Scenarios: []string{"AI评估"}},
-			{Day: 5, Focus: "挑战训练", Duration: 35, Scenarios: []string{"高难度场景"}},
-		}
-	default:
-		sessions = []WeeklySession{
-			{Day: 1, Focus: "专家级训练", Duration: 30, Scenarios: []string{"专业辩论"}},
-			{Day: 2, Focus: "危机应对", Duration: 35, Scenarios: []string{"紧急情况"}},
-			{Day: 3, Focus: "领导沟通", Duration: 30, Scenarios: []string{"高层对话"}},
-			{Day: 4, Focus: "公众演讲", Duration: 40, Scenarios: []string{"大型会议"}},
-			{Day: 5, Focus: "大师挑战", Duration: 45, Scenarios: []string{"终极考验"}},
-		}
+// getDefaultPersonalizedTraining 无评估记录时的个性化训练计划：按等级测评各维度以建立能力基线
+func (m *Manager) getDefaultPersonalizedTraining(userID string, level int, locale string) *PersonalizedTraining {
+	mainFocus := []string{}
+	scenarios := []string{}
+	for _, dim := range AllDimensions {
+		mainFocus = append(mainFocus, DimensionLabel(dim, locale))
+		scenarios = append(scenarios, scenariosFor(dim, 1)...)
 	}
 
-	return sessions
-}
-
-// getDefaultPersonalizedTraining 默认个性化训练计划
-func (m *Manager) getDefaultPersonalizedTraining(userProfile map[string]interface{}, level int) *PersonalizedTraining {
 	return &PersonalizedTraining{
-		UserLevel:      level,
-		MainFocus:      []string{"反应速度提升", "内容质量优化", "风格适应性"},
-		RecommendedScenarios: []string{"述职报告", "分享会", "团队讨论"},
-		WeeklyPlan:     m.generateWeeklyPlan(level),
-		ExpectedOutcomes: []string{"显著提升临场反应能力", "增强沟通自信心", "掌握多种应对策略"},
+		UserLevel:            clampDifficulty(level),
+		MainFocus:            mainFocus,
+		RecommendedScenarios: scenarios,
+		WeeklyPlan:           BaselinePlan(level, locale),
+		ExpectedOutcomes:     []string{i18n.T(locale, "training.outcome.baseline"), i18n.T(locale, "training.outcome.targeted")},
+		NextChallenge:        m.adaptive.NextChallenge(userID, locale),
 	}
 }
 
@@ -295,12 +413,15 @@ type PersonalizedTraining struct {
 	RecommendedScenarios []string       `json:"recommended_scenarios"`
 	WeeklyPlan          []WeeklySession `json:"weekly_plan"`
 	ExpectedOutcomes    []string        `json:"expected_outcomes"`
+	NextChallenge       *ChallengeRecommendation `json:"next_challenge,omitempty"`
 }
 
 // WeeklySession 每周训练 session
 type WeeklySession struct {
-	Day       int      `json:"day"`
-	Focus     string   `json:"focus"`
-	Duration  int      `json:"duration"` // 分钟
-	Scenarios []string `json:"scenarios"`
+	Day             int               `json:"day"`
+	Focus           string            `json:"focus"`
+	Duration        int               `json:"duration"` // 分钟
+	Scenarios       []string          `json:"scenarios"`
+	Difficulty      int               `json:"difficulty,omitempty"`       // 1-5
+	TargetDimension TrainingDimension `json:"target_dimension,omitempty"`
 }
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+
+	"reactedge/pkg/audio"
 )
 
 // OpenAIClient OpenAI客户端
@@ -17,6 +19,7 @@ type OpenAIClient struct {
 	*BaseClient
 	config *OpenAIConfig
 	client *openai.Client
+	tasks  *structuredTasks
 }
 
 // NewOpenAIClient 创建OpenAI客户端
@@ -26,13 +29,31 @@ func NewOpenAIClient(config OpenAIConfig) (*OpenAIClient, error) {
 
 	client := openai.NewClientWithConfig(openaiConfig)
 
-	return &OpenAIClient{
+	openaiClient := &OpenAIClient{
 		BaseClient: &BaseClient{
 			provider: ProviderOpenAI,
 		},
 		config: &config,
 		client: client,
-	}, nil
+	}
+	openaiClient.tasks = newStructuredTasks(newStructuredGenerator(client, formatJSONSchema), openaiClient.GetModelForTask, config.MaxTokens, config.Temperature)
+
+	return openaiClient, nil
+}
+
+// OutputStats 返回结构化输出统计
+func (c *OpenAIClient) OutputStats() map[string]OutputCounter {
+	return c.tasks.Stats()
+}
+
+// Transcriber 返回使用OpenAI语音转写接口的转写器
+func (c *OpenAIClient) Transcriber() audio.Transcriber {
+	return audio.NewOpenAITranscriber(c.client, c.config.Models.Transcription)
+}
+
+// Synthesizer 返回使用OpenAI语音合成接口的合成器
+func (c *OpenAIClient) Synthesizer() audio.Synthesizer {
+	return audio.NewOpenAISynthesizer(c.client, c.config.Models.Speech)
 }
 
 // OpenAI客户端方法
@@ -81,207 +102,19 @@ func (c *OpenAIClient) GenerateResponseWithModel(ctx context.Context, prompt, mo
 }
 
 func (c *OpenAIClient) AnalyzeImage(ctx context.Context, imageURL, prompt string) (*ImageAnalysisResult, error) {
-	model := c.GetModelForTask("image_analysis")
-
-	contentParts := []openai.ChatMessagePart{
-		{
-			Type: openai.ChatMessagePartTypeText,
-			Text: prompt,
-		},
-		{
-			Type: openai.ChatMessagePartTypeImageURL,
-			ImageURL: &openai.ChatMessageImageURL{
-				URL: imageURL,
-			},
-		},
-	}
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:         openai.ChatMessageRoleUser,
-				MultiContent: contentParts,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultImageAnalysis(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultImageAnalysis(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	result := &ImageAnalysisResult{
-		ObjectName:     extractObjectName(content),
-		Category:       extractCategory(content),
-		Description:    content,
-		Confidence:     0.95,
-		KeyFeatures:    extractKeyFeatures(content),
-		ScientificName: extractScientificName(content),
-	}
-
-	return result, nil
+	return c.tasks.AnalyzeImage(ctx, imageURL, prompt), nil
 }
 
 func (c *OpenAIClient) GenerateQuestions(ctx context.Context, contextInfo string, category string) ([]Question, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`基于以下信息为用户生成3个引导性的反应训练问题：
-
-上下文信息：%s
-训练类别：%s
-
-要求：
-1. 问题要适合职场沟通场景
-2. 问题要激发思考和反应能力
-3. 问题难度要循序渐进（从简单到深入）
-4. 每个问题都要有明确的类型标注
-5. 确保所有内容适合职场培训场景
-
-请以JSON格式返回，包含以下字段：
-- content: 问题内容
-- type: 问题类型（scenario场景, strategy策略, evaluation评估）
-- difficulty: 难度（basic基本, intermediate中级, advanced高级）
-- purpose: 问题目的说明`, contextInfo, category)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "你是一个职场沟通训练助手，专门为用户设计反应训练问题。请以JSON格式返回包含questions数组的结果。",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultQuestions(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultQuestions(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-	questions := parseQuestionsFromJSON(content)
-	if len(questions) == 0 {
-		return getDefaultQuestions(), nil
-	}
-
-	return questions, nil
+	return c.tasks.GenerateQuestions(ctx, contextInfo, category), nil
 }
 
 func (c *OpenAIClient) PolishNote(ctx context.Context, rawContent, contextInfo string) (*PolishedNote, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`请帮用户润色他们的反应训练记录，让它更清晰、有逻辑性。
-
-原始内容：%s
-
-上下文信息：%s
-
-要求：
-1. 保持用户的原意和表达特色
-2. 让表达更清晰准确
-3. 添加适当的沟通技巧解释
-4. 指出可能的改进方向
-5. 确保所有内容适合职场培训场景
-
-请严格按照以下JSON格式返回结果：
-
-{
-  "title": "记录标题",
-  "summary": "内容总结",
-  "key_points": ["关键要点1", "关键要点2"],
-  "communication_tips": ["沟通技巧1"],
-  "questions": ["问题1"],
-  "improvements": ["改进建议1"],
-  "formatted_text": "格式化的文本内容"
-}
-
-请确保返回的是有效的JSON格式。`, rawContent, contextInfo)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultPolishedNote(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultPolishedNote(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// 处理markdown格式的JSON代码块
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	var jsonResult PolishedNote
-	if err := json.Unmarshal([]byte(jsonContent), &jsonResult); err != nil {
-		jsonResult = PolishedNote{
-			Title:       "反应训练记录",
-			Summary:     extractSummaryFromText(jsonContent),
-			FormattedText: jsonContent,
-			KeyPoints:   extractKeyPointsFromText(jsonContent),
-		}
-	}
-
-	// 确保必需字段有值
-	if jsonResult.Title == "" {
-		jsonResult.Title = "反应训练记录"
-	}
-	if jsonResult.Summary == "" {
-		jsonResult.Summary = "这是反应训练的记录总结"
-	}
-	if len(jsonResult.KeyPoints) == 0 {
-		jsonResult.KeyPoints = []string{"记录了训练过程", "总结了经验教训"}
-	}
-	if jsonResult.FormattedText == "" {
-		jsonResult.FormattedText = content
-	}
-
-	return &jsonResult, nil
+	return c.tasks.PolishNote(ctx, rawContent, contextInfo), nil
 }
 
 func (c *OpenAIClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	// OpenAI TTS 简化实现
-	return getDefaultAudioData(), "wav", nil
+	return synthesize(ctx, c.Synthesizer(), text, voice, speed)
 }
 
 func (c *OpenAIClient) AnalyzeVideo(ctx context.Context, videoData []byte, format, analysisType string, duration float64) (*VideoAnalysis, error) {
@@ -293,253 +126,19 @@ func (c *OpenAIClient) GenerateVideo(ctx context.Context, script, style string,
 }
 
 func (c *OpenAIClient) GenerateReactionTemplates(ctx context.Context, scenario, style string) ([]ReactionTemplate, error) {
-	model := c.GetModelForTask("text_generation")
-
-	prompt := fmt.Sprintf(`基于以下场景和风格，为用户生成临场反应训练模板：
-
-场景：%s
-风格：%s
-
-要求：
-1. 生成3-5个实用的反应模板
-2. 每个模板包含触发情境、反应步骤、关键话术
-3. 模板要贴合职场实际场景
-4. 风格要符合指定的沟通风格
-
-请以JSON格式返回，包含templates数组，每个模板包含：
-- scenario: 触发情境
-- steps: 反应步骤数组
-- key_phrases: 关键话术数组
-- style_notes: 风格要点`, scenario, style)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "你是一个职场沟通教练，专门设计临场反应训练模板。",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	// 解析JSON响应
-	var result struct {
-		Templates []ReactionTemplate `json:"templates"`
-	}
-
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultReactionTemplates(), nil
-	}
-
-	return result.Templates, nil
+	return c.tasks.GenerateReactionTemplates(ctx, scenario, style), nil
 }
 
 func (c *OpenAIClient) AnalyzeExpressionStyle(ctx context.Context, personName string, sampleText string) (*StyleAnalysis, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请分析%s的表达风格：
-
-样本文本：%s
-
-请从以下维度进行分析：
-1. 语言特点（词汇、句式、修辞手法）
-2. 思维模式（逻辑结构、论证方式）
-3. 沟通策略（立场表达、冲突处理）
-4. 个人特色（独特标识、风格标签）
-
-请返回JSON格式的分析结果。`, personName, sampleText)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result StyleAnalysis
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultStyleAnalysis(), nil
-	}
-
-	return &result, nil
+	return c.tasks.AnalyzeExpressionStyle(ctx, personName, sampleText), nil
 }
 
 func (c *OpenAIClient) SimulateDebate(ctx context.Context, scenario string, difficulty int, userStyle string) (*DebateSimulation, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请模拟一个%s场景的辩论训练：
-
-场景：%s
-难度等级：%d
-用户风格：%s
-
-请生成：
-1. 对手的开场陈述
-2. 3轮交互对话
-3. 关键的反应机会点
-4. 风格适配建议
-
-返回JSON格式的结果。`, scenario, scenario, difficulty, userStyle)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result DebateSimulation
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultDebateSimulation(), nil
-	}
-
-	return &result, nil
+	return c.tasks.SimulateDebate(ctx, scenario, difficulty, userStyle), nil
 }
 
 func (c *OpenAIClient) EvaluateReaction(ctx context.Context, userResponse, scenario, expectedStyle string) (*ReactionEvaluation, error) {
-	model := c.GetModelForTask("advanced_reasoning")
-
-	prompt := fmt.Sprintf(`请评估用户的反应表现：
-
-用户反应：%s
-场景：%s
-期望风格：%s
-
-请从以下维度评估：
-1. 内容质量（逻辑性、相关性）
-2. 风格符合度（是否符合期望风格）
-3. 反应速度（思考-反应的时间合理性）
-4. 沟通效果（说服力、感染力）
-5. 改进建议
-
-返回JSON格式的评估结果。`, userResponse, scenario, expectedStyle)
-
-	req := openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: prompt,
-			},
-		},
-		MaxTokens:   c.config.MaxTokens,
-		Temperature: c.config.Temperature,
-	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	if len(resp.Choices) == 0 {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	content := resp.Choices[0].Message.Content
-
-	var result ReactionEvaluation
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return getDefaultReactionEvaluation(), nil
-	}
-
-	return &result, nil
+	return c.tasks.EvaluateReaction(ctx, userResponse, scenario, expectedStyle), nil
 }
 
 func (c *OpenAIClient) GetModelForTask(task string) string {
@@ -739,11 +338,6 @@ func (c *OpenAIClient) getDefaultReactionEvaluation() *ReactionEvaluation {
 	}
 }
 
-func (c *OpenAIClient) getDefaultAudioData(text string) []byte {
-	// 模拟音频数据
-	return []byte("mock-audio-data-" + text)
-}
-
 // ClaudeClient Claude客户端（占位符实现）
 type ClaudeClient struct {
 	*BaseClient
@@ -789,7 +383,7 @@ func (c *ClaudeClient) PolishNote(ctx context.Context, rawContent, contextInfo s
 }
 
 func (c *ClaudeClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	return getDefaultAudioData(), "wav", nil
+	return nil, "", fmt.Errorf("Claude%w", ErrSpeechNotSupported)
 }
 
 func (c *ClaudeClient) AnalyzeVideo(ctx context.Context, videoData []byte, format, analysisType string, duration float64) (*VideoAnalysis, error) {
@@ -924,22 +518,6 @@ func (c *ClaudeClient) getDefaultReactionEvaluation() *ReactionEvaluation {
 	}
 }
 
-func (c *ClaudeClient) getDefaultAudioData(text string) []byte {
-	wavHeader := []byte{
-		0x52, 0x49, 0x46, 0x46, 0x24, 0x08, 0x00, 0x00, 0x57, 0x41, 0x56, 0x45,
-		0x66, 0x6D, 0x74, 0x20, 0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
-		0x80, 0x3E, 0x00, 0x00, 0x80, 0x3E, 0x00, 0x00, 0x02, 0x00, 0x10, 0x00,
-		0x64, 0x61, 0x74, 0x61, 0x00, 0x08, 0x00, 0x00,
-	}
-
-	audioData := make([]byte, 2048)
-	for i := range audioData {
-		audioData[i] = byte((i * 37) % 256)
-	}
-
-	return append(wavHeader, audioData...)
-}
-
 func (c *ClaudeClient) generateMockMP4Data(script string, metadata *VideoMetadata) []byte {
 	ftypBox := []byte{
 		0x00, 0x00, 0x00, 0x20, 0x66, 0x74, 0x79, 0x70,
@@ -1041,7 +619,7 @@ func (c *AzureClient) PolishNote(ctx context.Context, rawContent, contextInfo st
 }
 
 func (c *AzureClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	return c.getDefaultAudioData(), "wav", nil
+	return nil, "", fmt.Errorf("Azure%w", ErrSpeechNotSupported)
 }
 
 func (c *AzureClient) AnalyzeVideo(ctx context.Context, videoData []byte, format, analysisType string, duration float64) (*VideoAnalysis, error) {
@@ -1106,22 +684,6 @@ func (c *AzureClient) EvaluateReaction(ctx context.Context, userResponse, scenar
 	}, nil
 }
 
-func (c *AzureClient) getDefaultAudioData() []byte {
-	wavHeader := []byte{
-		0x52, 0x49, 0x46, 0x46, 0x24, 0x08, 0x00, 0x00, 0x57, 0x41, 0x56, 0x45,
-		0x66, 0x6D, 0x74, 0x20, 0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
-		0x80, 0x3E, 0x00, 0x00, 0x80, 0x3E, 0x00, 0x00, 0x02, 0x00, 0x10, 0x00,
-		0x64, 0x61, 0x74, 0x61, 0x00, 0x08, 0x00, 0x00,
-	}
-
-	audioData := make([]byte, 2048)
-	for i := range audioData {
-		audioData[i] = byte((i * 37) % 256)
-	}
-
-	return append(wavHeader, audioData...)
-}
-
 func (c *AzureClient) generateMockVideo(script, style string, duration float64, scenes []string, voice, language string) ([]byte, string, float64, *VideoMetadata, error) {
 	mockVideoData := c.generateMockMP4Data(script, &VideoMetadata{
 		Title:         "Azure生成的演示视频",
@@ -1241,7 +803,7 @@ func (c *BaiduClient) PolishNote(ctx context.Context, rawContent, contextInfo st
 }
 
 func (c *BaiduClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	return c.getDefaultAudioData(), "wav", nil
+	return nil, "", fmt.Errorf("百度%w", ErrSpeechNotSupported)
 }
 
 func (c *BaiduClient) AnalyzeVideo(ctx context.Context, videoData []byte, format, analysisType string, duration float64) (*VideoAnalysis, error) {
@@ -1306,22 +868,6 @@ func (c *BaiduClient) EvaluateReaction(ctx context.Context, userResponse, scenar
 	}, nil
 }
 
-func (c *BaiduClient) getDefaultAudioData() []byte {
-	wavHeader := []byte{
-		0x52, 0x49, 0x46, 0x46, 0x24, 0x08, 0x00, 0x00, 0x57, 0x41, 0x56, 0x45,
-		0x66, 0x6D, 0x74, 0x20, 0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
-		0x80, 0x3E, 0x00, 0x00, 0x80, 0x3E, 0x00, 0x00, 0x02, 0x00, 0x10, 0x00,
-		0x64, 0x61, 0x74, 0x61, 0x00, 0x08, 0x00, 0x00,
-	}
-
-	audioData := make([]byte, 2048)
-	for i := range audioData {
-		audioData[i] = byte((i * 37) % 256)
-	}
-
-	return append(wavHeader, audioData...)
-}
-
 func (c *BaiduClient) generateMockVideo(script, style string, duration float64, scenes []string, voice, language string) ([]byte, string, float64, *VideoMetadata, error) {
 	mockVideoData := c.generateMockMP4Data(script, &VideoMetadata{
 		Title:         "百度生成的演示视频",
@@ -1513,9 +1059,9 @@ func (c *SparkClient) PolishNote(ctx context.Context, rawContent, contextInfo st
 	return getDefaultPolishedNote(), nil
 }
 
-// TextToSpeech 文本转语音（星火AI不支持，返回默认结果）
+// TextToSpeech 文本转语音（星火AI不支持）
 func (c *SparkClient) TextToSpeech(ctx context.Context, text, voice, language string, speed float64) ([]byte, string, error) {
-	return getDefaultAudioData(), "audio/wav", nil
+	return nil, "", fmt.Errorf("星火%w", ErrSpeechNotSupported)
 }
 
 // AnalyzeVideo 视频分析（星火AI不支持，返回默认结果）
@@ -0,0 +1,328 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema JSON Schema描述，只覆盖结果类型用到的子集
+// 结构体字段支持以下标签：
+//   - desc:"说明"      字段说明，会提供给模型
+//   - range:"0,10"     数值范围
+//   - enum:"a|b|c"     可选值
+//   - schema:"-"       不纳入Schema（例如结果来源等本地字段）
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// MarshalJSON 实现json.Marshaler，便于直接作为response_format的schema
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// String 返回缩进格式的Schema文本，用于写入提示词
+func (s *Schema) String() string {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(raw)
+}
+
+var (
+	schemaCache = make(map[reflect.Type]*Schema)
+	schemaMutex sync.Mutex
+)
+
+// SchemaFor 根据Go类型生成JSON Schema，结果按类型缓存
+func SchemaFor(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return &Schema{}
+	}
+
+	schemaMutex.Lock()
+	defer schemaMutex.Unlock()
+
+	if cached, ok := schemaCache[t]; ok {
+		return cached
+	}
+	schema := schemaForType(t, 0)
+	schemaCache[t] = schema
+	return schema
+}
+
+// schemaForType 递归生成Schema，深度限制用于防止自引用类型无限递归
+func schemaForType(t reflect.Type, depth int) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if depth > 8 {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), depth+1)}
+	case reflect.Map:
+		// 键只能是字符串，值为interface{}时不限制类型
+		schema := &Schema{Type: "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema.AdditionalProperties = schemaForType(t.Elem(), depth+1)
+		}
+		return schema
+	case reflect.Struct:
+		return structSchema(t, depth)
+	default:
+		// interface{}等任意类型
+		return &Schema{}
+	}
+}
+
+// structSchema 生成结构体的对象Schema
+func structSchema(t reflect.Type, depth int) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("schema") == "-" {
+			continue
+		}
+
+		name, omitempty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		prop := schemaForType(field.Type, depth+1)
+		if desc := field.Tag.Get("desc"); desc != "" || field.Tag.Get("range") != "" || field.Tag.Get("enum") != "" {
+			// 复制一份，避免修改共享的子Schema
+			copied := *prop
+			prop = &copied
+			prop.Description = desc
+			applyRange(prop, field.Tag.Get("range"))
+			if enum := field.Tag.Get("enum"); enum != "" {
+				prop.Enum = strings.Split(enum, "|")
+			}
+		}
+
+		schema.Properties[name] = prop
+		if !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// jsonFieldName 解析json标签
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// applyRange 解析range标签，格式为"最小值,最大值"
+func applyRange(schema *Schema, value string) {
+	if value == "" {
+		return
+	}
+	bounds := strings.SplitN(value, ",", 2)
+	if v, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64); err == nil {
+		schema.Minimum = &v
+	}
+	if len(bounds) == 2 {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64); err == nil {
+			schema.Maximum = &v
+		}
+	}
+}
+
+// SchemaError 模型输出不符合Schema
+type SchemaError struct {
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return "输出不符合Schema: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate 校验JSON数据是否符合Schema，返回的错误会列出所有问题
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("JSON解析失败: 顶层值之后还有多余内容")
+	}
+
+	var problems []string
+	s.validate("$", value, &problems)
+	if len(problems) > 0 {
+		// 问题太多时只保留前几条，便于反馈给模型
+		if len(problems) > 10 {
+			problems = append(problems[:10], fmt.Sprintf("以及其他%d处问题", len(problems)-10))
+		}
+		return &SchemaError{Problems: problems}
+	}
+	return nil
+}
+
+// validate 递归校验
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	if s == nil || s.Type == "" {
+		return
+	}
+
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			report("应为对象，实际为%s", jsonTypeName(value))
+			return
+		}
+		for _, name := range s.Required {
+			if _, exists := obj[name]; !exists {
+				report("缺少必填字段%q", name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prop, ok := s.Properties[key]; ok {
+				prop.validate(path+"."+key, obj[key], problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+key, obj[key], problems)
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			report("应为数组，实际为%s", jsonTypeName(value))
+			return
+		}
+		for i, item := range arr {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			report("应为字符串，实际为%s", jsonTypeName(value))
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			report("取值%q不在可选范围%v内", str, s.Enum)
+		}
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			report("应为数字，实际为%s", jsonTypeName(value))
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			report("数字格式错误: %s", num)
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				report("应为整数，实际为%s", num)
+			}
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			report("数值%s小于最小值%g", num, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			report("数值%s大于最大值%g", num, *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("应为布尔值，实际为%s", jsonTypeName(value))
+		}
+	}
+}
+
+// jsonTypeName 返回JSON值的类型名
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "对象"
+	case []interface{}:
+		return "数组"
+	case string:
+		return "字符串"
+	case json.Number:
+		return "数字"
+	case bool:
+		return "布尔值"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// containsString 判断切片是否包含字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// OutputSource 结构化结果的来源
+type OutputSource string
+
+const (
+	OutputModel    OutputSource = "model"    // 模型首次输出即有效
+	OutputRepaired OutputSource = "repaired" // 纠正重问后得到有效输出
+	OutputFallback OutputSource = "fallback" // 使用了本地默认结果
+)
+
+// OutputInfo 结构化结果的生成信息，调用方可据此判断是否使用了默认结果
+type OutputInfo struct {
+	Source   OutputSource `json:"source"`
+	Attempts int          `json:"attempts"`
+	Prompt   string       `json:"prompt,omitempty"` // 使用的提示词模板，格式为"名称@版本"
+	Error    string       `json:"error,omitempty"`
+}
+
+// IsFallback 是否使用了默认结果
+func (o *OutputInfo) IsFallback() bool {
+	return o != nil && o.Source == OutputFallback
+}
+
+// responseFormatMode 服务商支持的response_format类型
+type responseFormatMode int
+
+const (
+	formatNone       responseFormatMode = iota // 不支持，仅依赖提示词
+	formatJSONObject                           // 支持json_object
+	formatJSONSchema                           // 支持json_schema
+)
+
+// OutputCounter 某类任务的结构化输出统计
+type OutputCounter struct {
+	Model    int `json:"model"`
+	Repaired int `json:"repaired"`
+	Fallback int `json:"fallback"`
+}
+
+// structuredCall 一次结构化请求
+type structuredCall struct {
+	Name        string // Schema名称，同时作为统计的任务名
+	Template    string // 提示词模板标识
+	Model       string
+	System      string
+	Prompt      string
+	ImageURL    string // 可选，图像分析时附带图片
+	MaxTokens   int
+	Temperature float32
+}
+
+// structuredGenerator 负责请求模型、校验输出并在失败时重问一次
+type structuredGenerator struct {
+	client *openai.Client
+	mode   responseFormatMode
+	stats  map[string]*OutputCounter
+	mutex  sync.Mutex
+}
+
+// newStructuredGenerator 创建结构化输出生成器
+func newStructuredGenerator(client *openai.Client, mode responseFormatMode) *structuredGenerator {
+	return &structuredGenerator{
+		client: client,
+		mode:   mode,
+		stats:  make(map[string]*OutputCounter),
+	}
+}
+
+// Generate 请求模型并把结果解析到target（需为指向结构体的指针）
+// 返回的OutputInfo.Source为fallback时target内容不可用，调用方应改用默认结果
+func (g *structuredGenerator) Generate(ctx context.Context, call structuredCall, target interface{}) *OutputInfo {
+	schema := SchemaFor(target)
+	info := &OutputInfo{Prompt: call.Template}
+
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: strings.TrimSpace(call.System + "\n\n" + schemaInstruction(schema)),
+		},
+		userMessage(call),
+	}
+
+	// 首次请求 + 最多一次纠正重问
+	for attempt := 1; attempt <= 2; attempt++ {
+		info.Attempts = attempt
+
+		content, err := g.complete(ctx, call, schema, messages)
+		if err != nil {
+			info.Error = err.Error()
+			break
+		}
+
+		err = decodeStructured(content, schema, target)
+		if err == nil {
+			info.Source = OutputModel
+			if attempt > 1 {
+				info.Source = OutputRepaired
+			}
+			info.Error = ""
+			g.count(call.Name, info.Source)
+			return info
+		}
+
+		info.Error = err.Error()
+		fmt.Printf("⚠️ %s 输出校验失败（第%d次）: %v\n", call.Name, attempt, err)
+
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("上面的输出无效：%v\n请修正后重新输出，只返回符合Schema的JSON对象，不要包含任何解释或代码块标记。", err),
+			},
+		)
+	}
+
+	info.Source = OutputFallback
+	g.count(call.Name, OutputFallback)
+	return info
+}
+
+// Stats 返回各任务的结构化输出统计
+func (g *structuredGenerator) Stats() map[string]OutputCounter {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	stats := make(map[string]OutputCounter, len(g.stats))
+	for name, counter := range g.stats {
+		stats[name] = *counter
+	}
+	return stats
+}
+
+// complete 发送一次聊天请求
+func (g *structuredGenerator) complete(ctx context.Context, call structuredCall, schema *Schema, messages []openai.ChatCompletionMessage) (string, error) {
+	req := openai.ChatCompletionRequest{
+		Model:       call.Model,
+		Messages:    messages,
+		MaxTokens:   call.MaxTokens,
+		Temperature: call.Temperature,
+	}
+
+	switch g.mode {
+	case formatJSONSchema:
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   call.Name,
+				Schema: schema,
+			},
+		}
+	case formatJSONObject:
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	resp, err := g.client.CreateChatCompletion(ctx, req)
+	if err != nil && req.ResponseFormat != nil && isBadRequest(err) {
+		// 部分模型不支持response_format，去掉后仅依赖提示词约束
+		fmt.Printf("⚠️ 模型%s不支持response_format，改用提示词约束: %v\n", call.Model, err)
+		req.ResponseFormat = nil
+		resp, err = g.client.CreateChatCompletion(ctx, req)
+	}
+	if err != nil {
+		return "", fmt.Errorf("AI请求失败: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("AI未返回结果")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// isBadRequest 是否为400请求错误
+func isBadRequest(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == 400
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == 400
+	}
+	return false
+}
+
+// count 记录统计
+func (g *structuredGenerator) count(name string, source OutputSource) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	counter, ok := g.stats[name]
+	if !ok {
+		counter = &OutputCounter{}
+		g.stats[name] = counter
+	}
+	switch source {
+	case OutputModel:
+		counter.Model++
+	case OutputRepaired:
+		counter.Repaired++
+	case OutputFallback:
+		counter.Fallback++
+	}
+}
+
+// userMessage 构建用户消息，带图片时使用多段内容
+func userMessage(call structuredCall) openai.ChatCompletionMessage {
+	if call.ImageURL == "" {
+		return openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: call.Prompt}
+	}
+	return openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleUser,
+		MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: call.Prompt},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: call.ImageURL}},
+		},
+	}
+}
+
+// schemaInstruction 生成要求模型按Schema输出的说明
+func schemaInstruction(schema *Schema) string {
+	return "请只输出一个符合以下JSON Schema的JSON对象，不要输出解释、注释或代码块标记：\n" + schema.String()
+}
+
+// decodeStructured 提取、校验并解析模型输出
+func decodeStructured(content string, schema *Schema, target interface{}) error {
+	jsonContent := ExtractJSON(content)
+	if jsonContent == "" {
+		return fmt.Errorf("输出中没有找到JSON对象")
+	}
+	if err := schema.Validate([]byte(jsonContent)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(jsonContent), target); err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	return nil
+}
+
+// ExtractJSON 从模型输出中提取JSON文本，兼容```json代码块和前后多余文字
+func ExtractJSON(content string) string {
+	content = strings.TrimSpace(content)
+
+	// 代码块：```json ... ``` 或 ``` ... ```
+	if start := strings.Index(content, "```"); start != -1 {
+		body := content[start+3:]
+		if newline := strings.Index(body, "\n"); newline != -1 && !strings.ContainsAny(body[:newline], "{[") {
+			body = body[newline+1:]
+		}
+		if end := strings.Index(body, "```"); end != -1 {
+			body = body[:end]
+		}
+		content = strings.TrimSpace(body)
+	}
+
+	// 截取第一个{或[到最后一个}或]之间的内容
+	start := strings.IndexAny(content, "{[")
+	if start == -1 {
+		return ""
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return ""
+	}
+	return content[start : end+1]
+}
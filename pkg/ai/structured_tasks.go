@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"reactedge/pkg/prompt"
+)
+
+// structuredTasks 基于结构化输出实现的AI功能，供OpenAI兼容的客户端共用
+type structuredTasks struct {
+	generator   *structuredGenerator
+	prompts     *prompt.Library
+	modelFor    func(task string) string
+	maxTokens   int
+	temperature float32
+}
+
+// newStructuredTasks 创建结构化任务集合，提示词来自全局模板库
+func newStructuredTasks(generator *structuredGenerator, modelFor func(task string) string, maxTokens int, temperature float32) *structuredTasks {
+	return &structuredTasks{
+		generator:   generator,
+		prompts:     prompt.Default(),
+		modelFor:    modelFor,
+		maxTokens:   maxTokens,
+		temperature: temperature,
+	}
+}
+
+// questionList 问题列表的输出结构
+type questionList struct {
+	Questions []Question `json:"questions"`
+}
+
+// templateList 反应模板列表的输出结构
+type templateList struct {
+	Templates []ReactionTemplate `json:"templates"`
+}
+
+// generate 渲染提示词模板并请求结构化结果，模板渲染失败时直接返回降级信息
+// 上下文中带有回答语言时在系统提示词中要求模型使用该语言
+func (t *structuredTasks) generate(ctx context.Context, name, task, imageURL string, params interface{}, target interface{}) *OutputInfo {
+	rendered, err := t.prompts.Render(name, params)
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+		t.generator.count(name, OutputFallback)
+		return &OutputInfo{Source: OutputFallback, Error: err.Error()}
+	}
+	rendered.Localize(prompt.LanguageFromContext(ctx))
+
+	return t.generator.Generate(ctx, structuredCall{
+		Name:        name,
+		Template:    rendered.Ref(),
+		Model:       t.modelFor(task),
+		System:      rendered.System,
+		Prompt:      rendered.Prompt,
+		ImageURL:    imageURL,
+		MaxTokens:   t.maxTokens,
+		Temperature: t.temperature,
+	}, target)
+}
+
+// Stats 返回结构化输出统计
+func (t *structuredTasks) Stats() map[string]OutputCounter {
+	return t.generator.Stats()
+}
+
+// AnalyzeImage 图像分析
+func (t *structuredTasks) AnalyzeImage(ctx context.Context, imageURL, request string) *ImageAnalysisResult {
+	var result ImageAnalysisResult
+	info := t.generate(ctx, prompt.ImageAnalysis, "image_analysis", imageURL, prompt.ImageAnalysisParams{Request: request}, &result)
+	if info.IsFallback() {
+		fallback := getDefaultImageAnalysis()
+		fallback.Output = info
+		return fallback
+	}
+	result.Output = info
+	return &result
+}
+
+// GenerateQuestions 生成问题，列表结果无法携带来源信息，默认结果只记录在统计中
+func (t *structuredTasks) GenerateQuestions(ctx context.Context, contextInfo string, category string) []Question {
+	var result questionList
+	info := t.generate(ctx, prompt.Questions, "text_generation", "", prompt.QuestionsParams{Context: contextInfo, Category: category}, &result)
+	if info.IsFallback() || len(result.Questions) == 0 {
+		return getDefaultQuestions()
+	}
+	return result.Questions
+}
+
+// PolishNote 润色反应训练记录
+func (t *structuredTasks) PolishNote(ctx context.Context, rawContent, contextInfo string) *PolishedNote {
+	var result PolishedNote
+	info := t.generate(ctx, prompt.PolishNote, "text_generation", "", prompt.PolishNoteParams{RawContent: rawContent, Context: contextInfo}, &result)
+	if info.IsFallback() {
+		fallback := getDefaultPolishedNote()
+		fallback.Output = info
+		return fallback
+	}
+	result.Output = info
+	return &result
+}
+
+// GenerateReactionTemplates 生成反应模板，默认结果只记录在统计中
+func (t *structuredTasks) GenerateReactionTemplates(ctx context.Context, scenario, style string) []ReactionTemplate {
+	var result templateList
+	info := t.generate(ctx, prompt.ReactionTemplates, "text_generation", "", prompt.ReactionTemplatesParams{Scenario: scenario, Style: style}, &result)
+	if info.IsFallback() || len(result.Templates) == 0 {
+		return getDefaultReactionTemplates()
+	}
+	return result.Templates
+}
+
+// AnalyzeExpressionStyle 分析表达风格
+func (t *structuredTasks) AnalyzeExpressionStyle(ctx context.Context, personName string, sampleText string) *StyleAnalysis {
+	var result StyleAnalysis
+	info := t.generate(ctx, prompt.StyleAnalysis, "advanced_reasoning", "", prompt.StyleAnalysisParams{PersonName: personName, SampleText: sampleText}, &result)
+	if info.IsFallback() {
+		fallback := getDefaultStyleAnalysis()
+		fallback.Output = info
+		return fallback
+	}
+	result.Output = info
+	return &result
+}
+
+// SimulateDebate 模拟辩论
+func (t *structuredTasks) SimulateDebate(ctx context.Context, scenario string, difficulty int, userStyle string) *DebateSimulation {
+	var result DebateSimulation
+	params := prompt.DebateSimulationParams{Scenario: scenario, Difficulty: difficulty, UserStyle: userStyle}
+	info := t.generate(ctx, prompt.DebateSimulation, "advanced_reasoning", "", params, &result)
+	if info.IsFallback() {
+		fallback := getDefaultDebateSimulation()
+		fallback.Output = info
+		return fallback
+	}
+	result.Output = info
+	return &result
+}
+
+// EvaluateReaction 评估反应
+func (t *structuredTasks) EvaluateReaction(ctx context.Context, userResponse, scenario, expectedStyle string) *ReactionEvaluation {
+	var result ReactionEvaluation
+	params := prompt.ReactionEvaluationParams{UserResponse: userResponse, Scenario: scenario, ExpectedStyle: expectedStyle}
+	info := t.generate(ctx, prompt.ReactionEvaluation, "advanced_reasoning", "", params, &result)
+	if info.IsFallback() {
+		fallback := getDefaultReactionEvaluation()
+		fallback.Output = info
+		return fallback
+	}
+	result.Output = info
+	return &result
+}
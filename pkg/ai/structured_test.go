@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// TestSchemaValidate 测试Schema生成与输出校验
+func TestSchemaValidate(t *testing.T) {
+	schema := SchemaFor(&ReactionEvaluation{})
+	if _, ok := schema.Properties["output"]; ok {
+		t.Error("本地字段output不应出现在Schema中")
+	}
+	if item := schema.Properties["content_quality"]; item == nil || item.Properties["score"].Maximum == nil {
+		t.Fatalf("评估项Schema缺少分数范围: %s", schema)
+	}
+
+	valid := `{"content_quality":{"score":8,"description":"好","suggestions":[]},
+		"style_conformity":{"score":7,"description":"","suggestions":["a"]},
+		"reaction_speed":{"score":6.5,"description":"","suggestions":[]},
+		"communication_effect":{"score":7,"description":"","suggestions":[]},
+		"overall_score":7.2,"strengths":["清晰"],"improvements":[]}`
+	if err := schema.Validate([]byte(valid)); err != nil {
+		t.Errorf("有效输出校验失败: %v", err)
+	}
+
+	invalid := `{"content_quality":{"score":12,"description":"好","suggestions":[]},"overall_score":"高"}`
+	err := schema.Validate([]byte(invalid))
+	if err == nil {
+		t.Fatal("无效输出应校验失败")
+	}
+	for _, want := range []string{"$.content_quality.score", "$.overall_score", `缺少必填字段"strengths"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("错误信息缺少%q: %v", want, err)
+		}
+	}
+}
+
+// TestExtractJSON 测试从模型输出中提取JSON
+func TestExtractJSON(t *testing.T) {
+	cases := map[string]string{
+		"```json\n{\"a\":1}\n```":      `{"a":1}`,
+		"结果如下：{\"a\":{\"b\":2}} 希望有帮助": `{"a":{"b":2}}`,
+		"```\n[1,2]\n```":              `[1,2]`,
+		"没有JSON":                       "",
+	}
+	for input, want := range cases {
+		if got := ExtractJSON(input); got != want {
+			t.Errorf("ExtractJSON(%q) = %q，期望 %q", input, got, want)
+		}
+	}
+}
+
+// TestStructuredGeneratorRepair 测试无效输出触发一次纠正重问
+func TestStructuredGeneratorRepair(t *testing.T) {
+	replies := []string{
+		`{"templates":[{"scenario":"汇报","steps":"不是数组"}]}`,
+		"```json\n{\"templates\":[{\"scenario\":\"汇报\",\"steps\":[\"先结论\"],\"key_phrases\":[],\"style_notes\":\"简洁\"}]}\n```",
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ResponseFormat == nil || req.ResponseFormat.Type != openai.ChatCompletionResponseFormatTypeJSONSchema {
+			t.Errorf("请求应携带json_schema格式")
+		}
+		if requests == 1 && len(req.Messages) != 4 {
+			t.Errorf("重问时应附带上次输出和错误，实际消息数: %d", len(req.Messages))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: replies[requests]}}},
+		})
+		requests++
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig("test")
+	config.BaseURL = server.URL
+	generator := newStructuredGenerator(openai.NewClientWithConfig(config), formatJSONSchema)
+
+	var result templateList
+	info := generator.Generate(context.Background(), structuredCall{Name: "reaction_templates", Model: "test", Prompt: "生成模板"}, &result)
+	if info.Source != OutputRepaired || info.Attempts != 2 {
+		t.Fatalf("期望纠正后成功，实际: %+v", info)
+	}
+	if len(result.Templates) != 1 || result.Templates[0].Steps[0] != "先结论" {
+		t.Errorf("解析结果不正确: %+v", result)
+	}
+	if stats := generator.Stats()["reaction_templates"]; stats.Repaired != 1 {
+		t.Errorf("统计不正确: %+v", stats)
+	}
+}
@@ -0,0 +1,37 @@
+package ai
+
+import (
+	"fmt"
+
+	"reactedge/pkg/style"
+)
+
+// applyLocalStyle 附加本地风格分类结果作为参考意见
+// AI评估不可用时，用分类器的结果代替默认的风格符合度，而不是返回固定分数
+func applyLocalStyle(evaluation *ReactionEvaluation, userResponse, expectedStyle string) {
+	if evaluation == nil || userResponse == "" {
+		return
+	}
+	result := style.Default().Classify(userResponse, expectedStyle)
+	evaluation.LocalStyle = result
+	if !evaluation.Output.IsFallback() || result.Target == "" {
+		return
+	}
+
+	description := fmt.Sprintf("本地风格分析：最接近%s的风格", result.ClosestName)
+	if result.Closest == result.Target {
+		description = "本地风格分析：与期望风格一致"
+	}
+	if !result.Reliable {
+		description += "（回答较短，仅供参考）"
+	}
+	suggestions := result.Suggestions
+	if len(suggestions) == 0 {
+		suggestions = []string{"继续保持"}
+	}
+	evaluation.StyleConformity = EvaluationItem{
+		Score:       result.Conformity,
+		Description: description,
+		Suggestions: suggestions,
+	}
+}
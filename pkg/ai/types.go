@@ -1,26 +1,27 @@
 package ai
 
 import (
-	"encoding/json"
 	"fmt"
-	"strings"
+
+	"reactedge/pkg/style"
 )
 
 // ImageAnalysisResult 图像分析结果
 type ImageAnalysisResult struct {
 	ObjectName     string   `json:"object_name"`
 	Category       string   `json:"category"`
-	Confidence     float64  `json:"confidence"`
+	Confidence     float64  `json:"confidence" range:"0,1"`
 	Description    string   `json:"description"`
 	KeyFeatures    []string `json:"key_features"`
 	ScientificName string   `json:"scientific_name"`
+	Output         *OutputInfo `json:"output,omitempty" schema:"-"`
 }
 
 // Question 问题结构
 type Question struct {
 	Content    string `json:"content"`
-	Type       string `json:"type"`
-	Difficulty string `json:"difficulty"`
+	Type       string `json:"type" enum:"scenario|strategy|evaluation"`
+	Difficulty string `json:"difficulty" enum:"basic|intermediate|advanced"`
 	Purpose    string `json:"purpose"`
 }
 
@@ -35,6 +36,7 @@ type PolishedNote struct {
 	Improvements      []string `json:"improvements,omitempty"`
 	Connections       []string `json:"connections,omitempty"`
 	FormattedText     string   `json:"formatted_text"`
+	Output            *OutputInfo `json:"output,omitempty" schema:"-"`
 }
 
 // VideoAnalysis 视频分析结果
@@ -128,8 +130,9 @@ type StyleAnalysis struct {
 	ThinkingPatterns map[string]interface{} `json:"thinking_patterns"`
 	CommunicationStrategy map[string]interface{} `json:"communication_strategy"`
 	PersonalTraits   map[string]interface{} `json:"personal_traits"`
-	OverallScore     float64           `json:"overall_score"`
+	OverallScore     float64           `json:"overall_score" range:"0,10"`
 	StyleTags        []string          `json:"style_tags"`
+	Output           *OutputInfo       `json:"output,omitempty" schema:"-"`
 }
 
 // DebateSimulation 辩论模拟
@@ -139,7 +142,8 @@ type DebateSimulation struct {
 	InteractionRounds []DebateRound `json:"interaction_rounds"`
 	KeyReactionPoints []string `json:"key_reaction_points"`
 	StyleSuggestions []string `json:"style_suggestions"`
-	Difficulty       int      `json:"difficulty"`
+	Difficulty       int      `json:"difficulty" range:"1,5"`
+	Output           *OutputInfo `json:"output,omitempty" schema:"-"`
 }
 
 // DebateRound 辩论回合
@@ -156,14 +160,16 @@ type ReactionEvaluation struct {
 	StyleConformity    EvaluationItem `json:"style_conformity"`
 	ReactionSpeed      EvaluationItem `json:"reaction_speed"`
 	CommunicationEffect EvaluationItem `json:"communication_effect"`
-	OverallScore       float64        `json:"overall_score"`
+	OverallScore       float64        `json:"overall_score" range:"0,10" desc:"综合得分，0-10分"`
 	Strengths          []string       `json:"strengths"`
 	Improvements       []string       `json:"improvements"`
+	LocalStyle         *style.Result  `json:"local_style,omitempty" schema:"-"` // 本地风格分类器的参考意见
+	Output             *OutputInfo    `json:"output,omitempty" schema:"-"`
 }
 
 // EvaluationItem 评估项
 type EvaluationItem struct {
-	Score       float64 `json:"score"`
+	Score       float64 `json:"score" range:"0,10" desc:"0-10分"`
 	Description string  `json:"description"`
 	Suggestions []string `json:"suggestions"`
 }
@@ -375,34 +381,6 @@ func getDefaultReactionEvaluation() *ReactionEvaluation {
 	}
 }
 
-// getDefaultAudioData 生成默认音频数据
-func getDefaultAudioData() []byte {
-	// 生成一个简单的WAV文件头部 + 模拟音频数据
-	wavHeader := []byte{
-		0x52, 0x49, 0x46, 0x46, // "RIFF"
-		0x24, 0x08, 0x00, 0x00, // 文件大小
-		0x57, 0x41, 0x56, 0x45, // "WAVE"
-		0x66, 0x6D, 0x74, 0x20, // "fmt "
-		0x10, 0x00, 0x00, 0x00, // fmt chunk大小
-		0x01, 0x00,             // 格式：PCM
-		0x01, 0x00,             // 声道数：1
-		0x80, 0x3E, 0x00, 0x00, // 采样率：16000
-		0x80, 0x3E, 0x00, 0x00, // 字节率
-		0x02, 0x00,             // 块对齐
-		0x10, 0x00,             // 位深度：16
-		0x64, 0x61, 0x74, 0x61, // "data"
-		0x00, 0x08, 0x00, 0x00, // 数据大小
-	}
-
-	// 生成一些模拟的音频数据
-	audioData := make([]byte, 2048)
-	for i := range audioData {
-		audioData[i] = byte((i * 37) % 256) // 简单的伪随机数据
-	}
-
-	return append(wavHeader, audioData...)
-}
-
 // generateMockMP4Data 生成模拟的MP4文件数据
 func (c *TALClient) generateMockMP4Data(script string, metadata *VideoMetadata) []byte {
 	// MP4文件的基本结构
@@ -456,81 +434,6 @@ func (c *TALClient) generateMockMP4Data(script string, metadata *VideoMetadata)
 
 // 辅助函数
 
-func extractObjectName(content string) string {
-	if len(content) > 50 {
-		return content[:50] + "..."
-	}
-	return content
-}
-
-func extractCategory(content string) string {
-	if strings.Contains(content, "领导") || strings.Contains(content, "汇报") {
-		return "workplace"
-	}
-	return "general"
-}
-
-func extractKeyFeatures(content string) []string {
-	return []string{"特征1", "特征2"}
-}
-
-func extractScientificName(content string) string {
-	return "未知"
-}
-
-func parseQuestionsFromJSON(content string) []Question {
-	// 处理markdown格式的JSON代码块
-	jsonContent := content
-	if strings.Contains(content, "```json") {
-		startIndex := strings.Index(content, "```json")
-		if startIndex != -1 {
-			startIndex += 7
-			endIndex := strings.Index(content[startIndex:], "```")
-			if endIndex != -1 {
-				jsonContent = strings.TrimSpace(content[startIndex : startIndex+endIndex])
-			}
-		}
-	}
-
-	// 尝试解析JSON
-	var result struct {
-		Questions []Question `json:"questions"`
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), &result); err != nil {
-		return []Question{}
-	}
-
-	return result.Questions
-}
-
-func extractSummaryFromText(text string) string {
-	if len(text) > 100 {
-		return text[:100] + "..."
-	}
-	return text
-}
-
-func extractKeyPointsFromText(text string) []string {
-	points := strings.Split(text, "。")
-	var keyPoints []string
-	for _, point := range points {
-		point = strings.TrimSpace(point)
-		if point != "" && len(point) > 5 {
-			keyPoints = append(keyPoints, point)
-			if len(keyPoints) >= 3 {
-				break
-			}
-		}
-	}
-
-	if len(keyPoints) == 0 {
-		keyPoints = []string{"学习了新的知识", "发现了有趣的现象"}
-	}
-
-	return keyPoints
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
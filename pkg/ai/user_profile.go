@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+
+	"reactedge/pkg/prompt"
+)
+
+// InterestSignal 大模型判断的一个兴趣
+type InterestSignal struct {
+	Interest   string  `json:"interest"`
+	Confidence float64 `json:"confidence" range:"0,1"`
+	Evidence   string  `json:"evidence,omitempty" desc:"发言中的依据"`
+}
+
+// UserProfileExtraction 从回答中提取的用户画像
+type UserProfileExtraction struct {
+	Interests     []InterestSignal `json:"interests"`
+	ThinkingStyle string           `json:"thinking_style"`
+	Strengths     []string         `json:"strengths"`
+	Output        *OutputInfo      `json:"output,omitempty" schema:"-"`
+}
+
+// ExtractUserProfile 从回答中提取用户画像，失败时返回只带降级信息的空结果，由调用方使用本地检测
+func (t *structuredTasks) ExtractUserProfile(ctx context.Context, params prompt.UserProfileParams) *UserProfileExtraction {
+	var result UserProfileExtraction
+	result.Output = t.generate(ctx, prompt.UserProfile, "text_generation", "", params, &result)
+	return &result
+}
+
+// ExtractUserProfile 从回答中提取用户画像
+func (c *TALClient) ExtractUserProfile(ctx context.Context, params prompt.UserProfileParams) *UserProfileExtraction {
+	return c.tasks.ExtractUserProfile(ctx, params)
+}
+
+// ExtractUserProfile 从回答中提取用户画像
+func (c *OpenAIClient) ExtractUserProfile(ctx context.Context, params prompt.UserProfileParams) *UserProfileExtraction {
+	return c.tasks.ExtractUserProfile(ctx, params)
+}
+
+// ExtractUserProfile 从回答中提取用户画像，优先使用默认服务商，都不支持时返回降级结果
+func (m *Manager) ExtractUserProfile(ctx context.Context, params prompt.UserProfileParams) *UserProfileExtraction {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	type extractor interface {
+		ExtractUserProfile(ctx context.Context, params prompt.UserProfileParams) *UserProfileExtraction
+	}
+	if client, ok := m.client.(extractor); ok {
+		return client.ExtractUserProfile(ctx, params)
+	}
+	for _, provider := range m.config.GetAvailableProviders() {
+		if client, ok := m.providers[provider].(extractor); ok {
+			return client.ExtractUserProfile(ctx, params)
+		}
+	}
+	return &UserProfileExtraction{Output: &OutputInfo{Source: OutputFallback, Error: "没有支持提取用户画像的AI服务"}}
+}
@@ -0,0 +1,251 @@
+// Package audio 录音数据的格式识别、WAV/PCM编解码以及语音转文字
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Format 音频格式
+type Format string
+
+const (
+	FormatWAV  Format = "wav"
+	FormatPCM  Format = "pcm" // 16位小端裸PCM，需要另外给出采样率和声道数
+	FormatWebM Format = "webm"
+	FormatOGG  Format = "ogg"
+	FormatMP3  Format = "mp3"
+	FormatMP4  Format = "mp4"
+)
+
+// 裸PCM的默认参数，与浏览器录音常用的16kHz单声道一致
+const (
+	DefaultSampleRate = 16000
+	DefaultChannels   = 1
+)
+
+// ErrUnsupportedFormat 无法识别或不支持的音频格式
+var ErrUnsupportedFormat = errors.New("不支持的音频格式")
+
+// Clip 一段待处理的录音
+type Clip struct {
+	Data       []byte
+	Format     Format
+	SampleRate int    // 仅裸PCM使用
+	Channels   int    // 仅裸PCM使用
+	Language   string // 语言提示，如zh、en，空表示自动识别
+	Hint       string // 客户端已有的识别文本（如浏览器语音识别结果），本地转写使用
+}
+
+// PCM 解码后的16位PCM数据，多声道时交错存放
+type PCM struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+// Duration 录音时长
+func (p *PCM) Duration() time.Duration {
+	if p.SampleRate <= 0 || p.Channels <= 0 {
+		return 0
+	}
+	frames := len(p.Samples) / p.Channels
+	return time.Duration(frames) * time.Second / time.Duration(p.SampleRate)
+}
+
+// Mono 混合为单声道并归一化到[-1, 1]
+func (p *PCM) Mono() []float64 {
+	channels := p.Channels
+	if channels <= 0 {
+		channels = 1
+	}
+	mono := make([]float64, len(p.Samples)/channels)
+	for i := range mono {
+		sum := 0.0
+		for c := 0; c < channels; c++ {
+			sum += float64(p.Samples[i*channels+c])
+		}
+		mono[i] = sum / float64(channels) / 32768
+	}
+	return mono
+}
+
+// DetectFormat 根据文件头识别格式，识别不了时参考Content-Type
+func DetectFormat(data []byte, contentType string) Format {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return FormatWAV
+	case len(data) >= 4 && bytes.Equal(data[0:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return FormatWebM
+	case len(data) >= 4 && string(data[0:4]) == "OggS":
+		return FormatOGG
+	case len(data) >= 3 && string(data[0:3]) == "ID3":
+		return FormatMP3
+	case len(data) >= 8 && string(data[4:8]) == "ftyp":
+		return FormatMP4
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch strings.ToLower(mediaType) {
+	case "audio/wav", "audio/wave", "audio/x-wav":
+		return FormatWAV
+	case "audio/l16", "audio/pcm", "audio/x-pcm", "application/octet-stream":
+		return FormatPCM
+	case "audio/webm", "video/webm":
+		return FormatWebM
+	case "audio/ogg":
+		return FormatOGG
+	case "audio/mpeg", "audio/mp3":
+		return FormatMP3
+	case "audio/mp4", "audio/m4a", "audio/x-m4a":
+		return FormatMP4
+	}
+
+	// 不带ID3标签的MP3只能靠帧同步字判断，裸PCM也可能以这两个字节开头，放在最后
+	if len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 {
+		return FormatMP3
+	}
+	return ""
+}
+
+// Decode 把WAV或裸PCM解码为PCM数据，压缩格式返回ErrUnsupportedFormat
+func (c *Clip) Decode() (*PCM, error) {
+	switch c.Format {
+	case FormatWAV:
+		return DecodeWAV(c.Data)
+	case FormatPCM:
+		if len(c.Data)%2 != 0 {
+			return nil, fmt.Errorf("PCM数据长度必须是偶数: %d", len(c.Data))
+		}
+		pcm := &PCM{SampleRate: c.SampleRate, Channels: c.Channels, Samples: make([]int16, len(c.Data)/2)}
+		if pcm.SampleRate <= 0 {
+			pcm.SampleRate = DefaultSampleRate
+		}
+		if pcm.Channels <= 0 {
+			pcm.Channels = DefaultChannels
+		}
+		for i := range pcm.Samples {
+			pcm.Samples[i] = int16(binary.LittleEndian.Uint16(c.Data[i*2:]))
+		}
+		return pcm, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, c.Format)
+	}
+}
+
+// DecodeWAV 解析8/16/24/32位整数PCM或32位浮点的WAV文件
+func DecodeWAV(data []byte) (*PCM, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: 不是WAV文件", ErrUnsupportedFormat)
+	}
+
+	var (
+		audioFormat   uint16
+		channels      int
+		sampleRate    int
+		bitsPerSample int
+		pcmData       []byte
+		haveFmt       bool
+	)
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		body := data[offset+8:]
+		if size > len(body) {
+			// 流式录音的头部长度可能未回填，按实际数据截断
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("WAV格式块长度错误: %d", size)
+			}
+			audioFormat = binary.LittleEndian.Uint16(body[0:])
+			channels = int(binary.LittleEndian.Uint16(body[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:]))
+			if audioFormat == 0xFFFE && size >= 26 {
+				// WAVE_FORMAT_EXTENSIBLE：真实格式在子格式GUID的前两个字节
+				audioFormat = binary.LittleEndian.Uint16(body[24:])
+			}
+			haveFmt = true
+		case "data":
+			pcmData = body
+		}
+		offset += 8 + size + size%2
+	}
+
+	if !haveFmt || pcmData == nil {
+		return nil, fmt.Errorf("WAV文件缺少fmt或data块")
+	}
+	if channels <= 0 || sampleRate <= 0 {
+		return nil, fmt.Errorf("WAV参数无效: %d声道 %dHz", channels, sampleRate)
+	}
+
+	bytesPerSample := bitsPerSample / 8
+	if bytesPerSample == 0 || (audioFormat != 1 && audioFormat != 3) || (audioFormat == 3 && bitsPerSample != 32) {
+		return nil, fmt.Errorf("%w: WAV编码%d/%d位", ErrUnsupportedFormat, audioFormat, bitsPerSample)
+	}
+
+	count := len(pcmData) / bytesPerSample
+	pcm := &PCM{SampleRate: sampleRate, Channels: channels, Samples: make([]int16, count)}
+	for i := 0; i < count; i++ {
+		sample := pcmData[i*bytesPerSample:]
+		switch {
+		case audioFormat == 3:
+			value := float64(math.Float32frombits(binary.LittleEndian.Uint32(sample)))
+			pcm.Samples[i] = int16(clamp(value, -1, 1) * 32767)
+		case bitsPerSample == 8:
+			pcm.Samples[i] = int16(int(sample[0])-128) << 8
+		case bitsPerSample == 16:
+			pcm.Samples[i] = int16(binary.LittleEndian.Uint16(sample))
+		case bitsPerSample == 24:
+			pcm.Samples[i] = int16(uint16(sample[1]) | uint16(sample[2])<<8)
+		case bitsPerSample == 32:
+			pcm.Samples[i] = int16(binary.LittleEndian.Uint32(sample) >> 16)
+		default:
+			return nil, fmt.Errorf("%w: %d位采样", ErrUnsupportedFormat, bitsPerSample)
+		}
+	}
+	return pcm, nil
+}
+
+// EncodeWAV 把PCM数据编码为16位WAV文件
+func EncodeWAV(pcm *PCM) []byte {
+	dataSize := len(pcm.Samples) * 2
+	buf := bytes.NewBuffer(make([]byte, 0, 44+dataSize))
+
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	binary.Write(buf, binary.LittleEndian, uint16(pcm.Channels))
+	binary.Write(buf, binary.LittleEndian, uint32(pcm.SampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(pcm.SampleRate*pcm.Channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(pcm.Channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataSize))
+	binary.Write(buf, binary.LittleEndian, pcm.Samples)
+	return buf.Bytes()
+}
+
+// clamp 把数值限制在区间内
+func clamp(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// testPCM 生成指定时长的440Hz单声道正弦波
+func testPCM(duration time.Duration) *PCM {
+	pcm := &PCM{SampleRate: 16000, Channels: 1}
+	n := int(duration.Seconds() * float64(pcm.SampleRate))
+	pcm.Samples = make([]int16, n)
+	for i := range pcm.Samples {
+		pcm.Samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/float64(pcm.SampleRate)))
+	}
+	return pcm
+}
+
+// TestWAVAndTranscribers 测试WAV编解码、格式识别，以及本地和OpenAI兼容转写器
+func TestWAVAndTranscribers(t *testing.T) {
+	original := testPCM(3 * time.Second)
+	wav := EncodeWAV(original)
+
+	if format := DetectFormat(wav, ""); format != FormatWAV {
+		t.Fatalf("WAV识别错误: %q", format)
+	}
+	if format := DetectFormat([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x01}, ""); format != FormatWebM {
+		t.Errorf("WebM识别错误: %q", format)
+	}
+	if format := DetectFormat([]byte{0xFF, 0xFF, 0x00, 0x00}, "audio/L16; rate=16000"); format != FormatPCM {
+		t.Errorf("裸PCM应以Content-Type为准: %q", format)
+	}
+
+	decoded, err := DecodeWAV(wav)
+	if err != nil {
+		t.Fatalf("解码WAV失败: %v", err)
+	}
+	if decoded.Duration() != 3*time.Second || decoded.Samples[100] != original.Samples[100] {
+		t.Errorf("WAV往返不一致: %v", decoded.Duration())
+	}
+
+	local := NewLocalTranscriber()
+	if _, err := local.Transcribe(context.Background(), &Clip{Data: wav, Format: FormatWAV}); !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("没有识别文本时应返回ErrNoTranscript: %v", err)
+	}
+	transcript, err := local.Transcribe(context.Background(), &Clip{Data: wav, Format: FormatWAV, Hint: "书店变多了。可是没人看书！"})
+	if err != nil {
+		t.Fatalf("本地转写失败: %v", err)
+	}
+	if transcript.Duration != 3*time.Second || len(transcript.Segments) != 2 {
+		t.Fatalf("本地转写结果不正确: %+v", transcript)
+	}
+	if transcript.Segments[1].End != 3*time.Second || transcript.Segments[0].End != transcript.Segments[1].Start {
+		t.Errorf("片段时间戳不连续: %+v", transcript.Segments)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil || header.Filename != "speech.wav" {
+			t.Errorf("裸PCM应封装为WAV上传: %v %v", err, header)
+			http.Error(w, "bad file", http.StatusBadRequest)
+			return
+		}
+		file.Close()
+		if r.FormValue("response_format") != "verbose_json" {
+			t.Errorf("应请求verbose_json: %s", r.FormValue("response_format"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"language":"chinese","text":"书店变多了。","segments":[{"start":0.2,"end":1.5,"text":" 书店变多了。"}]}`))
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig("test")
+	config.BaseURL = server.URL
+	remote := NewOpenAITranscriber(openai.NewClientWithConfig(config), "")
+
+	pcm := EncodeWAV(original)[44:]
+	transcript, err = remote.Transcribe(context.Background(), &Clip{Data: pcm, Format: FormatPCM, SampleRate: 16000})
+	if err != nil {
+		t.Fatalf("远程转写失败: %v", err)
+	}
+	if transcript.Text != "书店变多了。" || transcript.Source != openai.Whisper1 {
+		t.Errorf("远程转写结果不正确: %+v", transcript)
+	}
+	if transcript.Duration != 3*time.Second {
+		t.Errorf("接口未返回时长时应以录音时长为准: %v", transcript.Duration)
+	}
+	if len(transcript.Segments) != 1 || transcript.Segments[0].Start != 200*time.Millisecond || transcript.Segments[0].Text != "书店变多了。" {
+		t.Errorf("片段解析不正确: %+v", transcript.Segments)
+	}
+}
+
+// TestOpenAISynthesizer 测试语音合成的音色别名、语速限制和指令参数
+func TestOpenAISynthesizer(t *testing.T) {
+	var got openai.CreateSpeechRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/speech" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("解析请求失败: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3fake-mp3"))
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig("test")
+	config.BaseURL = server.URL
+	client := openai.NewClientWithConfig(config)
+
+	speech, err := NewOpenAISynthesizer(client, "").Synthesize(context.Background(), SpeechRequest{
+		Text:         "各位观众，晚上好。",
+		Voice:        "news",
+		Speed:        9,
+		Instructions: "字正腔圆",
+	})
+	if err != nil {
+		t.Fatalf("合成失败: %v", err)
+	}
+	if speech.Format != FormatMP3 || speech.ContentType != "audio/mpeg" || string(speech.Data) != "ID3fake-mp3" {
+		t.Errorf("合成结果不正确: %+v", speech)
+	}
+	if got.Voice != openai.VoiceOnyx || got.Speed != MaxSpeed || got.Instructions != "" || got.ResponseFormat != "mp3" {
+		t.Errorf("tts-1请求参数不正确: %+v", got)
+	}
+
+	if _, err := NewOpenAISynthesizer(client, "gpt-4o-mini-tts").Synthesize(context.Background(), SpeechRequest{
+		Text: "晚上好", Voice: "shimmer", Format: FormatWAV, Instructions: "温柔",
+	}); err != nil {
+		t.Fatalf("合成失败: %v", err)
+	}
+	if got.Voice != openai.VoiceShimmer || got.Instructions != "温柔" || got.ResponseFormat != "wav" || got.Speed != 1 {
+		t.Errorf("支持指令的模型应传递instructions: %+v", got)
+	}
+
+	if _, err := NewOpenAISynthesizer(client, "").Synthesize(context.Background(), SpeechRequest{Text: "x", Format: "ogg"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("不支持的格式应返回ErrUnsupportedFormat: %v", err)
+	}
+}
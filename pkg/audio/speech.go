@@ -0,0 +1,150 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrNoSynthesizer 没有可用的语音合成服务
+var ErrNoSynthesizer = errors.New("没有可用的语音合成服务")
+
+// 合成语音的格式
+const (
+	FormatOpus Format = "opus"
+	FormatAAC  Format = "aac"
+	FormatFLAC Format = "flac"
+)
+
+// 语速范围，与OpenAI /audio/speech接口一致
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 4.0
+)
+
+// contentTypes 合成格式对应的Content-Type
+var contentTypes = map[Format]string{
+	FormatMP3:  "audio/mpeg",
+	FormatOpus: "audio/ogg",
+	FormatAAC:  "audio/aac",
+	FormatFLAC: "audio/flac",
+	FormatWAV:  "audio/wav",
+	FormatPCM:  "audio/L16; rate=24000; channels=1",
+}
+
+// voiceAliases 通用音色名到OpenAI音色的映射
+var voiceAliases = map[string]openai.SpeechVoice{
+	"":       openai.VoiceAlloy,
+	"male":   openai.VoiceOnyx,
+	"female": openai.VoiceNova,
+	"news":   openai.VoiceOnyx,
+	"男声":     openai.VoiceOnyx,
+	"女声":     openai.VoiceNova,
+}
+
+// SpeechRequest 语音合成请求
+type SpeechRequest struct {
+	Text         string
+	Voice        string  // OpenAI音色名或male/female/news等别名
+	Speed        float64 // 0表示1.0倍速
+	Format       Format  // 空表示mp3
+	Instructions string  // 语气说明，仅支持指令的模型（如gpt-4o-mini-tts）使用
+}
+
+// Speech 合成的语音
+type Speech struct {
+	Data        []byte
+	Format      Format
+	ContentType string
+}
+
+// Synthesizer 语音合成接口
+type Synthesizer interface {
+	Synthesize(ctx context.Context, req SpeechRequest) (*Speech, error)
+}
+
+// ContentType 返回合成格式的Content-Type
+func ContentType(format Format) string {
+	if contentType, ok := contentTypes[format]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// ValidSpeechFormat 判断是否是支持的合成格式
+func ValidSpeechFormat(format Format) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// OpenAISynthesizer 调用OpenAI兼容的/audio/speech接口
+type OpenAISynthesizer struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAISynthesizer 创建OpenAI兼容的语音合成器，model为空时使用tts-1
+func NewOpenAISynthesizer(client *openai.Client, model string) *OpenAISynthesizer {
+	if model == "" {
+		model = string(openai.TTSModel1)
+	}
+	return &OpenAISynthesizer{client: client, model: model}
+}
+
+// Synthesize 合成语音
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, req SpeechRequest) (*Speech, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("合成文本不能为空")
+	}
+
+	format := req.Format
+	if format == "" {
+		format = FormatMP3
+	}
+	if !ValidSpeechFormat(format) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	speed := req.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	speed = clamp(speed, MinSpeed, MaxSpeed)
+
+	voice, ok := voiceAliases[strings.ToLower(req.Voice)]
+	if !ok {
+		voice = openai.SpeechVoice(strings.ToLower(req.Voice))
+	}
+
+	request := openai.CreateSpeechRequest{
+		Model:          openai.SpeechModel(s.model),
+		Input:          text,
+		Voice:          voice,
+		ResponseFormat: openai.SpeechResponseFormat(format),
+		Speed:          speed,
+	}
+	// tts-1系列不接受instructions参数
+	if !strings.HasPrefix(s.model, "tts-1") {
+		request.Instructions = req.Instructions
+	}
+
+	resp, err := s.client.CreateSpeech(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("语音合成失败: %w", err)
+	}
+	defer resp.Close()
+
+	data, err := io.ReadAll(resp)
+	if err != nil {
+		return nil, fmt.Errorf("读取合成语音失败: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("语音合成服务返回了空音频")
+	}
+	return &Speech{Data: data, Format: format, ContentType: ContentType(format)}, nil
+}
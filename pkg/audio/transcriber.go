@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrNoTranscript 本地转写没有可用的识别文本
+var ErrNoTranscript = errors.New("本地转写器无法识别语音内容，请配置语音识别服务或同时提交识别文本")
+
+// Segment 带时间戳的识别片段
+type Segment struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// Transcript 语音转文字结果
+type Transcript struct {
+	Text     string        `json:"text"`
+	Language string        `json:"language,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Segments []Segment     `json:"segments"`
+	Source   string        `json:"source"` // 识别来源：模型名或local
+}
+
+// Transcriber 语音转文字接口
+type Transcriber interface {
+	Transcribe(ctx context.Context, clip *Clip) (*Transcript, error)
+}
+
+// OpenAITranscriber 调用OpenAI兼容的/audio/transcriptions接口
+type OpenAITranscriber struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAITranscriber 创建OpenAI兼容的语音转写器，model为空时使用whisper-1
+func NewOpenAITranscriber(client *openai.Client, model string) *OpenAITranscriber {
+	if model == "" {
+		model = openai.Whisper1
+	}
+	return &OpenAITranscriber{client: client, model: model}
+}
+
+// Transcribe 上传录音并返回分段结果；裸PCM先封装为WAV，接口不接受无文件头的数据
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, clip *Clip) (*Transcript, error) {
+	data, format := clip.Data, clip.Format
+	if format == FormatPCM {
+		pcm, err := clip.Decode()
+		if err != nil {
+			return nil, err
+		}
+		data, format = EncodeWAV(pcm), FormatWAV
+	}
+	if format == "" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	resp, err := t.client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:                  t.model,
+		FilePath:               "speech." + string(format),
+		Reader:                 bytes.NewReader(data),
+		Language:               clip.Language,
+		Format:                 openai.AudioResponseFormatVerboseJSON,
+		TimestampGranularities: []openai.TranscriptionTimestampGranularity{openai.TranscriptionTimestampGranularitySegment},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("语音转写失败: %w", err)
+	}
+
+	transcript := &Transcript{
+		Text:     strings.TrimSpace(resp.Text),
+		Language: resp.Language,
+		Duration: seconds(resp.Duration),
+		Source:   t.model,
+	}
+	for _, segment := range resp.Segments {
+		transcript.Segments = append(transcript.Segments, Segment{
+			Start: seconds(segment.Start),
+			End:   seconds(segment.End),
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+
+	// 部分兼容服务不返回时长，能解码时以录音本身为准
+	if transcript.Duration == 0 {
+		if pcm, err := clip.Decode(); err == nil {
+			transcript.Duration = pcm.Duration()
+		} else if n := len(transcript.Segments); n > 0 {
+			transcript.Duration = transcript.Segments[n-1].End
+		}
+	}
+	return transcript, nil
+}
+
+// LocalTranscriber 不依赖外部服务的本地转写替身
+// 它不做语音识别：使用客户端提交的识别文本，按句子长度把录音时长分配给各片段
+type LocalTranscriber struct{}
+
+// NewLocalTranscriber 创建本地转写器
+func NewLocalTranscriber() *LocalTranscriber {
+	return &LocalTranscriber{}
+}
+
+// sentencePattern 句子切分，保留句末标点
+var sentencePattern = regexp.MustCompile(`[^。！？!?；;\n]+[。！？!?；;]*`)
+
+// Transcribe 返回基于识别文本的分段结果，没有识别文本时返回ErrNoTranscript
+func (t *LocalTranscriber) Transcribe(ctx context.Context, clip *Clip) (*Transcript, error) {
+	text := strings.TrimSpace(clip.Hint)
+	if text == "" {
+		return nil, ErrNoTranscript
+	}
+
+	var duration time.Duration
+	if pcm, err := clip.Decode(); err == nil {
+		duration = pcm.Duration()
+	}
+
+	transcript := &Transcript{Text: text, Language: clip.Language, Duration: duration, Source: "local"}
+
+	var sentences []string
+	total := 0
+	for _, sentence := range sentencePattern.FindAllString(text, -1) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+			total += utf8.RuneCountInString(sentence)
+		}
+	}
+
+	// 时长未知（压缩格式）时不估算时间戳，只给出一个整段
+	if duration == 0 || total == 0 {
+		transcript.Segments = []Segment{{Text: text}}
+		return transcript, nil
+	}
+
+	var elapsed int
+	for _, sentence := range sentences {
+		start := duration * time.Duration(elapsed) / time.Duration(total)
+		elapsed += utf8.RuneCountInString(sentence)
+		end := duration * time.Duration(elapsed) / time.Duration(total)
+		transcript.Segments = append(transcript.Segments, Segment{Start: start, End: end, Text: sentence})
+	}
+	return transcript, nil
+}
+
+// seconds 把秒数转换为时长
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
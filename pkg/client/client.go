@@ -0,0 +1,266 @@
+// Package client ReactEdge /api/v1 接口的Go客户端，请求和响应类型与 /api/openapi.json 描述的一致，
+// 供其他服务调用名人风格回答生成、挑战、回答分析和表达指纹比较
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client ReactEdge接口客户端，可以在多个goroutine中同时使用
+type Client struct {
+	baseURL    string
+	token      string
+	locale     string
+	httpClient *http.Client
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithToken 使用API令牌认证，令牌在 /auth/tokens 创建
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithLocale 设置回答、建议和错误消息的语言，如en-US
+func WithLocale(locale string) Option {
+	return func(c *Client) {
+		c.locale = locale
+	}
+}
+
+// WithHTTPClient 使用自定义的HTTP客户端，如设置超时或使用Cookie会话
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New 创建客户端，baseURL为服务地址，如 http://localhost:8080
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// APIError 接口返回的错误
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ReactEdge接口返回%d: %s", e.StatusCode, e.Message)
+}
+
+// Styles 列出可用的风格和经典内容
+func (c *Client) Styles(ctx context.Context) (*StylesResponse, error) {
+	var result StylesResponse
+	if err := c.do(ctx, "GET", "/styles", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Generate 生成名人风格回答，AI服务不可用时服务端返回本地模拟回答
+func (c *Client) Generate(ctx context.Context, req GenerateRequest) (*GenerateResponse, error) {
+	var result GenerateResponse
+	if err := c.do(ctx, "POST", "/generate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartChallenge 开始新挑战
+func (c *Client) StartChallenge(ctx context.Context) (*ChallengeResponse, error) {
+	var result ChallengeResponse
+	if err := c.do(ctx, "POST", "/challenges", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Challenge 获取当前挑战的状态和阶段内容，尚未开始挑战时返回404的APIError
+func (c *Client) Challenge(ctx context.Context) (*ChallengeResponse, error) {
+	var result ChallengeResponse
+	if err := c.do(ctx, "GET", "/challenges/current", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AdvanceChallenge 进入挑战的下一阶段，进入表达DNA分析阶段时响应带有更新后的表达指纹
+func (c *Client) AdvanceChallenge(ctx context.Context) (*ChallengeResponse, error) {
+	var result ChallengeResponse
+	if err := c.do(ctx, "POST", "/challenges/current/advance", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubmitSpeech 提交回答文本
+func (c *Client) SubmitSpeech(ctx context.Context, req SpeechRequest) (*ChallengeResponse, error) {
+	var result ChallengeResponse
+	if err := c.do(ctx, "POST", "/challenges/current/speech", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SubmitAudio 上传录音回答，转写后提交到当前挑战
+func (c *Client) SubmitAudio(ctx context.Context, audio io.Reader, options AudioOptions) (*ChallengeResponse, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"format": options.Format, "language": options.Language, "hint": options.Hint} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	for key, value := range map[string]int{"sample_rate": options.SampleRate, "channels": options.Channels} {
+		if value > 0 {
+			query.Set(key, strconv.Itoa(value))
+		}
+	}
+	path := "/challenges/current/audio"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	contentType := options.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	request, err := c.newRequest(ctx, "POST", path, audio)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	var result ChallengeResponse
+	if err := c.send(request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AnalyzeSpeech 分析回答的语速、节奏、清晰度和信心
+func (c *Client) AnalyzeSpeech(ctx context.Context, req SpeechAnalysisRequest) (*SpeechAnalysisResponse, error) {
+	var result SpeechAnalysisResponse
+	if err := c.do(ctx, "POST", "/analysis/speech", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AnalyzeDisfluency 检测口头禅、犹豫词、重复和程度副词
+func (c *Client) AnalyzeDisfluency(ctx context.Context, text string) (*Disfluencies, error) {
+	var result Disfluencies
+	if err := c.do(ctx, "POST", "/analysis/disfluency", TextRequest{Text: text}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClassifyStyle 判断回答最接近哪种名人风格
+func (c *Client) ClassifyStyle(ctx context.Context, req StyleClassifyRequest) (*StyleResult, error) {
+	var result StyleResult
+	if err := c.do(ctx, "POST", "/analysis/style", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Fingerprint 获取个人表达指纹，还没有完成挑战时返回404的APIError
+func (c *Client) Fingerprint(ctx context.Context) (*Fingerprint, error) {
+	var result Fingerprint
+	if err := c.do(ctx, "GET", "/fingerprint", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompareFingerprint 把个人表达与每种内置名人风格比较
+func (c *Client) CompareFingerprint(ctx context.Context) (*CompareResponse, error) {
+	var result CompareResponse
+	if err := c.do(ctx, "GET", "/fingerprint/compare", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// do 以JSON发送请求并解析响应，body为nil时不带请求体
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("编码请求失败: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := c.newRequest(ctx, method, path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(request, result)
+}
+
+// newRequest 创建带认证和语言的请求，path是/api/v1之后的部分
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	request.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.locale != "" {
+		request.Header.Set("Accept-Language", c.locale)
+	}
+	return request, nil
+}
+
+// send 发送请求，状态码不是2xx时返回APIError
+func (c *Client) send(request *http.Request, result interface{}) error {
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("请求ReactEdge失败: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+		apiErr := &APIError{StatusCode: response.StatusCode, Message: strings.TrimSpace(string(data))}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
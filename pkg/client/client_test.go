@@ -0,0 +1,215 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"reactedge/config"
+	"reactedge/internal/ai"
+	"reactedge/web"
+)
+
+// newTestServer 启动使用内存存储、没有AI服务的真实路由，返回服务地址和新注册用户的API令牌
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+
+	cfg := config.GetDefaultConfig()
+	cfg.Auth.Enabled = true
+	cfg.Auth.DataFile = ""
+	cfg.Auth.AllowRegistration = true
+	cfg.History.DataFile = ""
+	cfg.Experiments.DataFile, cfg.Experiments.EventsFile = "", ""
+	cfg.Feedback.DataFile = ""
+	cfg.Personas.DataFile = ""
+	cfg.Corpus.Dir = ""
+	cfg.Analogies.Dir = ""
+	cfg.I18n.LocalesDir = ""
+
+	server := httptest.NewServer(web.NewServer(ai.NewHanStyleAI(), nil, cfg).Router())
+	t.Cleanup(server.Close)
+
+	// 注册后用会话创建API令牌
+	jar, _ := cookiejar.New(nil)
+	session := &http.Client{Jar: jar}
+	for _, step := range []struct{ path, body string }{
+		{"/auth/register", `{"username":"sdk","password":"sdk-password"}`},
+		{"/auth/tokens", `{"name":"client-test"}`},
+	} {
+		response, err := session.Post(server.URL+step.path, "application/json", strings.NewReader(step.body))
+		if err != nil {
+			t.Fatalf("请求%s失败: %v", step.path, err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode >= 300 {
+			t.Fatalf("请求%s返回%d", step.path, response.StatusCode)
+		}
+		if step.path == "/auth/tokens" {
+			var created struct {
+				Token string `json:"token"`
+			}
+			if err := json.NewDecoder(response.Body).Decode(&created); err != nil || created.Token == "" {
+				t.Fatalf("创建令牌失败: %v", err)
+			}
+			return server, created.Token
+		}
+	}
+	return server, ""
+}
+
+// TestOpenAPICoversClient 接口描述可以匿名获取，并且包含客户端用到的全部接口
+func TestOpenAPICoversClient(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	response, err := http.Get(server.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatalf("获取接口描述失败: %v", err)
+	}
+	defer response.Body.Close()
+	var document struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		t.Fatalf("接口描述不是JSON: %v", err)
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Fatalf("应为OpenAPI 3文档: %s", document.OpenAPI)
+	}
+
+	used := map[string]string{
+		"/api/v1/styles":                     "get",
+		"/api/v1/generate":                   "post",
+		"/api/v1/challenges":                 "post",
+		"/api/v1/challenges/current":         "get",
+		"/api/v1/challenges/current/advance": "post",
+		"/api/v1/challenges/current/speech":  "post",
+		"/api/v1/challenges/current/audio":   "post",
+		"/api/v1/analysis/speech":            "post",
+		"/api/v1/analysis/disfluency":        "post",
+		"/api/v1/analysis/style":             "post",
+		"/api/v1/fingerprint":                "get",
+		"/api/v1/fingerprint/compare":        "get",
+	}
+	for path, method := range used {
+		if _, ok := document.Paths[path][method]; !ok {
+			t.Errorf("接口描述缺少 %s %s", strings.ToUpper(method), path)
+		}
+	}
+}
+
+// TestClientChallengeFlow 用客户端走完生成回答和一次完整挑战
+func TestClientChallengeFlow(t *testing.T) {
+	server, token := newTestServer(t)
+	c := New(server.URL, WithToken(token))
+	ctx := context.Background()
+
+	styles, err := c.Styles(ctx)
+	if err != nil || len(styles.Styles) == 0 || len(styles.Contents) == 0 {
+		t.Fatalf("获取风格失败: %v %+v", err, styles)
+	}
+
+	generated, err := c.Generate(ctx, GenerateRequest{Style: styles.Styles[0].Key, Content: "news", Question: "领导让我周末加班怎么回应？"})
+	if err != nil || generated.Response == "" || generated.AttemptID == "" {
+		t.Fatalf("生成回答失败: %v %+v", err, generated)
+	}
+
+	state, err := c.StartChallenge(ctx)
+	if err != nil || state.State.CurrentPhase != PhaseWelcome || state.State.CurrentTopic == "" || state.Content.Title() == "" {
+		t.Fatalf("开始挑战失败: %v %+v", err, state)
+	}
+	for state.State.CurrentPhase < PhaseRecording {
+		if state, err = c.AdvanceChallenge(ctx); err != nil {
+			t.Fatalf("推进挑战失败: %v", err)
+		}
+	}
+	if state.State.Template == nil || len(state.State.Template.Steps) == 0 {
+		t.Fatalf("模板阶段之后应有应答模板: %+v", state.State)
+	}
+
+	speech := "这件事就像打游戏排位，不是输赢问题，本质上是节奏问题。我认为先把核心任务做完，再谈加班。"
+	state, err = c.SubmitSpeech(ctx, SpeechRequest{Speech: speech, Duration: 15})
+	if err != nil || state.State.SpeechAnalysis == nil || state.State.SpeechAnalysis.WordCount == 0 {
+		t.Fatalf("提交回答失败: %v %+v", err, state)
+	}
+	state, err = c.AdvanceChallenge(ctx)
+	if err != nil || state.State.CurrentPhase != PhaseDNAAnalysis || state.State.ExpressionDNA == nil || state.Fingerprint == nil {
+		t.Fatalf("进入DNA分析阶段失败: %v %+v", err, state)
+	}
+
+	fingerprint, err := c.Fingerprint(ctx)
+	if err != nil || fingerprint.Reports != 1 {
+		t.Fatalf("获取表达指纹失败: %v %+v", err, fingerprint)
+	}
+	compared, err := c.CompareFingerprint(ctx)
+	if err != nil || len(compared.Comparisons) == 0 || compared.UserID != fingerprint.UserID {
+		t.Fatalf("比较表达指纹失败: %v %+v", err, compared)
+	}
+
+	// 没有语音识别服务时使用客户端识别的文本，PCM录音同时做声学分析
+	if _, err := c.StartChallenge(ctx); err != nil {
+		t.Fatalf("开始挑战失败: %v", err)
+	}
+	pcm := make([]byte, 16000*2)
+	state, err = c.SubmitAudio(ctx, bytes.NewReader(pcm), AudioOptions{Format: "pcm", SampleRate: 16000, Channels: 1, Hint: speech})
+	if err != nil || state.State.Transcript == nil || state.State.Transcript.Source != "local" || state.State.UserSpeech != speech {
+		t.Fatalf("上传录音失败: %v %+v", err, state)
+	}
+}
+
+// TestClientAnalysis 回答分析接口，建议按客户端设置的语言返回
+func TestClientAnalysis(t *testing.T) {
+	server, token := newTestServer(t)
+	c := New(server.URL, WithToken(token), WithLocale("en-US"))
+	ctx := context.Background()
+
+	analyzed, err := c.AnalyzeSpeech(ctx, SpeechAnalysisRequest{Text: "Well, I think we should basically focus on the core task first.", Duration: 5})
+	if err != nil || analyzed.Result.Language != "en" || analyzed.Result.WordsPerMinute == 0 || len(analyzed.Tips) == 0 {
+		t.Fatalf("分析回答失败: %v %+v", err, analyzed)
+	}
+
+	disfluencies, err := c.AnalyzeDisfluency(ctx, "嗯，那个，我觉得这个这个方案非常好")
+	if err != nil || disfluencies.Total == 0 || disfluencies.Counts[CategoryFiller] == 0 {
+		t.Fatalf("检测赘词失败: %v %+v", err, disfluencies)
+	}
+	var joined strings.Builder
+	for _, span := range disfluencies.Spans {
+		joined.WriteString(span.Text)
+	}
+	if joined.String() != "嗯，那个，我觉得这个这个方案非常好" {
+		t.Fatalf("片段拼接应为原文: %s", joined.String())
+	}
+
+	classified, err := c.ClassifyStyle(ctx, StyleClassifyRequest{Text: "这件事的本质，其实不在于输赢，而在于你愿不愿意承认自己的局限。", Persona: "hanhan"})
+	if err != nil || classified.Closest == "" || len(classified.Scores) == 0 || classified.Target != "hanhan" {
+		t.Fatalf("风格分类失败: %v %+v", err, classified)
+	}
+}
+
+// TestClientErrors 服务端的错误以APIError返回，消息取自JSON错误体
+func TestClientErrors(t *testing.T) {
+	server, token := newTestServer(t)
+	ctx := context.Background()
+
+	var apiErr *APIError
+	if _, err := New(server.URL).Styles(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message == "" {
+		t.Fatalf("未认证时应返回401: %v", err)
+	}
+
+	c := New(server.URL, WithToken(token))
+	if _, err := c.Challenge(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "尚未开始挑战" {
+		t.Fatalf("尚未开始挑战时应返回404: %v", err)
+	}
+	if _, err := c.Fingerprint(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("没有表达DNA报告时应返回404: %v", err)
+	}
+	if _, err := c.Generate(ctx, GenerateRequest{Style: "hanhan"}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("问题为空时应返回400: %v", err)
+	}
+}
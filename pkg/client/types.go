@@ -0,0 +1,367 @@
+package client
+
+import "time"
+
+// GenerateRequest 生成回答的请求
+type GenerateRequest struct {
+	Style    string `json:"style"`    // 风格标识，内置风格或自定义风格
+	Content  string `json:"content"`  // 参考的经典内容，如news、poetry
+	Question string `json:"question"` // 职场问题
+}
+
+// GenerateResponse 生成的回答
+type GenerateResponse struct {
+	Response  string    `json:"response"`
+	AttemptID string    `json:"attempt_id"` // 训练记录ID，可用于反馈和朗读
+	AudioURL  string    `json:"audio_url"`  // 朗读地址，相对于服务地址
+	Citations []Passage `json:"citations"`  // 生成时参考的讲话片段
+}
+
+// Passage 语料库中的讲话片段
+type Passage struct {
+	ID         string  `json:"id"`
+	DocumentID string  `json:"document_id"`
+	Persona    string  `json:"persona"`
+	Title      string  `json:"title"`
+	Text       string  `json:"text"`
+	Score      float64 `json:"score"`
+}
+
+// StylesResponse 可用的风格和经典内容
+type StylesResponse struct {
+	Styles   []Style   `json:"styles"`
+	Contents []Content `json:"contents"`
+}
+
+// Style 可选的风格
+type Style struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Label       string `json:"label"` // 按请求语言组织的显示名称
+	Builtin     bool   `json:"builtin"`
+	Base        string `json:"base,omitempty"` // 自定义风格最接近的内置风格
+}
+
+// Content 可选的经典内容
+type Content struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// ChallengePhase 挑战阶段
+type ChallengePhase int
+
+// 挑战阶段，与服务端的取值一致
+const (
+	PhaseWelcome ChallengePhase = iota
+	PhaseAIDeconstruction
+	PhasePersonalizedTemplate
+	PhaseRecording
+	PhaseDNAAnalysis
+	PhaseComplete
+)
+
+// SpeechRequest 提交回答文本的请求
+type SpeechRequest struct {
+	Speech   string  `json:"speech"`
+	Duration float64 `json:"duration,omitempty"` // 回答时长（秒），用于计算语速
+}
+
+// AudioOptions 上传录音的参数，都可以不填
+type AudioOptions struct {
+	ContentType string // 录音的Content-Type，如audio/wav，默认application/octet-stream
+	Format      string // wav/pcm/webm/ogg/mp3/mp4，不填时按内容识别
+	Language    string // 录音语言，如zh、en
+	Hint        string // 客户端识别的文本，远程转写不可用时使用
+	SampleRate  int    // PCM录音的采样率
+	Channels    int    // PCM录音的声道数
+}
+
+// ChallengeResponse 挑战状态和当前阶段内容
+type ChallengeResponse struct {
+	State       ChallengeState `json:"state"`
+	Content     PhaseContent   `json:"content"`
+	Fingerprint *Fingerprint   `json:"fingerprint,omitempty"` // 进入表达DNA分析阶段时返回
+}
+
+// ChallengeState 挑战状态
+type ChallengeState struct {
+	CurrentPhase         ChallengePhase        `json:"current_phase"`
+	StartTime            time.Time             `json:"start_time"`
+	PhaseStartTime       time.Time             `json:"phase_start_time"`
+	UserProfile          UserProfile           `json:"user_profile"`
+	CurrentTopic         string                `json:"current_topic"`
+	UserSpeech           string                `json:"user_speech"`
+	ExpressionDNA        *ExpressionDNA        `json:"expression_dna,omitempty"`
+	SpeechAnalysis       *SpeechResult         `json:"speech_analysis,omitempty"`
+	Transcript           *Transcript           `json:"transcript,omitempty"`
+	PersonalizedTemplate string                `json:"personalized_template"`
+	Template             *PersonalizedTemplate `json:"template,omitempty"`
+	TimeRemaining        int                   `json:"time_remaining"` // 秒
+}
+
+// PhaseContent 当前阶段的展示内容，除phase、topic、title等公共字段外随阶段变化
+type PhaseContent map[string]interface{}
+
+// Title 阶段标题
+func (c PhaseContent) Title() string {
+	title, _ := c["title"].(string)
+	return title
+}
+
+// UserProfile 从回答中探测的用户画像
+type UserProfile struct {
+	PrimaryInterest string          `json:"primary_interest"`
+	ThinkingStyle   string          `json:"thinking_style"`
+	MetaphorStyle   string          `json:"metaphor_style"`
+	Strengths       []string        `json:"strengths"`
+	Interests       []InterestScore `json:"interests,omitempty"`
+	Confidence      float64         `json:"confidence"` // 主要兴趣的置信度 0-1
+	Samples         int             `json:"samples,omitempty"`
+	AIAssisted      bool            `json:"ai_assisted,omitempty"`
+}
+
+// InterestScore 兴趣得分
+type InterestScore struct {
+	Interest string   `json:"interest"`
+	Score    float64  `json:"score"`
+	Weight   float64  `json:"weight"` // 在全部兴趣中的占比，0-1
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// ExpressionDNA 表达DNA报告
+type ExpressionDNA struct {
+	SharpenessScore int      `json:"sharpeness_score"` // 犀利指数 0-100
+	PersonalityTags []string `json:"personality_tags"`
+	UniquePatterns  []string `json:"unique_patterns"`
+	ThinkingPattern string   `json:"thinking_pattern"`
+	MetaphorStyle   string   `json:"metaphor_style"`
+	RhythmSignature string   `json:"rhythm_signature"`
+	UniquenessScore int      `json:"uniqueness_score"`
+	Recommendations []string `json:"recommendations"`
+	NextChallenge   string   `json:"next_challenge"`
+	Language        string   `json:"language,omitempty"` // zh或en
+}
+
+// PersonalizedTemplate 分步骤的应答模板
+type PersonalizedTemplate struct {
+	Topic         string         `json:"topic"`
+	Subject       string         `json:"subject"`
+	Interest      string         `json:"interest"`
+	ThinkingStyle string         `json:"thinking_style,omitempty"`
+	Steps         []TemplateStep `json:"steps"`
+	Source        string         `json:"source"`
+}
+
+// TemplateStep 模板的一个步骤
+type TemplateStep struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// Transcript 录音的转写结果
+type Transcript struct {
+	Text     string        `json:"text"`
+	Language string        `json:"language,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Segments []Segment     `json:"segments"`
+	Source   string        `json:"source"` // 识别来源：模型名或local
+}
+
+// Segment 带时间戳的转写片段
+type Segment struct {
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// SpeechAnalysisRequest 回答分析的请求
+type SpeechAnalysisRequest struct {
+	Text     string  `json:"text"`
+	Duration float64 `json:"duration,omitempty"` // 回答时长（秒），没有时不计算语速
+}
+
+// SpeechAnalysisResponse 回答分析结果和建议
+type SpeechAnalysisResponse struct {
+	Result SpeechResult `json:"result"`
+	Tips   []string     `json:"tips"`
+}
+
+// SpeechResult 回答的语速、节奏、清晰度和信心分析
+type SpeechResult struct {
+	Text            string        `json:"text"`
+	Language        string        `json:"language"` // 检测到的回答语言，zh或en
+	WordCount       int           `json:"word_count"`
+	SentenceCount   int           `json:"sentence_count"`
+	QuestionCount   int           `json:"question_count"`
+	Duration        time.Duration `json:"duration"`
+	WordsPerMinute  float64       `json:"words_per_minute"`
+	PauseCount      int           `json:"pause_count"`
+	RhythmScore     int           `json:"rhythm_score"`
+	ClarityScore    int           `json:"clarity_score"`
+	ConfidenceScore int           `json:"confidence_score"`
+	Prosody         *Prosody      `json:"prosody,omitempty"` // 有录音时的声学分析
+	Disfluencies    *Disfluencies `json:"disfluencies"`
+}
+
+// Prosody 基于录音的声学韵律分析
+type Prosody struct {
+	Duration       time.Duration `json:"duration"`
+	SpeechDuration time.Duration `json:"speech_duration"`
+	SpeechRatio    float64       `json:"speech_ratio"`
+	Pauses         []Pause       `json:"pauses"`
+	LongestPause   time.Duration `json:"longest_pause"`
+	MeanPause      time.Duration `json:"mean_pause"`
+	RateCurve      []RatePoint   `json:"rate_curve,omitempty"`
+	RateVariation  float64       `json:"rate_variation"`
+	PitchMean      float64       `json:"pitch_mean"`
+	PitchStdDev    float64       `json:"pitch_std_dev"`
+	PitchRange     float64       `json:"pitch_range"`
+	VoicedFrames   int           `json:"voiced_frames"`
+	VolumeMeanDB   float64       `json:"volume_mean_db"`
+	VolumeStdDevDB float64       `json:"volume_std_dev_db"`
+	VolumeRangeDB  float64       `json:"volume_range_db"`
+	RhythmScore    int           `json:"rhythm_score"`
+}
+
+// Pause 一次停顿
+type Pause struct {
+	Start    time.Duration `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// RatePoint 某一时间窗内的语速
+type RatePoint struct {
+	Start          time.Duration `json:"start"`
+	CharsPerMinute float64       `json:"chars_per_minute"`
+}
+
+// TextRequest 只包含文本的分析请求
+type TextRequest struct {
+	Text string `json:"text"`
+}
+
+// 赘词类别
+const (
+	CategoryFiller      = "filler"
+	CategoryHedge       = "hedge"
+	CategoryRepetition  = "repetition"
+	CategoryIntensifier = "intensifier"
+)
+
+// Disfluencies 赘词检测结果
+type Disfluencies struct {
+	Occurrences []Occurrence   `json:"occurrences"`
+	Counts      map[string]int `json:"counts"` // 类别 → 次数
+	Total       int            `json:"total"`
+	Spans       []Span         `json:"spans"` // 按顺序拼接即为原文，Category不为空的片段需要高亮
+}
+
+// Occurrence 一处赘词，Start和End按Unicode字符计
+type Occurrence struct {
+	Category string `json:"category"`
+	Text     string `json:"text"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// Span 原文片段
+type Span struct {
+	Text     string `json:"text"`
+	Category string `json:"category,omitempty"`
+}
+
+// StyleClassifyRequest 风格分类的请求
+type StyleClassifyRequest struct {
+	Text    string `json:"text"`
+	Persona string `json:"persona,omitempty"` // 期望的风格，可选
+}
+
+// StyleResult 风格分类结果
+type StyleResult struct {
+	Closest     string         `json:"closest"`
+	ClosestName string         `json:"closest_name"`
+	Target      string         `json:"target,omitempty"`
+	Conformity  float64        `json:"conformity"` // 0-10
+	Reliable    bool           `json:"reliable"`   // 回答太短时为false，结果仅供参考
+	Scores      []PersonaScore `json:"scores"`
+	Evidence    []Evidence     `json:"evidence"`
+	Phrases     []string       `json:"phrases,omitempty"`
+	Suggestions []string       `json:"suggestions,omitempty"`
+}
+
+// PersonaScore 与一种风格的相似度
+type PersonaScore struct {
+	Persona     string  `json:"persona"`
+	Name        string  `json:"name"`
+	Similarity  float64 `json:"similarity"`
+	Probability float64 `json:"probability"`
+}
+
+// Evidence 一项风格特征的对比
+type Evidence struct {
+	Feature  string  `json:"feature"`
+	Label    string  `json:"label"`
+	Value    float64 `json:"value"`
+	Expected float64 `json:"expected"`
+	Match    float64 `json:"match"`
+	Closest  string  `json:"closest"`
+}
+
+// Fingerprint 个人表达指纹
+type Fingerprint struct {
+	UserID            string    `json:"user_id"`
+	Reports           int       `json:"reports"`
+	FirstSeen         time.Time `json:"first_seen"`
+	LastSeen          time.Time `json:"last_seen"`
+	StableTraits      []Share   `json:"stable_traits"`
+	SignaturePatterns []Share   `json:"signature_patterns"`
+	ThinkingPatterns  []Share   `json:"thinking_patterns"`
+	Rhythms           []Share   `json:"rhythms"`
+	MetaphorDomains   []Share   `json:"metaphor_domains"`
+	Features          []Trait   `json:"features"`
+	Drift             *Drift    `json:"drift,omitempty"` // 报告太少时为空
+}
+
+// Share 取值在报告中出现的次数和占比
+type Share struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"` // 0-1
+}
+
+// Trait 比各名人风格平均水平更突出的特征
+type Trait struct {
+	Feature string  `json:"feature"`
+	Label   string  `json:"label"`
+	Value   float64 `json:"value"`
+	Score   float64 `json:"score"`
+}
+
+// Drift 近期报告相对基线的风格漂移
+type Drift struct {
+	Baseline   int                `json:"baseline"`
+	Recent     int                `json:"recent"`
+	Dimensions map[string]float64 `json:"dimensions"`
+	Score      float64            `json:"score"`
+	Drifting   bool               `json:"drifting"`
+	Changes    []string           `json:"changes,omitempty"`
+}
+
+// CompareResponse 个人表达与各内置风格的比较
+type CompareResponse struct {
+	UserID      string       `json:"user_id"`
+	Comparisons []Comparison `json:"comparisons"`
+}
+
+// Comparison 与一种风格的比较
+type Comparison struct {
+	Persona    string   `json:"persona"`
+	Name       string   `json:"name"`
+	Similarity float64  `json:"similarity"` // 0-10
+	Shared     []string `json:"shared,omitempty"`
+	Gaps       []string `json:"gaps,omitempty"`
+	Phrases    []string `json:"phrases,omitempty"`
+}
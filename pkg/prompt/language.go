@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"context"
+	"strings"
+)
+
+// 模板本身用中文书写，中文回答不需要额外说明；其他语言在系统提示词末尾追加回答语言要求
+var languageInstructions = map[string]string{
+	"en": "Respond in English (en-US). Keep JSON field names, and any values that must be chosen from a list given above, exactly as written; write all other natural-language text in English.",
+}
+
+// LanguageInstruction 返回要求模型使用指定语言回答的说明，中文或不认识的语言返回空
+func LanguageInstruction(language string) string {
+	base := strings.ToLower(strings.SplitN(strings.ReplaceAll(language, "_", "-"), "-", 2)[0])
+	return languageInstructions[base]
+}
+
+// Localize 在系统提示词末尾追加回答语言要求
+func (r *Rendered) Localize(language string) *Rendered {
+	instruction := LanguageInstruction(language)
+	if instruction == "" {
+		return r
+	}
+	if r.System == "" {
+		r.System = instruction
+	} else {
+		r.System += "\n\n" + instruction
+	}
+	return r
+}
+
+type languageKey struct{}
+
+// WithLanguage 将回答语言写入上下文，AI调用据此本地化提示词
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageFromContext 从上下文获取回答语言，没有设置时返回空
+func LanguageFromContext(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
@@ -0,0 +1,118 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+)
+
+// 模板名称
+const (
+	PersonaAnswer      = "persona_answer"
+	ImageAnalysis      = "image_analysis"
+	Questions          = "questions"
+	PolishNote         = "polish_note"
+	ReactionTemplates  = "reaction_templates"
+	StyleAnalysis      = "style_analysis"
+	DebateSimulation   = "debate_simulation"
+	ReactionEvaluation = "reaction_evaluation"
+	ChallengeTemplate  = "challenge_template"
+	UserProfile        = "user_profile"
+)
+
+// PersonaAnswerParams 名人风格回答的参数
+type PersonaAnswerParams struct {
+	PersonaName         string
+	PersonaDescription  string
+	PersonaInstructions string    // 自定义风格从样本分析出的表达要求
+	Phrases             []string  // 自定义风格样本中的代表性说法
+	Reference           string    // 经典讲话内容参考
+	Passages            []Passage // 从语料库检索到的真实讲话片段
+	Question            string
+}
+
+// Passage 提示词中引用的讲话片段
+type Passage struct {
+	Index int // 从1开始的编号
+	Title string
+	Text  string
+}
+
+// ImageAnalysisParams 图像分析的参数
+type ImageAnalysisParams struct {
+	Request string
+}
+
+// QuestionsParams 生成训练问题的参数
+type QuestionsParams struct {
+	Context  string
+	Category string
+}
+
+// PolishNoteParams 润色训练记录的参数
+type PolishNoteParams struct {
+	RawContent string
+	Context    string
+}
+
+// ReactionTemplatesParams 生成反应模板的参数
+type ReactionTemplatesParams struct {
+	Scenario string
+	Style    string
+}
+
+// StyleAnalysisParams 表达风格分析的参数
+type StyleAnalysisParams struct {
+	PersonName string
+	SampleText string
+}
+
+// DebateSimulationParams 辩论模拟的参数
+type DebateSimulationParams struct {
+	Scenario   string
+	Difficulty int
+	UserStyle  string
+}
+
+// ChallengeTemplateParams 挑战个性化应答模板的参数
+type ChallengeTemplateParams struct {
+	Topic         string
+	Interest      string   // 用户的主要兴趣，如游戏、体育
+	ThinkingStyle string   // 用户的思维方式，如类比思维、逻辑推理
+	Analogies     []string // 兴趣类比库中与话题相关的类比，供模型参考
+}
+
+// UserProfileParams 从回答中提取用户画像的参数
+type UserProfileParams struct {
+	Speech         string
+	Interests      []string // 可选的兴趣，来自画像词表
+	ThinkingStyles []string // 可选的思维方式
+	Strengths      []string // 可选的表达优势
+}
+
+// ReactionEvaluationParams 反应评估的参数
+type ReactionEvaluationParams struct {
+	UserResponse  string
+	Scenario      string
+	ExpectedStyle string
+}
+
+// paramTypes 各模板登记的参数类型，加载模板时会据此检查字段名
+var paramTypes = map[string]reflect.Type{
+	PersonaAnswer:      reflect.TypeOf(PersonaAnswerParams{}),
+	ImageAnalysis:      reflect.TypeOf(ImageAnalysisParams{}),
+	Questions:          reflect.TypeOf(QuestionsParams{}),
+	PolishNote:         reflect.TypeOf(PolishNoteParams{}),
+	ReactionTemplates:  reflect.TypeOf(ReactionTemplatesParams{}),
+	StyleAnalysis:      reflect.TypeOf(StyleAnalysisParams{}),
+	DebateSimulation:   reflect.TypeOf(DebateSimulationParams{}),
+	ReactionEvaluation: reflect.TypeOf(ReactionEvaluationParams{}),
+	ChallengeTemplate:  reflect.TypeOf(ChallengeTemplateParams{}),
+	UserProfile:        reflect.TypeOf(UserProfileParams{}),
+}
+
+// paramTypeFor 查找模板的参数类型，变体模板（如persona_answer.concise）沿用基础模板的参数类型
+func paramTypeFor(name string) (reflect.Type, bool) {
+	base, _, _ := strings.Cut(name, ".")
+	params, ok := paramTypes[base]
+	return params, ok
+}
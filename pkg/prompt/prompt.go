@@ -0,0 +1,292 @@
+package prompt
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/*.tmpl
+var embedded embed.FS
+
+// 模板文件扩展名
+const templateExt = ".tmpl"
+
+// Template 一个命名、带版本的提示词模板
+// 模板文件以YAML头部开始，之后是用户提示词正文：
+//
+//	---
+//	version: 2
+//	description: 说明
+//	system: 系统提示词（可使用模板语法）
+//	---
+//	正文
+type Template struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"` // embedded 或覆盖文件路径
+
+	system *template.Template
+	body   *template.Template
+}
+
+// Ref 返回"名称@版本"形式的模板标识，用于记录生成结果使用的模板
+func (t *Template) Ref() string {
+	return t.Name + "@" + t.Version
+}
+
+// Rendered 渲染后的提示词
+type Rendered struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	System  string `json:"system,omitempty"`
+	Prompt  string `json:"prompt"`
+}
+
+// Ref 返回"名称@版本"形式的模板标识
+func (r *Rendered) Ref() string {
+	return r.Name + "@" + r.Version
+}
+
+// Combined 合并系统提示词和正文，用于只接受单段提示词的接口
+func (r *Rendered) Combined() string {
+	if r.System == "" {
+		return r.Prompt
+	}
+	return r.System + "\n\n" + r.Prompt
+}
+
+// header 模板文件头部
+type header struct {
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+	System      string `yaml:"system"`
+}
+
+// Library 提示词模板库，内置模板可被覆盖目录中的同名文件替换
+type Library struct {
+	overrideDir string
+	templates   map[string]*Template
+	mutex       sync.RWMutex
+}
+
+// NewLibrary 创建模板库，overrideDir为空时只使用内置模板
+func NewLibrary(overrideDir string) (*Library, error) {
+	library := &Library{overrideDir: overrideDir}
+	if err := library.Reload(); err != nil {
+		return nil, err
+	}
+	return library, nil
+}
+
+var (
+	defaultLibrary *Library
+	defaultOnce    sync.Once
+)
+
+// Default 返回全局模板库，初始只包含内置模板
+func Default() *Library {
+	defaultOnce.Do(func() {
+		library, err := NewLibrary("")
+		if err != nil {
+			// 内置模板有测试保证，这里出错说明构建有问题
+			panic(fmt.Sprintf("加载内置提示词模板失败: %v", err))
+		}
+		defaultLibrary = library
+	})
+	return defaultLibrary
+}
+
+// SetOverrideDir 设置覆盖目录并重新加载
+func (l *Library) SetOverrideDir(dir string) error {
+	l.mutex.Lock()
+	l.overrideDir = dir
+	l.mutex.Unlock()
+	return l.Reload()
+}
+
+// Reload 重新加载内置模板和覆盖目录
+// 内置模板出错时返回错误；覆盖文件出错时跳过该文件并继续使用内置版本
+func (l *Library) Reload() error {
+	templates := make(map[string]*Template)
+
+	entries, err := fs.ReadDir(embedded, "templates")
+	if err != nil {
+		return fmt.Errorf("读取内置模板失败: %w", err)
+	}
+	for _, entry := range entries {
+		raw, err := embedded.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return fmt.Errorf("读取内置模板失败: %w", err)
+		}
+		tmpl, err := parseTemplate(templateName(entry.Name()), "embedded", raw)
+		if err != nil {
+			return err
+		}
+		templates[tmpl.Name] = tmpl
+	}
+
+	l.mutex.RLock()
+	dir := l.overrideDir
+	l.mutex.RUnlock()
+
+	if dir != "" {
+		overridden := loadOverrides(dir, templates)
+		if overridden > 0 {
+			fmt.Printf("✅ 已从%s加载%d个提示词模板覆盖\n", dir, overridden)
+		}
+	}
+
+	l.mutex.Lock()
+	l.templates = templates
+	l.mutex.Unlock()
+	return nil
+}
+
+// loadOverrides 加载覆盖目录中的模板，返回成功覆盖的数量
+func loadOverrides(dir string, templates map[string]*Template) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("⚠️ 读取提示词覆盖目录失败: %v\n", err)
+		}
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExt {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("⚠️ 读取提示词模板%s失败: %v\n", path, err)
+			continue
+		}
+		tmpl, err := parseTemplate(templateName(entry.Name()), path, raw)
+		if err != nil {
+			fmt.Printf("⚠️ 提示词模板%s无效，继续使用内置版本: %v\n", path, err)
+			continue
+		}
+		templates[tmpl.Name] = tmpl
+		count++
+	}
+	return count
+}
+
+// templateName 由文件名得到模板名
+func templateName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), templateExt)
+}
+
+// parseTemplate 解析模板文件，并用参数类型的零值试渲染以检查字段名
+func parseTemplate(name, source string, raw []byte) (*Template, error) {
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, fmt.Errorf("模板%s缺少YAML头部", name)
+	}
+	end := strings.Index(text[4:], "\n---\n")
+	if end == -1 {
+		return nil, fmt.Errorf("模板%s的YAML头部没有结束标记", name)
+	}
+
+	var head header
+	if err := yaml.Unmarshal([]byte(text[4:4+end]), &head); err != nil {
+		return nil, fmt.Errorf("解析模板%s头部失败: %w", name, err)
+	}
+	if head.Version == "" {
+		return nil, fmt.Errorf("模板%s缺少version", name)
+	}
+
+	tmpl := &Template{
+		Name:        name,
+		Version:     head.Version,
+		Description: head.Description,
+		Source:      source,
+	}
+
+	var err error
+	if tmpl.system, err = template.New(name + ".system").Parse(head.System); err != nil {
+		return nil, fmt.Errorf("解析模板%s系统提示词失败: %w", name, err)
+	}
+	if tmpl.body, err = template.New(name).Parse(text[4+end+5:]); err != nil {
+		return nil, fmt.Errorf("解析模板%s失败: %w", name, err)
+	}
+
+	if params, ok := paramTypeFor(name); ok {
+		if _, err := tmpl.render(reflect.New(params).Elem().Interface()); err != nil {
+			return nil, fmt.Errorf("模板%s与参数类型%s不匹配: %w", name, params.Name(), err)
+		}
+	}
+	return tmpl, nil
+}
+
+// render 执行模板
+func (t *Template) render(params interface{}) (*Rendered, error) {
+	var system, body bytes.Buffer
+	if err := t.system.Execute(&system, params); err != nil {
+		return nil, err
+	}
+	if err := t.body.Execute(&body, params); err != nil {
+		return nil, err
+	}
+	return &Rendered{
+		Name:    t.Name,
+		Version: t.Version,
+		System:  strings.TrimSpace(system.String()),
+		Prompt:  strings.TrimSpace(body.String()),
+	}, nil
+}
+
+// Render 用参数渲染指定模板，参数类型必须与模板登记的类型一致
+func (l *Library) Render(name string, params interface{}) (*Rendered, error) {
+	l.mutex.RLock()
+	tmpl, ok := l.templates[name]
+	l.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("提示词模板不存在: %s", name)
+	}
+
+	if expected, ok := paramTypeFor(name); ok && reflect.TypeOf(params) != expected {
+		return nil, fmt.Errorf("模板%s需要参数类型%s，实际为%T", name, expected.Name(), params)
+	}
+
+	rendered, err := tmpl.render(params)
+	if err != nil {
+		return nil, fmt.Errorf("渲染模板%s失败: %w", tmpl.Ref(), err)
+	}
+	return rendered, nil
+}
+
+// Get 获取模板信息
+func (l *Library) Get(name string) (*Template, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	tmpl, ok := l.templates[name]
+	return tmpl, ok
+}
+
+// List 按名称列出所有模板
+func (l *Library) List() []*Template {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	list := make([]*Template, 0, len(l.templates))
+	for _, tmpl := range l.templates {
+		list = append(list, tmpl)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
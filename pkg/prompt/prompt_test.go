@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLibraryOverride 测试内置模板、类型检查和目录覆盖
+func TestLibraryOverride(t *testing.T) {
+	dir := t.TempDir()
+	library, err := NewLibrary(dir)
+	if err != nil {
+		t.Fatalf("加载内置模板失败: %v", err)
+	}
+	for name := range paramTypes {
+		if _, ok := library.Get(name); !ok {
+			t.Errorf("缺少内置模板: %s", name)
+		}
+	}
+
+	params := ReactionEvaluationParams{UserResponse: "先说结论", Scenario: "述职答辩", ExpectedStyle: "韩寒"}
+	rendered, err := library.Render(ReactionEvaluation, params)
+	if err != nil {
+		t.Fatalf("渲染失败: %v", err)
+	}
+	if rendered.Ref() != "reaction_evaluation@1" || !strings.Contains(rendered.Prompt, "述职答辩") || rendered.System == "" {
+		t.Errorf("渲染结果不正确: %+v", rendered)
+	}
+	if _, err := library.Render(ReactionEvaluation, QuestionsParams{}); err == nil {
+		t.Error("参数类型不匹配时应返回错误")
+	}
+
+	override := "---\nversion: \"2-tuned\"\nsystem: 严格的评估专家\n---\n评估{{.UserResponse}}\n"
+	broken := "---\nversion: \"9\"\n---\n{{.NoSuchField}}\n"
+	os.WriteFile(filepath.Join(dir, "reaction_evaluation.tmpl"), []byte(override), 0644)
+	os.WriteFile(filepath.Join(dir, "questions.tmpl"), []byte(broken), 0644)
+	if err := library.Reload(); err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+
+	rendered, _ = library.Render(ReactionEvaluation, params)
+	if rendered.Ref() != "reaction_evaluation@2-tuned" || rendered.Prompt != "评估先说结论" {
+		t.Errorf("覆盖模板未生效: %+v", rendered)
+	}
+	if tmpl, _ := library.Get(Questions); tmpl.Version != "1" || tmpl.Source != "embedded" {
+		t.Errorf("无效的覆盖模板应被跳过: %+v", tmpl)
+	}
+}
+
+// TestLocalize 测试按回答语言追加语言要求
+func TestLocalize(t *testing.T) {
+	rendered := &Rendered{System: "评估专家", Prompt: "评估回答"}
+	if rendered.Localize("zh-CN").System != "评估专家" {
+		t.Fatal("中文不应追加语言要求")
+	}
+	if system := rendered.Localize("en-GB").System; !strings.HasPrefix(system, "评估专家\n\n") || !strings.Contains(system, "English") {
+		t.Fatalf("英文应追加语言要求: %s", system)
+	}
+
+	ctx := WithLanguage(context.Background(), "en-US")
+	if LanguageFromContext(ctx) != "en-US" || LanguageFromContext(context.Background()) != "" {
+		t.Fatal("上下文中的回答语言不正确")
+	}
+}
@@ -0,0 +1,112 @@
+package style
+
+import (
+	"strings"
+	"unicode"
+)
+
+// feature 一项风格特征，取值为每百字（或每句）出现的次数
+type feature struct {
+	Name    string
+	Label   string
+	Markers []string
+	PerLine bool // 按句计算而不是按每百字计算
+	extract func(text string, chars, sentences int) float64
+}
+
+// features 用于区分名人风格的特征
+var features = []feature{
+	{Name: "question", Label: "反问和设问", Markers: []string{"？", "?", "难道", "岂不", "凭什么"}, PerLine: true},
+	{Name: "data", Label: "数据和数字", Markers: []string{"%", "数据", "统计", "同比", "增长", "占比", "指标"}},
+	{Name: "empathy", Label: "共情表达", Markers: []string{"理解", "感受", "一起", "温暖", "谢谢", "彼此", "您", "心"}},
+	{Name: "logic", Label: "逻辑连接", Markers: []string{"首先", "其次", "最后", "第一", "第二", "第三", "因此", "那么", "既然", "本质", "逻辑", "前提", "推理", "结论", "换句话说", "维度", "层面"}},
+	{Name: "contrast", Label: "转折对比", Markers: []string{"但", "却", "而是", "不是", "实际上", "表面", "其实", "可是"}},
+	{Name: "metaphor", Label: "比喻类比", Markers: []string{"就像", "好比", "如同", "仿佛", "犹如", "有什么区别"}},
+	{Name: "formal", Label: "正式书面语", Markers: []string{"我们要", "坚持", "推动", "进一步", "全面", "稳步", "发展", "战略", "体系", "成效", "奠定"}},
+	{Name: "sentence_length", Label: "平均句长（每10字）", extract: func(text string, chars, sentences int) float64 {
+		return float64(chars) / float64(sentences) / 10
+	}},
+}
+
+// featureVector 计算文本的特征向量
+func featureVector(text string) []float64 {
+	chars := contentLength(text)
+	sentences := sentenceCount(text)
+	vector := make([]float64, len(features))
+	if chars == 0 {
+		return vector
+	}
+	for i, f := range features {
+		if f.extract != nil {
+			vector[i] = f.extract(text, chars, sentences)
+			continue
+		}
+		count := 0
+		for _, marker := range f.Markers {
+			count += strings.Count(text, marker)
+		}
+		// 数字串也算数据
+		if f.Name == "data" {
+			count += numberCount(text)
+		}
+		if f.PerLine {
+			vector[i] = float64(count) / float64(sentences)
+		} else {
+			vector[i] = float64(count) * 100 / float64(chars)
+		}
+	}
+	return vector
+}
+
+// contentLength 统计文字数，不含标点和空白
+func contentLength(text string) int {
+	count := 0
+	for _, r := range text {
+		if isContentRune(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// sentenceCount 统计句子数，至少为1
+func sentenceCount(text string) int {
+	count := 0
+	inSentence := false
+	for _, r := range text {
+		if strings.ContainsRune("。！？!?；;\n", r) {
+			if inSentence {
+				count++
+			}
+			inSentence = false
+		} else if isContentRune(r) {
+			inSentence = true
+		}
+	}
+	if inSentence {
+		count++
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// numberCount 统计连续数字串的个数
+func numberCount(text string) int {
+	count := 0
+	inNumber := false
+	for _, r := range text {
+		digit := unicode.IsDigit(r)
+		if digit && !inNumber {
+			count++
+		}
+		inNumber = digit
+	}
+	return count
+}
+
+// isContentRune 判断是否是文字（汉字、字母、数字）
+func isContentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,483 @@
+// Package style 本地离线的名人风格分类器
+// 用各名人风格的语料训练字n-gram画像和风格特征分布，判断一段回答最接近哪种风格，
+// 在AI评估不可用时代替风格符合度评分，也可以作为AI评分之外的参考意见
+package style
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"reactedge/internal/ai"
+)
+
+//go:embed corpus/*.txt
+var embedded embed.FS
+
+// hanhanPersona 韩寒风格的标识，语料来自HanStyleAI
+const hanhanPersona = "hanhan"
+
+// 至少多少字的回答分类结果才比较可靠
+const minReliableChars = 20
+
+// Corpus 一种风格的训练语料，每条样本一段话
+type Corpus struct {
+	Persona string   `json:"persona"`
+	Name    string   `json:"name"`
+	Samples []string `json:"samples"`
+}
+
+// ParseCorpus 解析语料文件：每行一条样本，#开头的行是注释，"# name: 名称"指定显示名称
+func ParseCorpus(persona string, data []byte) Corpus {
+	corpus := Corpus{Persona: persona, Name: persona}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), "name:"); ok {
+				corpus.Name = strings.TrimSpace(name)
+			}
+			continue
+		}
+		corpus.Samples = append(corpus.Samples, line)
+	}
+	return corpus
+}
+
+// DefaultCorpora 返回内置的四种名人风格语料，韩寒风格合并HanStyleAI的语料
+func DefaultCorpora() []Corpus {
+	entries, err := fs.ReadDir(embedded, "corpus")
+	if err != nil {
+		panic(fmt.Sprintf("读取内置风格语料失败: %v", err))
+	}
+	var corpora []Corpus
+	for _, entry := range entries {
+		data, err := embedded.ReadFile("corpus/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("读取内置风格语料失败: %v", err))
+		}
+		persona := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		corpus := ParseCorpus(persona, data)
+		if persona == hanhanPersona {
+			// 韩寒语料只在HanStyleAI中维护一份
+			corpus.Samples = append(ai.HanStyleCorpus(), corpus.Samples...)
+		}
+		corpora = append(corpora, corpus)
+	}
+	return corpora
+}
+
+// profile 一种风格的画像
+type profile struct {
+	persona string
+	name    string
+	counts  map[string]float64 // 语料中各n-gram的次数
+	ngrams  map[string]float64 // 归一化的TF-IDF向量
+	mean    []float64          // 各特征在样本中的平均值
+}
+
+// Classifier 风格分类器，训练后只读，可并发使用
+type Classifier struct {
+	profiles []*profile
+	idf      map[string]float64
+	scale    []float64 // 各特征在全部样本中的标准差，用于归一化
+}
+
+// Train 用语料训练分类器
+func Train(corpora []Corpus) (*Classifier, error) {
+	classifier := &Classifier{idf: make(map[string]float64), scale: make([]float64, len(features))}
+
+	var allVectors [][]float64
+	counts := make([]map[string]float64, 0, len(corpora))
+	for _, corpus := range corpora {
+		if len(corpus.Samples) == 0 {
+			continue
+		}
+		p := &profile{persona: corpus.Persona, name: corpus.Name, mean: make([]float64, len(features))}
+		if p.name == "" {
+			p.name = p.persona
+		}
+
+		grams := make(map[string]float64)
+		for _, sample := range corpus.Samples {
+			for gram, count := range ngramCounts(sample) {
+				grams[gram] += count
+			}
+			vector := featureVector(sample)
+			allVectors = append(allVectors, vector)
+			for i, value := range vector {
+				p.mean[i] += value / float64(len(corpus.Samples))
+			}
+		}
+		for gram := range grams {
+			classifier.idf[gram]++
+		}
+		counts = append(counts, grams)
+		classifier.profiles = append(classifier.profiles, p)
+	}
+	if len(classifier.profiles) < 2 {
+		return nil, fmt.Errorf("风格分类器至少需要两种风格的语料")
+	}
+
+	// 只在少数风格中出现的n-gram更有区分度
+	for gram, df := range classifier.idf {
+		classifier.idf[gram] = math.Log(float64(len(classifier.profiles)+1) / df)
+	}
+	for i, p := range classifier.profiles {
+		p.counts = counts[i]
+		p.ngrams = classifier.weigh(counts[i])
+	}
+
+	for i := range classifier.scale {
+		values := make([]float64, len(allVectors))
+		for j, vector := range allVectors {
+			values[j] = vector[i]
+		}
+		_, std := meanStdDev(values)
+		if std < 0.01 {
+			std = 1
+		}
+		classifier.scale[i] = std
+	}
+	return classifier, nil
+}
+
+var (
+	defaultClassifier *Classifier
+	defaultMutex      sync.RWMutex
+)
+
+// Default 返回当前使用的分类器，首次调用时用内置语料训练
+func Default() *Classifier {
+	defaultMutex.RLock()
+	classifier := defaultClassifier
+	defaultMutex.RUnlock()
+	if classifier != nil {
+		return classifier
+	}
+
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultClassifier == nil {
+		classifier, err := Train(DefaultCorpora())
+		if err != nil {
+			// 内置语料有测试保证，这里出错说明构建有问题
+			panic(fmt.Sprintf("训练内置风格分类器失败: %v", err))
+		}
+		defaultClassifier = classifier
+	}
+	return defaultClassifier
+}
+
+// SetDefault 替换默认分类器，例如加入了自定义风格后重新训练；传nil恢复内置分类器
+func SetDefault(classifier *Classifier) {
+	defaultMutex.Lock()
+	defaultClassifier = classifier
+	defaultMutex.Unlock()
+}
+
+// Personas 返回分类器支持的风格标识
+func (c *Classifier) Personas() []string {
+	personas := make([]string, len(c.profiles))
+	for i, p := range c.profiles {
+		personas[i] = p.persona
+	}
+	return personas
+}
+
+// PersonaScore 与一种风格的接近程度
+type PersonaScore struct {
+	Persona     string  `json:"persona"`
+	Name        string  `json:"name"`
+	Similarity  float64 `json:"similarity"`  // 0-1，字词相似度和特征相似度的平均
+	Probability float64 `json:"probability"` // 各风格之间归一化后的概率
+}
+
+// Evidence 一项特征的证据
+type Evidence struct {
+	Feature  string  `json:"feature"`
+	Label    string  `json:"label"`
+	Value    float64 `json:"value"`    // 回答中的取值
+	Expected float64 `json:"expected"` // 目标风格语料中的平均值
+	Match    float64 `json:"match"`    // 0-1，越接近目标风格越高
+	Closest  string  `json:"closest"`  // 这项特征上最接近的风格
+}
+
+// Result 风格分类结果
+type Result struct {
+	Closest     string         `json:"closest"`
+	ClosestName string         `json:"closest_name"`
+	Target      string         `json:"target,omitempty"`  // 期望的风格，未指定或不认识时为空
+	Conformity  float64        `json:"conformity"`        // 0-10，与目标风格（未指定时为最接近风格）的符合度
+	Reliable    bool           `json:"reliable"`          // 回答太短时结果仅供参考
+	Scores      []PersonaScore `json:"scores"`            // 按相似度从高到低排列
+	Evidence    []Evidence     `json:"evidence"`          // 各特征与目标风格的对比
+	Phrases     []string       `json:"phrases,omitempty"` // 与目标风格语料共有的特征字词
+	Suggestions []string       `json:"suggestions,omitempty"`
+}
+
+// Classify 判断文本最接近哪种风格，expected可以是风格标识（如hanhan）或名称（如韩寒）
+func (c *Classifier) Classify(text, expected string) *Result {
+	vector := c.weigh(ngramCounts(text))
+	values := featureVector(text)
+
+	cosines := make([]float64, len(c.profiles))
+	maxCosine := 0.0
+	for i, p := range c.profiles {
+		cosines[i] = dot(vector, p.ngrams)
+		maxCosine = math.Max(maxCosine, cosines[i])
+	}
+
+	result := &Result{Reliable: contentLength(text) >= minReliableChars}
+	similarities := make([]float64, len(c.profiles))
+	total := 0.0
+	for i, p := range c.profiles {
+		// 字词相似度取相对值，避免短回答的余弦值整体偏低
+		lexical := 0.0
+		if maxCosine > 0 {
+			lexical = cosines[i] / maxCosine
+		}
+		similarities[i] = (lexical + c.featureSimilarity(values, p)) / 2
+		result.Scores = append(result.Scores, PersonaScore{
+			Persona:    p.persona,
+			Name:       p.name,
+			Similarity: round2(similarities[i]),
+		})
+		total += math.Exp(similarities[i] * 8)
+	}
+	for i := range result.Scores {
+		result.Scores[i].Probability = round2(math.Exp(similarities[i]*8) / total)
+	}
+
+	best := 0
+	for i := range similarities {
+		if similarities[i] > similarities[best] {
+			best = i
+		}
+	}
+	result.Closest, result.ClosestName = c.profiles[best].persona, c.profiles[best].name
+
+	target := best
+	if index := c.find(expected); index >= 0 {
+		target = index
+		result.Target = c.profiles[index].persona
+	}
+	result.Conformity = math.Round(similarities[target]*100) / 10
+	result.Evidence = c.evidence(values, c.profiles[target])
+	result.Phrases = topPhrases(vector, c.profiles[target], 5)
+	result.Suggestions = suggestions(result.Evidence, c.profiles[target])
+
+	sort.SliceStable(result.Scores, func(i, j int) bool {
+		return result.Scores[i].Similarity > result.Scores[j].Similarity
+	})
+	return result
+}
+
+// find 按标识或名称查找风格，找不到返回-1
+func (c *Classifier) find(persona string) int {
+	persona = strings.TrimSpace(persona)
+	if persona == "" {
+		return -1
+	}
+	for i, p := range c.profiles {
+		if strings.EqualFold(p.persona, persona) || p.name == persona {
+			return i
+		}
+	}
+	return -1
+}
+
+// featureSimilarity 特征向量与风格平均值的相似度，0-1
+func (c *Classifier) featureSimilarity(values []float64, p *profile) float64 {
+	sum := 0.0
+	for i, value := range values {
+		z := (value - p.mean[i]) / c.scale[i]
+		sum += z * z
+	}
+	return 1 / (1 + math.Sqrt(sum/float64(len(values))))
+}
+
+// evidence 逐项对比回答和目标风格的特征
+func (c *Classifier) evidence(values []float64, target *profile) []Evidence {
+	evidence := make([]Evidence, len(features))
+	for i, f := range features {
+		closest, closestDistance := "", math.Inf(1)
+		for _, p := range c.profiles {
+			if distance := math.Abs(values[i] - p.mean[i]); distance < closestDistance {
+				closest, closestDistance = p.persona, distance
+			}
+		}
+		z := (values[i] - target.mean[i]) / c.scale[i]
+		evidence[i] = Evidence{
+			Feature:  f.Name,
+			Label:    f.Label,
+			Value:    round2(values[i]),
+			Expected: round2(target.mean[i]),
+			Match:    round2(1 / (1 + math.Abs(z))),
+			Closest:  closest,
+		}
+	}
+	return evidence
+}
+
+// Trait 文本中比各风格平均水平更突出的特征
+type Trait struct {
+	Feature string  `json:"feature"`
+	Label   string  `json:"label"`
+	Value   float64 `json:"value"`
+	Score   float64 `json:"score"` // 高出各风格平均值多少个标准差
+}
+
+// Traits 返回文本中明显高于各风格平均水平的特征，按突出程度从高到低排列
+func (c *Classifier) Traits(text string) []Trait {
+	values := featureVector(text)
+	var traits []Trait
+	for i, f := range features {
+		average := 0.0
+		for _, p := range c.profiles {
+			average += p.mean[i] / float64(len(c.profiles))
+		}
+		if z := (values[i] - average) / c.scale[i]; z >= 0.5 {
+			traits = append(traits, Trait{Feature: f.Name, Label: f.Label, Value: round2(values[i]), Score: round2(z)})
+		}
+	}
+	sort.SliceStable(traits, func(i, j int) bool { return traits[i].Score > traits[j].Score })
+	return traits
+}
+
+// suggestions 根据偏离最大的特征给出建议
+// 回答在某项特征上比目标风格更突出、且这项特征本就最接近目标风格时不算问题
+func suggestions(evidence []Evidence, target *profile) []string {
+	sorted := append([]Evidence(nil), evidence...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Match < sorted[j].Match })
+
+	var tips []string
+	for _, e := range sorted {
+		if e.Match >= 0.5 || len(tips) >= 3 {
+			break
+		}
+		if e.Value < e.Expected {
+			tips = append(tips, fmt.Sprintf("%s偏少，%s风格中更常见", e.Label, target.name))
+		} else if e.Closest != target.persona {
+			tips = append(tips, fmt.Sprintf("%s偏多，%s风格中较少使用", e.Label, target.name))
+		}
+	}
+	return tips
+}
+
+// weigh 把n-gram计数转成归一化的TF-IDF向量，没见过的n-gram忽略
+func (c *Classifier) weigh(counts map[string]float64) map[string]float64 {
+	vector := make(map[string]float64, len(counts))
+	norm := 0.0
+	for gram, count := range counts {
+		idf, ok := c.idf[gram]
+		if !ok || idf == 0 {
+			continue
+		}
+		weight := (1 + math.Log(count)) * idf
+		vector[gram] = weight
+		norm += weight * weight
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for gram := range vector {
+			vector[gram] /= norm
+		}
+	}
+	return vector
+}
+
+// ngramCounts 统计1-3字的n-gram，不跨越标点
+func ngramCounts(text string) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, chunk := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isContentRune(r) }) {
+		runes := []rune(chunk)
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				counts[string(runes[i:i+n])]++
+			}
+		}
+	}
+	return counts
+}
+
+// topPhrases 返回对相似度贡献最大的多字n-gram，只取在目标风格语料中反复出现的
+func topPhrases(vector map[string]float64, target *profile, limit int) []string {
+	type contribution struct {
+		gram  string
+		value float64
+	}
+	var contributions []contribution
+	for gram, weight := range vector {
+		if len([]rune(gram)) < 2 || target.counts[gram] < 2 {
+			continue
+		}
+		contributions = append(contributions, contribution{gram, weight * target.ngrams[gram]})
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].value != contributions[j].value {
+			return contributions[i].value > contributions[j].value
+		}
+		return contributions[i].gram < contributions[j].gram
+	})
+
+	var phrases []string
+	for _, c := range contributions {
+		// 已有更长的短语包含它时跳过
+		covered := false
+		for _, phrase := range phrases {
+			if strings.Contains(phrase, c.gram) || strings.Contains(c.gram, phrase) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			phrases = append(phrases, c.gram)
+		}
+		if len(phrases) >= limit {
+			break
+		}
+	}
+	return phrases
+}
+
+// dot 两个稀疏向量的点积
+func dot(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	sum := 0.0
+	for key, value := range a {
+		sum += value * b[key]
+	}
+	return sum
+}
+
+// meanStdDev 计算平均值和标准差
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// round2 保留两位小数
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,72 @@
+package style
+
+import (
+	"testing"
+
+	"reactedge/internal/ai"
+)
+
+// TestClassify 测试各风格的典型回答能被分到对应风格，并给出证据和期望风格的符合度
+func TestClassify(t *testing.T) {
+	classifier := Default()
+	if len(classifier.Personas()) != 4 {
+		t.Fatalf("内置语料应包含四种风格: %v", classifier.Personas())
+	}
+
+	cases := map[string]string{
+		"hanhan":    "难道加班就等于努力吗？如果工时能衡量价值，那机器早就是劳模了。表面上是敬业，实际上是低效。",
+		"dongqing":  "我特别能理解大家的辛苦，谢谢每一位同事的付出，我们一起想想办法，让彼此都轻松一些。",
+		"chengming": "首先，我们要明确问题的前提；其次，分析成本结构；因此，结论是方案需要调整，关键在于机制。",
+		"kanghui":   "根据统计，本季度销售额同比增长8.5%，客户满意度达到95%，各项指标稳步提升，我们将进一步推动发展。",
+	}
+	for persona, text := range cases {
+		result := classifier.Classify(text, "")
+		if result.Closest != persona || result.Scores[0].Persona != persona {
+			t.Errorf("%s的回答被分到了%s: %+v", persona, result.Closest, result.Scores)
+		}
+		if !result.Reliable || len(result.Evidence) != len(features) || len(result.Phrases) == 0 {
+			t.Errorf("%s的分类结果缺少证据: %+v", persona, result)
+		}
+	}
+
+	// 按名称指定期望风格时，符合度针对期望风格计算
+	hanhan := classifier.Classify(cases["hanhan"], "韩寒")
+	dongqing := classifier.Classify(cases["hanhan"], "dongqing")
+	if hanhan.Target != "hanhan" || dongqing.Target != "dongqing" || hanhan.Conformity <= dongqing.Conformity {
+		t.Errorf("期望风格的符合度不正确: %v %v", hanhan.Conformity, dongqing.Conformity)
+	}
+	if len(dongqing.Suggestions) == 0 {
+		t.Errorf("与期望风格差距大时应给出建议")
+	}
+
+	if short := classifier.Classify("好的", "unknown"); short.Reliable || short.Target != "" {
+		t.Errorf("短回答应标记为不可靠，未知风格应忽略: %+v", short)
+	}
+
+	if _, err := Train([]Corpus{{Persona: "a", Samples: []string{"只有一种"}}}); err == nil {
+		t.Errorf("只有一种风格时应返回错误")
+	}
+}
+
+// TestDefaultCorporaUsesHanStyleCorpus 韩寒风格的训练语料包含HanStyleAI的语料，且不重复
+func TestDefaultCorporaUsesHanStyleCorpus(t *testing.T) {
+	for _, corpus := range DefaultCorpora() {
+		if corpus.Persona != hanhanPersona {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, sample := range corpus.Samples {
+			if seen[sample] {
+				t.Errorf("韩寒语料重复: %s", sample)
+			}
+			seen[sample] = true
+		}
+		for _, sample := range ai.HanStyleCorpus() {
+			if !seen[sample] {
+				t.Errorf("韩寒语料缺少HanStyleAI的样本: %s", sample)
+			}
+		}
+		return
+	}
+	t.Fatal("内置语料缺少韩寒风格")
+}
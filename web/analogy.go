@@ -0,0 +1,70 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"reactedge/internal/ai"
+)
+
+// loadAnalogies 加载编辑维护的类比库目录，目录不存在或有错误时使用内置类比库
+func (s *Server) loadAnalogies() error {
+	if s.config == nil || s.config.Analogies.Dir == "" {
+		return nil
+	}
+	base, err := ai.LoadAnalogyBase(s.config.Analogies.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.aiEngine.SetAnalogyBase(base)
+	return nil
+}
+
+// handleAnalogies 查看类比库：不带参数时列出各领域的类比数，
+// domain参数列出该领域的类比，topic参数按与话题的相关度排列
+func (s *Server) handleAnalogies(w http.ResponseWriter, r *http.Request) {
+	base := s.aiEngine.AnalogyBase()
+	domain := r.URL.Query().Get("domain")
+	topic := r.URL.Query().Get("topic")
+
+	switch {
+	case topic != "":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"domain":    domain,
+			"topic":     topic,
+			"analogies": base.Relevant(domain, topic),
+		})
+	case domain != "":
+		analogies := base.Analogies(domain)
+		if len(analogies) == 0 {
+			http.Error(w, tr(r, "error.analogy_domain_not_found"), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"domain": domain, "analogies": analogies})
+	default:
+		counts := make(map[string]int)
+		for _, name := range base.Domains() {
+			counts[name] = len(base.Analogies(name))
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"domains": counts})
+	}
+}
+
+// handleAnalogiesReload 重新加载类比库目录，编辑修改文件后不需要重启服务
+func (s *Server) handleAnalogiesReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.loadAnalogies(); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	fmt.Println("✅ 类比库已重新加载")
+	s.handleAnalogies(w, r)
+}
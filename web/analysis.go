@@ -0,0 +1,124 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"reactedge/internal/ai"
+	"reactedge/internal/analysis"
+	"reactedge/pkg/style"
+)
+
+// loadLexicon 加载配置的赘词词表和用户画像词表，文件不存在时使用内置词表
+func (s *Server) loadLexicon() {
+	if s.config == nil {
+		return
+	}
+	s.loadProfileLexicon()
+	if s.config.Analysis.LexiconFile == "" {
+		return
+	}
+	lexicon, err := analysis.LoadLexicon(s.config.Analysis.LexiconFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("⚠️ 赘词词表加载失败，使用内置词表: %v\n", err)
+		return
+	}
+	analysis.SetDefaultLexicon(lexicon)
+}
+
+// loadProfileLexicon 加载配置的用户画像词表，文件不存在或有错误时使用内置词表
+func (s *Server) loadProfileLexicon() {
+	if s.config.Analysis.ProfileLexiconFile == "" {
+		return
+	}
+	lexicon, err := ai.LoadProfileLexicon(s.config.Analysis.ProfileLexiconFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("⚠️ 用户画像词表加载失败，使用内置词表: %v\n", err)
+		return
+	}
+	ai.SetDefaultProfileLexicon(lexicon)
+}
+
+// handleDisfluency 检测文本中的口头禅、犹豫词、重复和程度副词，返回位置供前端高亮
+func (s *Server) handleDisfluency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" {
+		http.Error(w, tr(r, "error.empty_field", "text"), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, analysis.DetectDisfluencies(req.Text))
+}
+
+// handleStyleClassify 用本地风格分类器判断回答最接近哪种名人风格，persona为期望的风格（可选）
+func (s *Server) handleStyleClassify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Text    string `json:"text"`
+		Persona string `json:"persona"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" {
+		http.Error(w, tr(r, "error.empty_field", "text"), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, style.Default().Classify(req.Text, req.Persona))
+}
+
+// handleSpeechAnalysis 分析一段回答文本的语速、节奏、清晰度和信心，按回答语言评分，建议按请求语言给出
+// duration为回答时长（秒），可选，没有时不计算语速
+func (s *Server) handleSpeechAnalysis(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Text     string  `json:"text"`
+		Duration float64 `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" {
+		http.Error(w, tr(r, "error.empty_field", "text"), http.StatusBadRequest)
+		return
+	}
+
+	analyzer := analysis.NewSpeechAnalyzer()
+	result := analyzer.AnalyzeText(req.Text, time.Duration(req.Duration*float64(time.Second)))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"result": result,
+		"tips":   analyzer.GetSpeechTips(result, requestLocale(r)),
+	})
+}
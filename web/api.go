@@ -0,0 +1,191 @@
+package web
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"reactedge/internal/corpus"
+	"reactedge/internal/i18n"
+	"reactedge/internal/user"
+)
+
+// APIVersion 当前JSON接口的版本，接口地址以/api/v1开头
+const APIVersion = "v1"
+
+// apiPrefix JSON接口的路径前缀
+const apiPrefix = "/api/" + APIVersion
+
+// openAPIDocument 接口描述，启动时转成JSON，在/api/openapi.json提供
+//
+//go:embed openapi.yaml
+var openAPIDocument []byte
+
+// openAPIJSON 转成JSON的接口描述
+var openAPIJSON = func() []byte {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(openAPIDocument, &document); err != nil {
+		// 接口描述随程序编译，这里出错说明构建有问题
+		panic(fmt.Sprintf("解析接口描述失败: %v", err))
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		panic(fmt.Sprintf("转换接口描述失败: %v", err))
+	}
+	return data
+}()
+
+// demoContents 演示页面可选的经典内容
+var demoContents = []string{"news", "poetry", "blog", "debate"}
+
+// generateRequest 生成回答的请求
+type generateRequest struct {
+	Style    string `json:"style"`    // 风格标识，内置风格或自定义风格
+	Content  string `json:"content"`  // 参考的经典内容，如news、poetry
+	Question string `json:"question"` // 职场问题
+}
+
+// generateResponse 生成回答的结果
+type generateResponse struct {
+	Response  string           `json:"response"`
+	AttemptID string           `json:"attempt_id"`
+	AudioURL  string           `json:"audio_url"`
+	Citations []corpus.Passage `json:"citations"`
+}
+
+// apiStyle 可选的风格
+type apiStyle struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Label       string `json:"label"` // 按请求语言组织的显示名称
+	Builtin     bool   `json:"builtin"`
+	Base        string `json:"base,omitempty"` // 自定义风格最接近的内置风格
+}
+
+// apiContent 可选的经典内容
+type apiContent struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// setupAPIRoutes 注册版本化JSON接口，与页面使用的旧地址共用处理函数；错误统一返回{"error": "..."}
+func (s *Server) setupAPIRoutes() {
+	routes := map[string]http.HandlerFunc{
+		"/styles":                     s.handleAPIStyles,
+		"/generate":                   s.handleGenerate,
+		"/challenges":                 s.handleChallengeStart,
+		"/challenges/current":         s.handleChallengeState,
+		"/challenges/current/advance": s.handleChallengeAdvance,
+		"/challenges/current/speech":  s.handleChallengeSpeech,
+		"/challenges/current/audio":   s.handleChallengeAudio,
+		"/analysis/speech":            s.handleSpeechAnalysis,
+		"/analysis/disfluency":        s.handleDisfluency,
+		"/analysis/style":             s.handleStyleClassify,
+		"/fingerprint":                s.handleFingerprint,
+		"/fingerprint/compare":        s.handleFingerprintCompare,
+	}
+	for path, handler := range routes {
+		s.router.HandleFunc(apiPrefix+path, apiHandler(user.Require(handler)))
+	}
+	s.router.HandleFunc(apiPrefix+"/", apiHandler(http.NotFound))
+	s.router.HandleFunc("/api/openapi.json", handleOpenAPI)
+	s.router.HandleFunc("/api/websocket.schema.json", handleWebSocketSchema)
+}
+
+// handleOpenAPI 提供OpenAPI 3格式的接口描述，不需要登录
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIJSON)
+}
+
+// handleAPIStyles 列出当前用户可用的风格和演示用的经典内容
+func (s *Server) handleAPIStyles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	locale := requestLocale(r)
+	catalog := i18n.Default()
+	u := currentUser(r)
+
+	styles := []apiStyle{}
+	for _, p := range s.personas.List(u.ID, u.IsAdmin()) {
+		style := apiStyle{
+			Key:         p.Key,
+			Name:        p.Name,
+			Description: p.Description,
+			Builtin:     p.Builtin,
+			Base:        p.Base,
+		}
+		if label, ok := catalog.Lookup(locale, "page.demo.style."+p.Key); ok && p.Builtin {
+			style.Label = label
+		} else {
+			style.Label = p.Name + catalog.T(locale, "page.demo.custom") + p.Description
+		}
+		styles = append(styles, style)
+	}
+
+	contents := make([]apiContent, len(demoContents))
+	for i, key := range demoContents {
+		contents[i] = apiContent{Key: key, Label: catalog.T(locale, "page.demo.content."+key)}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"styles": styles, "contents": contents})
+}
+
+// apiHandler 把处理函数用http.Error输出的纯文本错误改写成JSON，方便其他服务统一处理
+func apiHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writer := &apiErrorWriter{ResponseWriter: w}
+		next(writer, r)
+		writer.finish()
+	}
+}
+
+// apiErrorWriter 截留纯文本的错误响应，处理结束后以JSON输出
+type apiErrorWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader 错误状态且内容为纯文本时截留，其他响应直接写出
+func (w *apiErrorWriter) WriteHeader(status int) {
+	if status >= http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write 截留错误消息
+func (w *apiErrorWriter) Write(data []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap 供http.ResponseController访问底层连接
+func (w *apiErrorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish 输出截留的错误
+func (w *apiErrorWriter) finish() {
+	if w.status == 0 {
+		return
+	}
+	writeJSON(w.ResponseWriter, w.status, map[string]string{"error": strings.TrimSpace(w.body.String())})
+}
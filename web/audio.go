@@ -0,0 +1,324 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"reactedge/internal/challenge"
+	"reactedge/internal/i18n"
+	"reactedge/internal/user"
+	"reactedge/pkg/audio"
+	"reactedge/pkg/prompt"
+)
+
+// maxAudioSize 录音大小上限，与OpenAI转写接口的25MB限制一致
+const maxAudioSize = 25 << 20
+
+// newTranscriber 优先使用AI服务的语音转写接口，不可用时使用本地转写
+func (s *Server) newTranscriber() audio.Transcriber {
+	if s.aiManager != nil {
+		if transcriber := s.aiManager.Transcriber(); transcriber != nil {
+			return transcriber
+		}
+	}
+	fmt.Println("⚠️ 没有可用的语音识别服务，录音将使用客户端提交的识别文本")
+	return audio.NewLocalTranscriber()
+}
+
+// transcribe 转写录音；远程转写失败且客户端提交了识别文本时退化为本地转写
+func (s *Server) transcribe(ctx context.Context, clip *audio.Clip) (*audio.Transcript, error) {
+	transcript, err := s.transcriber.Transcribe(ctx, clip)
+	if err == nil || clip.Hint == "" {
+		return transcript, err
+	}
+	if _, isLocal := s.transcriber.(*audio.LocalTranscriber); isLocal {
+		return nil, err
+	}
+
+	log.Printf("语音转写失败，使用客户端识别文本: %v", err)
+	return audio.NewLocalTranscriber().Transcribe(ctx, clip)
+}
+
+// handleChallengeAudio 上传录音回答，转写后提交到当前挑战
+// 支持multipart表单（audio文件字段）或直接以audio/*作为请求体；
+// 参数language、hint、sample_rate、channels可放在表单或查询字符串中
+func (s *Server) handleChallengeAudio(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAudioSize)
+	var (
+		data        []byte
+		contentType string
+		err         error
+	)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, formErr := r.FormFile("audio")
+		if formErr != nil {
+			http.Error(w, tr(r, "error.read_upload_failed", "audio", formErr.Error()), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		contentType = header.Header.Get("Content-Type")
+		data, err = io.ReadAll(file)
+	} else {
+		contentType = r.Header.Get("Content-Type")
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, tr(r, "error.read_audio_failed", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	clip, err := newAudioClip(requestLocale(r), data, contentType, r.FormValue)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.interactionTimeout())
+	defer cancel()
+
+	transcript, err := s.transcribe(ctx, clip)
+	if errors.Is(err, audio.ErrNoTranscript) || errors.Is(err, audio.ErrUnsupportedFormat) {
+		http.Error(w, trError(r, err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, trError(r, err), http.StatusBadGateway)
+		return
+	}
+	if transcript.Text == "" {
+		http.Error(w, tr(r, "error.no_speech"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	userID := currentUser(r).ID
+	state := s.challenges.SubmitTranscript(userID, transcript, decodeClip(clip))
+	if state == nil {
+		http.Error(w, tr(r, "error.challenge_not_started"), http.StatusNotFound)
+		return
+	}
+	state = s.refineChallengeProfile(ctx, userID, transcript.Text, state)
+	s.writeChallenge(w, r, state)
+}
+
+// decodeClip 解码WAV/PCM录音用于声学分析，压缩格式返回nil
+func decodeClip(clip *audio.Clip) *audio.PCM {
+	pcm, err := clip.Decode()
+	if err != nil {
+		if !errors.Is(err, audio.ErrUnsupportedFormat) {
+			log.Printf("解码录音失败，跳过声学分析: %v", err)
+		}
+		return nil
+	}
+	return pcm
+}
+
+// newAudioClip 根据数据和参数构建录音，get用于读取language等参数，错误信息按locale翻译
+func newAudioClip(locale string, data []byte, contentType string, get func(string) string) (*audio.Clip, error) {
+	if len(data) == 0 {
+		return nil, errors.New(i18n.T(locale, "error.empty_audio"))
+	}
+
+	clip := &audio.Clip{
+		Data:     data,
+		Format:   audio.Format(get("format")),
+		Language: get("language"),
+		Hint:     get("hint"),
+	}
+	if clip.Format == "" {
+		clip.Format = audio.DetectFormat(data, contentType)
+	}
+	if clip.Format == "" {
+		return nil, errors.New(i18n.T(locale, "error.unknown_audio_format"))
+	}
+
+	for param, target := range map[string]*int{"sample_rate": &clip.SampleRate, "channels": &clip.Channels} {
+		if value := get(param); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number <= 0 {
+				return nil, errors.New(i18n.T(locale, "error.positive_integer", param))
+			}
+			*target = number
+		}
+	}
+	return clip, nil
+}
+
+// wsAudioStream 一条WebSocket连接上正在接收的录音
+// 客户端先发送audio_start，再以二进制帧发送录音数据，最后发送audio_end
+type wsAudioStream struct {
+	version int    // audio_start消息的协议版本
+	id      string // audio_start消息的id，录音数据出错时回复给它
+	params  map[string]string
+	buf     bytes.Buffer
+}
+
+// wsAudioStartPayload 开始接收录音，参数同 /challenge/audio
+type wsAudioStartPayload struct {
+	Format     string `json:"format"`
+	Language   string `json:"language"`
+	SampleRate int    `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+}
+
+// wsAudioEndPayload 录音发送完毕，hint是客户端识别的文本
+type wsAudioEndPayload struct {
+	Hint string `json:"hint"`
+}
+
+// wsTranscript 转写结果和提交后的挑战状态
+type wsTranscript struct {
+	Transcript *audio.Transcript         `json:"transcript"`
+	State      *challenge.ChallengeState `json:"state"`
+	Content    map[string]interface{}    `json:"content"`
+}
+
+// handleWebSocketAudioStart 开始接收录音
+func (s *Server) handleWebSocketAudioStart(ws *wsSession, req *wsRequest) {
+	var payload wsAudioStartPayload
+	if !ws.decode(req, &payload) {
+		return
+	}
+
+	stream := &wsAudioStream{version: req.Version, id: req.ID, params: map[string]string{
+		"format":   payload.Format,
+		"language": payload.Language,
+	}}
+	if payload.SampleRate > 0 {
+		stream.params["sample_rate"] = strconv.Itoa(payload.SampleRate)
+	}
+	if payload.Channels > 0 {
+		stream.params["channels"] = strconv.Itoa(payload.Channels)
+	}
+	if !ws.setAudio(stream) {
+		ws.replyError(req, i18n.T(ws.locale, "error.audio_stream_limit", maxAudioStreamsPerUser))
+		return
+	}
+	ws.write(req.Version, req.ID, wsTypeStatus, wsStatus{Stage: "audio_receiving", Message: i18n.T(ws.locale, "status.audio_receiving")})
+}
+
+// audioKey 录音名额的计数键：登录用户按用户ID，匿名用户各连接互不相同，
+// 避免关闭认证时全部客户端共用一个用户的名额
+func (ws *wsSession) audioKey() string {
+	if ws.viewer.ID == user.AnonymousID {
+		return fmt.Sprintf("%s@%p", user.AnonymousID, ws)
+	}
+	return ws.viewer.ID
+}
+
+// setAudio 开始接收新录音，替换连接上未结束的录音；用户同时接收的录音已达上限时返回false
+func (ws *wsSession) setAudio(stream *wsAudioStream) bool {
+	if ws.audio == nil && !ws.hub.acquireAudio(ws.audioKey()) {
+		return false
+	}
+	ws.audio = stream
+	return true
+}
+
+// clearAudio 结束正在接收的录音并释放名额
+func (ws *wsSession) clearAudio() {
+	if ws.audio != nil {
+		ws.audio = nil
+		ws.hub.releaseAudio(ws.audioKey())
+	}
+}
+
+// appendAudio 把二进制帧追加到正在接收的录音
+func (ws *wsSession) appendAudio(data []byte) {
+	stream := ws.audio
+	if stream == nil {
+		ws.write(wsProtocolVersion, "", wsTypeError, wsError{Message: i18n.T(ws.locale, "error.audio_not_started")})
+		return
+	}
+	if !stream.append(data) {
+		ws.clearAudio()
+		ws.write(stream.version, stream.id, wsTypeError, wsError{Message: i18n.T(ws.locale, "error.audio_too_large", maxAudioSize>>20)})
+	}
+}
+
+// append 追加二进制帧，超过上限时返回false
+func (stream *wsAudioStream) append(data []byte) bool {
+	if stream.buf.Len()+len(data) > maxAudioSize {
+		return false
+	}
+	stream.buf.Write(data)
+	return true
+}
+
+// handleWebSocketAudioEnd 录音接收完毕，异步转写并提交到当前挑战，转写可以用cancel取消
+func (s *Server) handleWebSocketAudioEnd(ws *wsSession, req *wsRequest) {
+	stream := ws.audio
+	if stream == nil {
+		ws.replyError(req, i18n.T(ws.locale, "error.no_audio_stream"))
+		return
+	}
+	ws.clearAudio()
+
+	var payload wsAudioEndPayload
+	if !ws.decode(req, &payload) {
+		return
+	}
+	stream.params["hint"] = payload.Hint
+
+	clip, err := newAudioClip(ws.locale, stream.buf.Bytes(), "", func(key string) string { return stream.params[key] })
+	if err != nil {
+		ws.replyError(req, err.Error())
+		return
+	}
+
+	call, err := ws.begin(req)
+	if err != nil {
+		ws.replyError(req, err.Error())
+		return
+	}
+	call.send(wsTypeStatus, wsStatus{Stage: "transcribing", Message: i18n.T(ws.locale, "status.transcribing")})
+
+	go func() {
+		defer call.cancel()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("WebSocket语音转写panic: %v", r)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(prompt.WithLanguage(call.ctx, ws.locale), s.interactionTimeout())
+		defer cancel()
+
+		transcript, err := s.transcribe(ctx, clip)
+		if err != nil {
+			call.fail(errorText(ws.locale, err))
+			return
+		}
+		if transcript.Text == "" {
+			call.fail(i18n.T(ws.locale, "error.no_speech"))
+			return
+		}
+
+		// 取消后不再提交到挑战
+		if !call.finish() {
+			return
+		}
+		state := s.challenges.SubmitTranscript(ws.viewer.ID, transcript, decodeClip(clip))
+		if state == nil {
+			call.write(wsTypeError, wsError{Message: i18n.T(ws.locale, "error.challenge_not_started")})
+			return
+		}
+		state = s.refineChallengeProfile(ctx, ws.viewer.ID, transcript.Text, state)
+		call.write(wsTypeTranscript, wsTranscript{
+			Transcript: transcript,
+			State:      state,
+			Content:    s.challenges.GetPhaseContent(state, ws.locale),
+		})
+	}()
+}
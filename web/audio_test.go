@@ -0,0 +1,98 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"reactedge/config"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialWebSocket 用客户端的会话Cookie建立WebSocket连接
+func dialWebSocket(t *testing.T, serverURL string, client *http.Client) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Jar: client.Jar, HandshakeTimeout: 5 * time.Second}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(serverURL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("建立WebSocket连接失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readReply 读取一条服务端消息
+func readReply(t *testing.T, conn *websocket.Conn) wsReply {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var reply wsReply
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("读取消息失败: %v", err)
+	}
+	return reply
+}
+
+// TestWebSocketAudioLimits 每个用户同时接收的录音数有上限，超过单条消息大小上限时断开连接
+func TestWebSocketAudioLimits(t *testing.T) {
+	server := newTestServer(t, nil)
+	client := newSession(t, server, "alice")
+
+	start := func() (*websocket.Conn, wsReply) {
+		conn := dialWebSocket(t, server.URL, client)
+		conn.WriteJSON(wsRequest{Version: wsProtocolVersion, ID: "a1", Action: wsActionAudioStart})
+		return conn, readReply(t, conn)
+	}
+
+	var first *websocket.Conn
+	for i := 0; i < maxAudioStreamsPerUser; i++ {
+		conn, reply := start()
+		if reply.Type != wsTypeStatus {
+			t.Fatalf("第%d个录音应开始接收: %+v", i+1, reply)
+		}
+		if first == nil {
+			first = conn
+		}
+	}
+	if _, reply := start(); reply.Type != wsTypeError {
+		t.Fatalf("超过上限的录音应被拒绝: %+v", reply)
+	}
+
+	// 连接断开后释放名额
+	first.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, reply := start()
+		if reply.Type == wsTypeStatus {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("连接断开后应释放录音名额")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	conn := dialWebSocket(t, server.URL, client)
+	conn.WriteMessage(websocket.BinaryMessage, bytes.Repeat([]byte{0}, wsMaxMessageSize+1))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("超过大小上限的消息应断开连接")
+	}
+}
+
+// TestWebSocketAnonymousAudioLimitPerConnection 关闭认证时录音名额按连接计算，匿名客户端不共用名额
+func TestWebSocketAnonymousAudioLimitPerConnection(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) { cfg.Auth.Enabled = false })
+
+	for i := 0; i < maxAudioStreamsPerUser+1; i++ {
+		conn := dialWebSocket(t, server.URL, &http.Client{})
+		conn.WriteJSON(wsRequest{Version: wsProtocolVersion, ID: "a1", Action: wsActionAudioStart})
+		if reply := readReply(t, conn); reply.Type != wsTypeStatus {
+			t.Fatalf("第%d个匿名连接的录音应开始接收: %+v", i+1, reply)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"reactedge/internal/i18n"
+	"reactedge/internal/user"
+)
+
+// newUserStore 根据配置创建用户存储，加载失败时退化为内存存储；配置了管理员账号时确保其存在
+func newUserStore(cfg *authSettings) *user.Store {
+	store, err := user.NewStore(cfg.DataFile, cfg.SessionTTL)
+	if err != nil {
+		fmt.Printf("⚠️ 用户数据加载失败，使用内存存储: %v\n", err)
+		store, _ = user.NewStore("", cfg.SessionTTL)
+	}
+
+	if cfg.AdminUsername != "" {
+		if admin, err := store.EnsureAdmin(cfg.AdminUsername, cfg.AdminPassword); err != nil {
+			fmt.Printf("⚠️ 创建管理员账号失败: %v\n", err)
+		} else {
+			fmt.Printf("👤 管理员账号: %s\n", admin.Username)
+		}
+	}
+	if cfg.Enabled && !store.HasAdmin() {
+		fmt.Println("⚠️ 还没有管理员账号，请配置 auth.admin_username 和 auth.admin_password")
+	}
+	return store
+}
+
+// authSettings 认证相关设置
+type authSettings struct {
+	Enabled           bool
+	DataFile          string
+	SessionTTL        time.Duration
+	CookieSecure      bool
+	AllowRegistration bool
+	AdminUsername     string
+	AdminPassword     string
+}
+
+// loadAuthSettings 读取认证配置，未提供配置时使用默认值
+func (s *Server) loadAuthSettings() *authSettings {
+	settings := &authSettings{
+		Enabled:           true,
+		DataFile:          "data/users.json",
+		SessionTTL:        7 * 24 * time.Hour,
+		AllowRegistration: false,
+	}
+	if s.config != nil {
+		settings.Enabled = s.config.Auth.Enabled
+		settings.DataFile = s.config.Auth.DataFile
+		settings.CookieSecure = s.config.Auth.CookieSecure
+		settings.AllowRegistration = s.config.Auth.AllowRegistration
+		settings.AdminUsername = s.config.Auth.AdminUsername
+		settings.AdminPassword = s.config.Auth.AdminPassword
+		if s.config.Auth.SessionTTL > 0 {
+			settings.SessionTTL = time.Duration(s.config.Auth.SessionTTL) * time.Hour
+		}
+	}
+	return settings
+}
+
+// currentUser 获取当前请求的用户
+func currentUser(r *http.Request) *user.User {
+	return user.FromContext(r.Context())
+}
+
+// targetUserID 获取要查询的用户ID，管理员可通过user_id参数查看其他用户
+func targetUserID(r *http.Request) string {
+	u := currentUser(r)
+	if id := r.URL.Query().Get("user_id"); id != "" && u.IsAdmin() {
+		return id
+	}
+	return u.ID
+}
+
+// credentialsRequest 注册和登录请求
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleRegister 注册新用户并直接登录
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.auth.AllowRegistration {
+		http.Error(w, tr(r, "error.registration_closed"), http.StatusForbidden)
+		return
+	}
+
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u, err := s.users.Register(req.Username, req.Password)
+	switch {
+	case errors.Is(err, user.ErrInvalidInput):
+		http.Error(w, trError(r, err), http.StatusBadRequest)
+		return
+	case errors.Is(err, user.ErrUserExists):
+		http.Error(w, trError(r, err), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, tr(r, "error.register_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Printf("👤 新用户注册: %s (%s)\n", u.Username, u.Role)
+	s.startSession(w, r, u)
+}
+
+// handleLogin 用户名密码登录
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u, err := s.users.Authenticate(req.Username, req.Password)
+	if err != nil {
+		http.Error(w, trError(r, err), http.StatusUnauthorized)
+		return
+	}
+
+	s.startSession(w, r, u)
+}
+
+// startSession 创建会话并写入Cookie
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, u *user.User) {
+	session, err := s.users.CreateSession(u.ID)
+	if err != nil {
+		http.Error(w, tr(r, "error.session_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     user.SessionCookie,
+		Value:    session.ID,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   s.auth.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": u})
+}
+
+// handleLogout 注销当前会话
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(user.SessionCookie); err == nil {
+		s.users.DeleteSession(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     user.SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   s.auth.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMe 返回当前登录用户
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user":         currentUser(r),
+		"auth_enabled": s.auth.Enabled,
+		"locale":       requestLocale(r),
+		"locales":      i18n.Default().Locales(),
+	})
+}
+
+// handleTokens 管理API令牌：GET列出，POST创建，DELETE吊销
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	u := currentUser(r)
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": s.users.ListTokens(u.ID)})
+
+	case "POST":
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		plain, token, err := s.users.CreateToken(u.ID, req.Name)
+		if err != nil {
+			http.Error(w, tr(r, "error.token_failed", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		// 明文令牌只返回这一次
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"token": plain, "info": token})
+
+	case "DELETE":
+		if err := s.users.RevokeToken(u.ID, r.URL.Query().Get("id")); err != nil {
+			http.Error(w, tr(r, "error.token_not_found"), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleLoginPage 登录/注册页面
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	renderPage(w, r, "login")
+}
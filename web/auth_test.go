@@ -0,0 +1,160 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"reactedge/config"
+	"reactedge/internal/user"
+)
+
+// doRequest 发送请求并返回状态码，out不为空时解析JSON响应
+func doRequest(t *testing.T, client *http.Client, method, url, token, body string, out interface{}) int {
+	t.Helper()
+
+	request, _ := http.NewRequest(method, url, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("请求%s失败: %v", url, err)
+	}
+	defer response.Body.Close()
+	if out != nil && response.StatusCode < 300 {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			t.Fatalf("解析%s的响应失败: %v", url, err)
+		}
+	}
+	return response.StatusCode
+}
+
+// TestRegistrationClosedByDefault 默认关闭自助注册
+func TestRegistrationClosedByDefault(t *testing.T) {
+	if config.GetDefaultConfig().Auth.AllowRegistration {
+		t.Fatal("默认配置应关闭自助注册")
+	}
+	server := newTestServer(t, func(cfg *config.Config) { cfg.Auth.AllowRegistration = false })
+
+	status := doRequest(t, http.DefaultClient, "POST", server.URL+"/auth/register", "", `{"username":"alice","password":"alice-password"}`, nil)
+	if status != http.StatusForbidden {
+		t.Fatalf("关闭注册时应返回403: %d", status)
+	}
+
+	// 错误信息按请求的语言返回
+	request, _ := http.NewRequest("POST", server.URL+"/auth/register", strings.NewReader(`{}`))
+	request.Header.Set("Accept-Language", "en-US")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("注册请求失败: %v", err)
+	}
+	defer response.Body.Close()
+	if body, _ := io.ReadAll(response.Body); !strings.Contains(string(body), "Registration is closed") {
+		t.Errorf("应返回英文错误信息: %s", body)
+	}
+}
+
+// TestAuthFlow 测试注册、会话Cookie、登录、API令牌和管理接口的权限
+func TestAuthFlow(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.AdminUsername, cfg.Auth.AdminPassword = "root", "root-password"
+	})
+
+	// 注册后直接登录，会话Cookie只能由服务端读取
+	jar, _ := cookiejar.New(nil)
+	alice := &http.Client{Jar: jar}
+	response, err := alice.Post(server.URL+"/auth/register", "application/json", strings.NewReader(`{"username":"alice","password":"alice-password"}`))
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	response.Body.Close()
+	var cookie *http.Cookie
+	for _, c := range response.Cookies() {
+		if c.Name == user.SessionCookie {
+			cookie = c
+		}
+	}
+	if response.StatusCode != http.StatusOK || cookie == nil || !cookie.HttpOnly {
+		t.Fatalf("注册后应设置HttpOnly会话Cookie: %d %+v", response.StatusCode, cookie)
+	}
+
+	var me struct {
+		User *user.User `json:"user"`
+	}
+	if status := doRequest(t, alice, "GET", server.URL+"/auth/me", "", "", &me); status != http.StatusOK || me.User.Username != "alice" || me.User.IsAdmin() {
+		t.Fatalf("注册的用户应为普通用户: %d %+v", status, me.User)
+	}
+	if status := doRequest(t, alice, "POST", server.URL+"/auth/register", "", `{"username":"Alice","password":"alice-password"}`, nil); status != http.StatusConflict {
+		t.Errorf("重复用户名应返回409: %d", status)
+	}
+
+	// 登录
+	anonymous := &http.Client{}
+	if status := doRequest(t, anonymous, "POST", server.URL+"/auth/login", "", `{"username":"alice","password":"wrong-password"}`, nil); status != http.StatusUnauthorized {
+		t.Errorf("错误密码应返回401: %d", status)
+	}
+	if status := doRequest(t, anonymous, "GET", server.URL+"/auth/me", "", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("未登录应返回401: %d", status)
+	}
+	jar, _ = cookiejar.New(nil)
+	relogin := &http.Client{Jar: jar}
+	if status := doRequest(t, relogin, "POST", server.URL+"/auth/login", "", `{"username":"alice","password":"alice-password"}`, nil); status != http.StatusOK {
+		t.Fatalf("登录失败: %d", status)
+	}
+	if status := doRequest(t, relogin, "GET", server.URL+"/auth/me", "", "", nil); status != http.StatusOK {
+		t.Errorf("登录后的会话应有效: %d", status)
+	}
+
+	// API令牌
+	var created struct {
+		Token string      `json:"token"`
+		Info  *user.Token `json:"info"`
+	}
+	if status := doRequest(t, alice, "POST", server.URL+"/auth/tokens", "", `{"name":"script"}`, &created); status != http.StatusCreated || !strings.HasPrefix(created.Token, user.TokenPrefix) {
+		t.Fatalf("创建令牌失败: %d %+v", status, created)
+	}
+	if status := doRequest(t, anonymous, "GET", server.URL+"/auth/me", created.Token, "", &me); status != http.StatusOK || me.User.Username != "alice" {
+		t.Fatalf("令牌应识别为创建者: %d", status)
+	}
+	if status := doRequest(t, alice, "DELETE", server.URL+"/auth/tokens?id="+created.Info.ID, "", "", nil); status != http.StatusNoContent {
+		t.Fatalf("吊销令牌失败: %d", status)
+	}
+	if status := doRequest(t, anonymous, "GET", server.URL+"/auth/me", created.Token, "", nil); status != http.StatusUnauthorized {
+		t.Errorf("吊销后的令牌不应有效: %d", status)
+	}
+
+	// 管理接口
+	if status := doRequest(t, anonymous, "GET", server.URL+"/admin/websocket", "", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("未登录访问管理接口应返回401: %d", status)
+	}
+	if status := doRequest(t, alice, "GET", server.URL+"/admin/websocket", "", "", nil); status != http.StatusForbidden {
+		t.Errorf("普通用户访问管理接口应返回403: %d", status)
+	}
+	jar, _ = cookiejar.New(nil)
+	root := &http.Client{Jar: jar}
+	if status := doRequest(t, root, "POST", server.URL+"/auth/login", "", `{"username":"root","password":"root-password"}`, nil); status != http.StatusOK {
+		t.Fatalf("配置的管理员应能登录: %d", status)
+	}
+	if status := doRequest(t, root, "GET", server.URL+"/admin/websocket", "", "", nil); status != http.StatusOK {
+		t.Errorf("管理员应能访问管理接口: %d", status)
+	}
+}
+
+// TestAnonymousIsNotAdmin 关闭认证时匿名用户不能访问管理接口
+func TestAnonymousIsNotAdmin(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) { cfg.Auth.Enabled = false })
+
+	if status := doRequest(t, http.DefaultClient, "GET", server.URL+"/auth/me", "", "", nil); status != http.StatusOK {
+		t.Errorf("关闭认证时普通接口应可用: %d", status)
+	}
+	if status := doRequest(t, http.DefaultClient, "GET", server.URL+"/admin/websocket", "", "", nil); status != http.StatusForbidden {
+		t.Errorf("关闭认证时管理接口应返回403: %d", status)
+	}
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"reactedge/internal/ai"
+	"reactedge/internal/challenge"
+	"reactedge/internal/fingerprint"
+	"reactedge/internal/history"
+	"reactedge/pkg/prompt"
+)
+
+// handleChallengeStart 为当前用户开始新挑战
+func (s *Server) handleChallengeStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := s.challenges.StartChallenge(currentUser(r).ID)
+	s.writeChallenge(w, r, state)
+}
+
+// handleChallengeState 获取当前用户的挑战状态
+func (s *Server) handleChallengeState(w http.ResponseWriter, r *http.Request) {
+	state := s.challenges.GetChallengeState(targetUserID(r))
+	if state == nil {
+		http.Error(w, tr(r, "error.challenge_not_started"), http.StatusNotFound)
+		return
+	}
+	s.writeChallenge(w, r, state)
+}
+
+// handleChallengeAdvance 推进当前用户的挑战阶段
+func (s *Server) handleChallengeAdvance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := currentUser(r).ID
+	state := s.challenges.AdvancePhase(userID)
+	if state == nil {
+		http.Error(w, tr(r, "error.challenge_not_started"), http.StatusNotFound)
+		return
+	}
+
+	// 进入模板阶段时优先用大模型按话题生成模板，失败时保留本地组装的模板
+	if state.CurrentPhase == challenge.PhasePersonalizedTemplate && s.aiManager != nil {
+		state = s.generateChallengeTemplate(r.Context(), userID, state)
+	}
+
+	// 进入表达DNA分析阶段时保存本次挑战的完整记录，并把DNA报告计入个人表达指纹
+	if state.CurrentPhase == challenge.PhaseDNAAnalysis && state.UserSpeech != "" {
+		s.recordAttempt(&history.Attempt{
+			UserID:     userID,
+			Kind:       history.KindChallenge,
+			Question:   state.CurrentTopic,
+			Persona:    "hanhan",
+			UserAnswer: state.UserSpeech,
+			Speech:     state.SpeechAnalysis,
+			DNA:        state.ExpressionDNA,
+		})
+		s.fingerprints.Observe(userID, fingerprint.Report{DNA: state.ExpressionDNA, Text: state.UserSpeech})
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"state":       state,
+			"content":     s.challenges.GetPhaseContent(state, requestLocale(r)),
+			"fingerprint": s.fingerprints.Fingerprint(userID),
+		})
+		return
+	}
+	s.writeChallenge(w, r, state)
+}
+
+// handleChallengeSpeech 提交当前用户的回答文本
+func (s *Server) handleChallengeSpeech(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Speech   string  `json:"speech"`
+		Duration float64 `json:"duration"` // 回答时长（秒），可选
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Speech == "" {
+		http.Error(w, tr(r, "error.empty_field", "speech"), http.StatusBadRequest)
+		return
+	}
+
+	userID := currentUser(r).ID
+	duration := time.Duration(req.Duration * float64(time.Second))
+	state := s.challenges.SubmitSpeechWithDuration(userID, req.Speech, duration)
+	if state == nil {
+		http.Error(w, tr(r, "error.challenge_not_started"), http.StatusNotFound)
+		return
+	}
+	state = s.refineChallengeProfile(r.Context(), userID, req.Speech, state)
+	s.writeChallenge(w, r, state)
+}
+
+// writeChallenge 输出挑战状态和当前阶段内容
+func (s *Server) writeChallenge(w http.ResponseWriter, r *http.Request, state *challenge.ChallengeState) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"state":   state,
+		"content": s.challenges.GetPhaseContent(state, requestLocale(r)),
+	})
+}
+
+// generateChallengeTemplate 用大模型生成挑战的个性化应答模板
+func (s *Server) generateChallengeTemplate(ctx context.Context, userID string, state *challenge.ChallengeState) *challenge.ChallengeState {
+	ctx, cancel := context.WithTimeout(ctx, s.interactionTimeout())
+	defer cancel()
+
+	profile := state.UserProfile
+	interest := profile.PrimaryInterest
+	if state.Template != nil {
+		interest = state.Template.Interest
+	}
+	generated := s.aiManager.GenerateChallengeTemplate(ctx, prompt.ChallengeTemplateParams{
+		Topic:         state.CurrentTopic,
+		Interest:      interest,
+		ThinkingStyle: profile.ThinkingStyle,
+		Analogies:     s.aiEngine.RelatedAnalogies(interest, state.CurrentTopic),
+	})
+	if generated.Output.IsFallback() {
+		fmt.Printf("⚠️ 大模型生成应答模板失败，使用本地模板: %s\n", generated.Output.Error)
+		return state
+	}
+
+	template := ai.NewPersonalizedTemplate(state.CurrentTopic, interest, profile.ThinkingStyle, ai.TemplateLLM,
+		generated.Hook, generated.Contrast, generated.Essence, generated.Question)
+	if updated := s.challenges.ApplyTemplate(userID, template); updated != nil {
+		return updated
+	}
+	return state
+}
+
+// refineChallengeProfile 用大模型从回答中提取画像，作为额外证据与本地检测结果合并
+func (s *Server) refineChallengeProfile(ctx context.Context, userID, speech string, state *challenge.ChallengeState) *challenge.ChallengeState {
+	if s.aiManager == nil {
+		return state
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.interactionTimeout())
+	defer cancel()
+
+	detector := ai.DefaultProfileDetector()
+	lexicon := detector.Lexicon()
+	extraction := s.aiManager.ExtractUserProfile(ctx, prompt.UserProfileParams{
+		Speech:         speech,
+		Interests:      lexicon.InterestNames(),
+		ThinkingStyles: lexicon.ThinkingStyles(),
+		Strengths:      lexicon.StrengthNames(),
+	})
+	if extraction.Output.IsFallback() {
+		fmt.Printf("⚠️ 大模型提取用户画像失败，仅使用本地检测: %s\n", extraction.Output.Error)
+		return state
+	}
+
+	interests := make(map[string]float64, len(extraction.Interests))
+	for _, signal := range extraction.Interests {
+		interests[signal.Interest] = signal.Confidence
+	}
+	evidence := detector.AIEvidence(interests, extraction.ThinkingStyle, extraction.Strengths)
+	if updated := s.challenges.MergeProfileEvidence(userID, evidence); updated != nil {
+		return updated
+	}
+	return state
+}
@@ -0,0 +1,192 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"reactedge/internal/corpus"
+	"reactedge/pkg/prompt"
+)
+
+// 上传讲稿的大小上限
+const maxCorpusSize = 2 << 20
+
+// newCorpusLibrary 根据配置加载讲稿语料，加载失败时只使用内置风格语料
+func (s *Server) newCorpusLibrary() *corpus.Library {
+	dir := "data/corpus"
+	if s.config != nil {
+		dir = s.config.Corpus.Dir
+	}
+
+	library, err := corpus.NewLibrary(dir)
+	if err != nil {
+		fmt.Printf("⚠️ 讲稿语料加载失败，只使用内置风格语料: %v\n", err)
+		library, _ = corpus.NewLibrary("")
+	}
+
+	if s.config != nil && s.config.Corpus.Embeddings && s.aiManager != nil {
+		if embedder := s.aiManager.Embedder(); embedder != nil {
+			library.SetEmbedder(embedder)
+		} else {
+			fmt.Println("⚠️ 没有支持向量化的AI服务，语料检索只使用BM25")
+		}
+	}
+	return library
+}
+
+// retrievePassages 检索与问题相关的讲话片段，用户选择的经典内容优先
+func (s *Server) retrievePassages(ctx context.Context, persona, question, reference string) []corpus.Passage {
+	limit := 3
+	if s.config != nil {
+		limit = s.config.Corpus.Passages
+	}
+	if s.corpus == nil || limit <= 0 {
+		return nil
+	}
+	return s.corpus.Search(ctx, corpus.Query{
+		Persona:   persona,
+		Text:      question,
+		Reference: reference,
+		Limit:     limit,
+	})
+}
+
+// promptPassages 把检索到的片段转成提示词参数
+func promptPassages(passages []corpus.Passage) []prompt.Passage {
+	result := make([]prompt.Passage, len(passages))
+	for i, passage := range passages {
+		result[i] = prompt.Passage{Index: i + 1, Title: passage.Title, Text: passage.Text}
+	}
+	return result
+}
+
+// citationIDs 返回片段ID，记录在训练历史中
+func citationIDs(passages []corpus.Passage) []string {
+	var ids []string
+	for _, passage := range passages {
+		ids = append(ids, passage.ID)
+	}
+	return ids
+}
+
+// corpusVisible 返回当前用户可以查看的风格，其他用户未共享的自定义风格样本不可见
+func (s *Server) corpusVisible(r *http.Request) func(persona string) bool {
+	u := currentUser(r)
+	return func(persona string) bool { return s.personaAllowed(u, persona) }
+}
+
+// handleCorpus 列出讲稿，可按persona过滤
+func (s *Server) handleCorpus(w http.ResponseWriter, r *http.Request) {
+	visible := s.corpusVisible(r)
+	documents := []corpus.Document{}
+	for _, document := range s.corpus.Documents(r.URL.Query().Get("persona")) {
+		if visible(document.Persona) {
+			documents = append(documents, document)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"documents": documents})
+}
+
+// handleCorpusSearch 按问题检索讲话片段，参数q、persona、reference、limit
+func (s *Server) handleCorpusSearch(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	if values.Get("q") == "" {
+		http.Error(w, tr(r, "error.empty_field", "q"), http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(values.Get("limit"))
+	passages := s.corpus.Search(r.Context(), corpus.Query{
+		Persona:   values.Get("persona"),
+		Text:      values.Get("q"),
+		Reference: values.Get("reference"),
+		Limit:     limit,
+		Visible:   s.corpusVisible(r),
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"passages": passages})
+}
+
+// handleCorpusUpload 上传讲稿：JSON {persona, name, title, text}，或multipart表单的file字段加persona、name、title
+func (s *Server) handleCorpusUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxCorpusSize)
+
+	var req struct {
+		Persona string `json:"persona"`
+		Name    string `json:"name"`
+		Title   string `json:"title"`
+		Text    string `json:"text"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, tr(r, "error.read_upload_failed", "file", err.Error()), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Persona, req.Name, req.Title, req.Text = r.FormValue("persona"), r.FormValue("name"), r.FormValue("title"), string(data)
+		if req.Name == "" {
+			req.Name = strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename))
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document, err := s.corpus.Add(req.Persona, req.Name, req.Title, req.Text)
+	if errors.Is(err, corpus.ErrInvalid) {
+		http.Error(w, trError(r, err), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, document)
+}
+
+// handleCorpusDocument 删除一篇讲稿
+func (s *Server) handleCorpusDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := s.corpus.Remove(r.PathValue("persona") + "/" + r.PathValue("name"))
+	switch {
+	case errors.Is(err, corpus.ErrNotFound):
+		http.Error(w, trError(r, err), http.StatusNotFound)
+	case errors.Is(err, corpus.ErrInvalid):
+		http.Error(w, trError(r, err), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleCorpusReload 重新加载讲稿目录
+func (s *Server) handleCorpusReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.corpus.Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"documents": s.corpus.Documents("")})
+}
@@ -0,0 +1,217 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"reactedge/internal/experiment"
+	"reactedge/internal/history"
+	"reactedge/pkg/prompt"
+)
+
+// newExperimentManager 根据配置创建实验管理器，加载失败时退化为内存存储
+func (s *Server) newExperimentManager() *experiment.Manager {
+	path, eventsPath := "data/experiments.json", "data/experiment_events.jsonl"
+	if s.config != nil {
+		path, eventsPath = s.config.Experiments.DataFile, s.config.Experiments.EventsFile
+	}
+
+	manager, err := experiment.NewManager(path, eventsPath)
+	if err != nil {
+		fmt.Printf("⚠️ 实验数据加载失败，使用内存存储: %v\n", err)
+		manager, _ = experiment.NewManager("", "")
+	}
+	return manager
+}
+
+// assignVariant 为用户分配名人风格回答实验的变体，没有进行中的实验时返回nil
+func (s *Server) assignVariant(userID string) *experiment.Assignment {
+	return s.experiments.Assign(prompt.PersonaAnswer, userID)
+}
+
+// recordGeneration 保存生成记录；属于实验时记录曝光，并按需异步自动评分
+func (s *Server) recordGeneration(attempt *history.Attempt, assignment *experiment.Assignment) string {
+	if assignment != nil {
+		attempt.Experiment = assignment.ExperimentID
+		attempt.Variant = assignment.Variant
+	}
+
+	attemptID := s.recordAttempt(attempt)
+	if assignment == nil || attemptID == "" {
+		return attemptID
+	}
+
+	if err := s.experiments.Record(experiment.Event{
+		ExperimentID: assignment.ExperimentID,
+		Variant:      assignment.Variant,
+		UserID:       attempt.UserID,
+		AttemptID:    attemptID,
+		Kind:         experiment.EventExposure,
+	}); err != nil {
+		log.Printf("记录实验曝光失败: %v", err)
+	}
+
+	if assignment.AutoEvaluate && s.aiManager != nil {
+		go s.scoreGeneration(attempt, assignment)
+	}
+	return attemptID
+}
+
+// scoreGeneration 用EvaluateReaction为实验变体生成的回答评分
+func (s *Server) scoreGeneration(attempt *history.Attempt, assignment *experiment.Assignment) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interactionTimeout())
+	defer cancel()
+
+	styleName := s.persona(attempt.Persona).Name
+	evaluation, err := s.aiManager.EvaluateReaction(ctx, attempt.GeneratedAnswer, attempt.Question, styleName)
+	if err != nil || evaluation.Output.IsFallback() {
+		// 默认评估结果不是真实评分，不计入实验
+		log.Printf("实验回答自动评分失败: %s/%s", assignment.ExperimentID, assignment.Variant)
+		return
+	}
+
+	if err := s.experiments.Record(experiment.Event{
+		ExperimentID: assignment.ExperimentID,
+		Variant:      assignment.Variant,
+		UserID:       attempt.UserID,
+		AttemptID:    attempt.ID,
+		Kind:         experiment.EventScore,
+		Value:        evaluation.OverallScore,
+	}); err != nil {
+		log.Printf("记录实验评分失败: %v", err)
+	}
+}
+
+// handleVote 对实验中生成的回答点赞或点踩
+func (s *Server) handleVote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Vote string `json:"vote"` // up 或 down
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	value := map[string]float64{"up": 1, "down": -1}[req.Vote]
+	if value == 0 {
+		http.Error(w, tr(r, "error.invalid_vote"), http.StatusBadRequest)
+		return
+	}
+
+	attempt, ok := s.ownedAttempt(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if attempt.Experiment == "" {
+		http.Error(w, tr(r, "error.not_in_experiment"), http.StatusBadRequest)
+		return
+	}
+
+	err := s.experiments.Record(experiment.Event{
+		ExperimentID: attempt.Experiment,
+		Variant:      attempt.Variant,
+		UserID:       currentUser(r).ID,
+		AttemptID:    attempt.ID,
+		Kind:         experiment.EventVote,
+		Value:        value,
+	})
+	if err != nil {
+		http.Error(w, tr(r, "error.vote_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+// handleExperiments GET列出实验及结果，POST创建或更新实验
+func (s *Server) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		reports := []*experiment.Report{}
+		for _, item := range s.experiments.List() {
+			if report, err := s.experiments.Report(item.ID); err == nil {
+				reports = append(reports, report)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"experiments": reports})
+
+	case "POST":
+		var req experiment.Experiment
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Target != prompt.PersonaAnswer {
+			http.Error(w, tr(r, "error.experiment_target"), http.StatusBadRequest)
+			return
+		}
+		for _, variant := range req.Variants {
+			if variant.Template == "" {
+				continue
+			}
+			if variant.Template != prompt.PersonaAnswer && !strings.HasPrefix(variant.Template, prompt.PersonaAnswer+".") {
+				http.Error(w, tr(r, "error.variant_template", variant.Template), http.StatusBadRequest)
+				return
+			}
+			if _, ok := prompt.Default().Get(variant.Template); !ok {
+				http.Error(w, tr(r, "error.template_not_found", variant.Template), http.StatusBadRequest)
+				return
+			}
+		}
+
+		saved, err := s.experiments.Save(&req)
+		if errors.Is(err, experiment.ErrConflict) {
+			http.Error(w, trError(r, err), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, saved)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleExperimentReport 查看单个实验的对比结果
+func (s *Server) handleExperimentReport(w http.ResponseWriter, r *http.Request) {
+	report, err := s.experiments.Report(r.PathValue("id"))
+	if errors.Is(err, experiment.ErrNotFound) {
+		http.Error(w, trError(r, err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleExperimentStop 停止实验
+func (s *Server) handleExperimentStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stopped, err := s.experiments.Stop(r.PathValue("id"))
+	if errors.Is(err, experiment.ErrNotFound) {
+		http.Error(w, trError(r, err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, stopped)
+}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reactedge/internal/experiment"
+	"reactedge/internal/feedback"
+	"reactedge/internal/history"
+)
+
+// newFeedbackStore 根据配置创建反馈存储，加载失败时退化为内存存储
+func (s *Server) newFeedbackStore() *feedback.Store {
+	path := "data/feedback.jsonl"
+	if s.config != nil {
+		path = s.config.Feedback.DataFile
+	}
+
+	store, err := feedback.NewStore(path)
+	if err != nil {
+		fmt.Printf("⚠️ 用户反馈加载失败，使用内存存储: %v\n", err)
+		store, _ = feedback.NewStore("")
+	}
+	return store
+}
+
+// handleFeedback POST提交对生成回答的反馈，GET查询自己的反馈
+func (s *Server) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		query := feedback.Query{
+			UserID:    targetUserID(r),
+			AttemptID: r.URL.Query().Get("attempt_id"),
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"feedback": s.feedback.List(query)})
+
+	case "POST":
+		var req struct {
+			AttemptID string         `json:"attempt_id"`
+			Rating    int            `json:"rating"`
+			Comment   string         `json:"comment"`
+			Tags      []feedback.Tag `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.AttemptID == "" {
+			http.Error(w, tr(r, "error.missing_field", "attempt_id"), http.StatusBadRequest)
+			return
+		}
+
+		attempt, ok := s.ownedAttempt(w, r, req.AttemptID)
+		if !ok {
+			return
+		}
+		if attempt.Kind != history.KindGenerate || attempt.GeneratedAnswer == "" {
+			http.Error(w, tr(r, "error.no_feedback_answer"), http.StatusBadRequest)
+			return
+		}
+
+		// 保存回答快照，导出的数据可以直接用于提示词调优和语料整理
+		item := &feedback.Feedback{
+			AttemptID:  attempt.ID,
+			UserID:     currentUser(r).ID,
+			Rating:     req.Rating,
+			Comment:    req.Comment,
+			Tags:       req.Tags,
+			Persona:    attempt.Persona,
+			Question:   attempt.Question,
+			Answer:     attempt.GeneratedAnswer,
+			Prompt:     attempt.Prompt,
+			Experiment: attempt.Experiment,
+			Variant:    attempt.Variant,
+		}
+		err := s.feedback.Record(item)
+		if errors.Is(err, feedback.ErrInvalid) {
+			http.Error(w, trError(r, err), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, tr(r, "error.save_feedback_failed", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		s.recordFeedbackVote(item)
+		writeJSON(w, http.StatusOK, item)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// recordFeedbackVote 实验中的回答评分明确时折算为投票：4分及以上点赞，2分及以下点踩
+func (s *Server) recordFeedbackVote(item *feedback.Feedback) {
+	if item.Experiment == "" || item.Rating == 0 {
+		return
+	}
+
+	var value float64
+	switch {
+	case item.Rating >= 4:
+		value = 1
+	case item.Rating <= 2:
+		value = -1
+	default:
+		return
+	}
+
+	if err := s.experiments.Record(experiment.Event{
+		ExperimentID: item.Experiment,
+		Variant:      item.Variant,
+		UserID:       item.UserID,
+		AttemptID:    item.AttemptID,
+		Kind:         experiment.EventVote,
+		Value:        value,
+	}); err != nil {
+		log.Printf("反馈折算实验投票失败: %v", err)
+	}
+}
+
+// handleFeedbackTags 返回可用的反馈标签
+func (s *Server) handleFeedbackTags(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tags": feedback.Tags()})
+}
+
+// handleFeedbackExport 导出反馈，format=csv(默认)或jsonl，可按风格、标签、评分和时间过滤
+func (s *Server) handleFeedbackExport(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	query := feedback.Query{
+		UserID:  values.Get("user_id"),
+		Persona: values.Get("persona"),
+		Tag:     feedback.Tag(values.Get("tag")),
+	}
+
+	var err error
+	if query.Since, err = parseTimeParam(values.Get("since")); err != nil {
+		http.Error(w, tr(r, "error.invalid_param", "since", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if query.Until, err = parseTimeParam(values.Get("until")); err != nil {
+		http.Error(w, tr(r, "error.invalid_param", "until", err.Error()), http.StatusBadRequest)
+		return
+	}
+	for param, target := range map[string]*int{"min_rating": &query.MinRating, "max_rating": &query.MaxRating} {
+		if value := values.Get(param); value != "" {
+			if *target, err = strconv.Atoi(value); err != nil {
+				http.Error(w, tr(r, "error.integer_param", param), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	items := s.feedback.List(query)
+	filename := "feedback-" + time.Now().Format("20060102")
+
+	switch format := values.Get("format"); format {
+	case "", "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		// 写入BOM，方便用Excel直接打开中文内容
+		w.Write([]byte("\xEF\xBB\xBF"))
+		err = feedback.WriteCSV(w, items)
+	case "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.jsonl"`)
+		err = feedback.WriteJSONL(w, items)
+	default:
+		http.Error(w, tr(r, "error.invalid_export_format"), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("导出反馈失败: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+
+	"reactedge/internal/history"
+	"reactedge/internal/user"
+)
+
+// TestFeedbackOnlyForGeneratedAnswers 测试只能对有回答的生成记录提交反馈
+func TestFeedbackOnlyForGeneratedAnswers(t *testing.T) {
+	app, server := newTestApp(t, nil)
+	alice := newSession(t, server, "alice")
+
+	var me struct {
+		User *user.User `json:"user"`
+	}
+	if status := doRequest(t, alice, "GET", server.URL+"/auth/me", "", "", &me); status != http.StatusOK {
+		t.Fatalf("获取当前用户失败: %d", status)
+	}
+
+	generated := app.recordAttempt(&history.Attempt{UserID: me.User.ID, Kind: history.KindGenerate, Question: "怎么汇报", GeneratedAnswer: "先讲结论"})
+	empty := app.recordAttempt(&history.Attempt{UserID: me.User.ID, Kind: history.KindGenerate, Question: "怎么汇报"})
+	challenge := app.recordAttempt(&history.Attempt{UserID: me.User.ID, Kind: history.KindChallenge, UserAnswer: "我的回答"})
+
+	for id, want := range map[string]int{
+		generated: http.StatusOK,
+		empty:     http.StatusBadRequest,
+		challenge: http.StatusBadRequest,
+	} {
+		if status := doRequest(t, alice, "POST", server.URL+"/feedback", "", `{"attempt_id":"`+id+`","rating":4}`, nil); status != want {
+			t.Errorf("记录%s的反馈应返回%d，实际: %d", id, want, status)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"reactedge/internal/fingerprint"
+	"reactedge/internal/history"
+	"reactedge/internal/persona"
+	"reactedge/pkg/style"
+)
+
+// replayFingerprints 用历史挑战记录中的表达DNA恢复个人表达指纹
+func (s *Server) replayFingerprints() {
+	attempts, err := s.history.List(history.Query{Kind: history.KindChallenge})
+	if err != nil {
+		log.Printf("读取挑战历史失败: %v", err)
+		return
+	}
+
+	replayed := 0
+	for _, attempt := range attempts {
+		if attempt.DNA == nil {
+			continue
+		}
+		s.fingerprints.Observe(attempt.UserID, fingerprint.Report{DNA: attempt.DNA, Text: attempt.UserAnswer, At: attempt.CreatedAt})
+		replayed++
+	}
+	if replayed > 0 {
+		fmt.Printf("✅ 已从挑战历史恢复 %d 份表达DNA报告\n", replayed)
+	}
+}
+
+// handleFingerprint 查看个人表达指纹：稳定特质、标志性模式、偏好的类比领域和风格漂移
+func (s *Server) handleFingerprint(w http.ResponseWriter, r *http.Request) {
+	fp := s.fingerprints.Fingerprint(targetUserID(r))
+	if fp == nil {
+		http.Error(w, tr(r, "error.no_fingerprint"), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, fp)
+}
+
+// handleFingerprintCompare 把个人表达与每种内置名人风格比较
+func (s *Server) handleFingerprintCompare(w http.ResponseWriter, r *http.Request) {
+	userID := targetUserID(r)
+	text := s.fingerprints.Text(userID)
+	if text == "" {
+		http.Error(w, tr(r, "error.no_fingerprint"), http.StatusNotFound)
+		return
+	}
+
+	var keys []string
+	for _, p := range persona.Builtins() {
+		keys = append(keys, p.Key)
+	}
+	comparisons := fingerprint.Compare(style.Default(), text, keys)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":     userID,
+		"comparisons": comparisons,
+	})
+}
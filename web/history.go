@@ -0,0 +1,198 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"reactedge/internal/history"
+	aiPkg "reactedge/pkg/ai"
+)
+
+// newHistoryStore 根据配置创建训练历史存储，加载失败时退化为内存存储
+func (s *Server) newHistoryStore() history.Store {
+	path := "data/history.jsonl"
+	if s.config != nil {
+		path = s.config.History.DataFile
+	}
+
+	store, err := history.NewFileStore(path)
+	if err != nil {
+		fmt.Printf("⚠️ 训练历史加载失败，使用内存存储: %v\n", err)
+		store, _ = history.NewFileStore("")
+	}
+	return store
+}
+
+// replayHistory 用历史评估记录恢复自适应引擎的能力画像
+func (s *Server) replayHistory() {
+	attempts, err := s.history.List(history.Query{Kind: history.KindEvaluation})
+	if err != nil {
+		log.Printf("读取评估历史失败: %v", err)
+		return
+	}
+
+	replayed := 0
+	for _, attempt := range attempts {
+		if attempt.Evaluation == nil || attempt.Evaluation.Output.IsFallback() {
+			continue
+		}
+		scores := aiPkg.EvaluationScores(attempt.Evaluation)
+		if _, err := s.trainer.RecordScores(attempt.UserID, attempt.Scenario, attempt.Difficulty, scores, attempt.Evaluation.OverallScore, attempt.CreatedAt); err == nil {
+			replayed++
+		}
+	}
+	if replayed > 0 {
+		fmt.Printf("✅ 已从训练历史恢复 %d 条评估记录\n", replayed)
+	}
+}
+
+// recordAttempt 保存训练记录，失败时只记录日志，返回记录ID
+func (s *Server) recordAttempt(attempt *history.Attempt) string {
+	if err := s.history.Record(attempt); err != nil {
+		log.Printf("保存训练记录失败: %v", err)
+		return ""
+	}
+	return attempt.ID
+}
+
+// handleHistory 查询当前用户的训练记录
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+
+	attempts, err := s.history.List(query)
+	if err != nil {
+		http.Error(w, tr(r, "error.history_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	// 最新的记录排在前面
+	result := make([]*history.Attempt, 0, len(attempts))
+	for i := len(attempts) - 1; i >= 0; i-- {
+		result = append(result, attempts[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"attempts": result})
+}
+
+// handleHistoryItem 获取单条训练记录
+func (s *Server) handleHistoryItem(w http.ResponseWriter, r *http.Request) {
+	attempt, ok := s.ownedAttempt(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, attempt)
+}
+
+// ownedAttempt 获取属于当前用户的记录，管理员可查看所有记录
+func (s *Server) ownedAttempt(w http.ResponseWriter, r *http.Request, id string) (*history.Attempt, bool) {
+	attempt, err := s.history.Get(id)
+	if errors.Is(err, history.ErrNotFound) {
+		http.Error(w, trError(r, err), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, tr(r, "error.history_failed", err.Error()), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	u := currentUser(r)
+	if attempt.UserID != u.ID && !u.IsAdmin() {
+		http.Error(w, trError(r, history.ErrNotFound), http.StatusNotFound)
+		return nil, false
+	}
+	return attempt, true
+}
+
+// handleProgress 返回各指标按时间聚合的进度
+// metric可用逗号分隔多个指标，默认返回全部；by=persona时按名人风格分别统计
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query.Limit = 0
+
+	metrics := history.Metrics()
+	if value := r.URL.Query().Get("metric"); value != "" {
+		metrics = nil
+		for _, name := range strings.Split(value, ",") {
+			metric := history.Metric(strings.TrimSpace(name))
+			if !history.ValidMetric(metric) {
+				http.Error(w, tr(r, "error.unknown_metric", metric), http.StatusBadRequest)
+				return
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+
+	attempts, err := s.history.List(query)
+	if err != nil {
+		http.Error(w, tr(r, "error.history_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	bucket := history.ParseBucket(r.URL.Query().Get("bucket"))
+	series := []history.Series{}
+	for _, metric := range metrics {
+		if r.URL.Query().Get("by") == "persona" {
+			series = append(series, history.BuildSeriesByPersona(attempts, metric, bucket)...)
+			continue
+		}
+		if item := history.BuildSeries(attempts, metric, bucket); len(item.Points) > 0 {
+			series = append(series, item)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"bucket":   bucket,
+		"attempts": len(attempts),
+		"series":   series,
+	})
+}
+
+// parseHistoryQuery 解析查询参数：kind、persona、since、until（RFC3339或2006-01-02）、limit
+func parseHistoryQuery(r *http.Request) (history.Query, error) {
+	values := r.URL.Query()
+	query := history.Query{
+		UserID:  targetUserID(r),
+		Kind:    history.AttemptKind(values.Get("kind")),
+		Persona: values.Get("persona"),
+	}
+
+	var err error
+	if query.Since, err = parseTimeParam(values.Get("since")); err != nil {
+		return query, errors.New(tr(r, "error.invalid_param", "since", err.Error()))
+	}
+	if query.Until, err = parseTimeParam(values.Get("until")); err != nil {
+		return query, errors.New(tr(r, "error.invalid_param", "until", err.Error()))
+	}
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return query, errors.New(tr(r, "error.non_negative_param", "limit"))
+		}
+	}
+	return query, nil
+}
+
+// parseTimeParam 解析时间参数，空字符串返回零值
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"reactedge/internal/corpus"
+	"reactedge/internal/experiment"
+	"reactedge/internal/feedback"
+	"reactedge/internal/history"
+	"reactedge/internal/i18n"
+	"reactedge/internal/persona"
+	"reactedge/internal/user"
+	aiPkg "reactedge/pkg/ai"
+	"reactedge/pkg/audio"
+	"reactedge/pkg/prompt"
+)
+
+// localeCookie 记住用户通过lang参数切换的语言
+const localeCookie = "reactedge_lang"
+
+// loadCatalog 加载编辑维护的消息目录并设置默认语言，目录不存在时只切换默认语言
+func (s *Server) loadCatalog() error {
+	if s.config == nil || (s.config.I18n.LocalesDir == "" && s.config.I18n.DefaultLocale == "") {
+		return nil
+	}
+	catalog, err := i18n.LoadCatalog(s.config.I18n.LocalesDir, s.config.I18n.DefaultLocale)
+	if errors.Is(err, os.ErrNotExist) {
+		catalog, err = i18n.LoadCatalog("", s.config.I18n.DefaultLocale)
+	}
+	if err != nil {
+		return err
+	}
+	i18n.SetDefault(catalog)
+	return nil
+}
+
+// localeMiddleware 协商请求语言并写入上下文，顺序为lang参数、lang参数记住的Cookie、
+// 用户设置、Accept-Language；需要在用户识别中间件之后执行
+func (s *Server) localeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		catalog := i18n.Default()
+		requested := r.URL.Query().Get("lang")
+		if locale := catalog.Match(requested); locale != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     localeCookie,
+				Value:    locale,
+				Path:     "/",
+				MaxAge:   365 * 24 * 3600,
+				Secure:   s.auth.CookieSecure,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		var remembered, preference string
+		if cookie, err := r.Cookie(localeCookie); err == nil {
+			remembered = cookie.Value
+		}
+		if u := currentUser(r); u != nil {
+			preference = u.Locale
+		}
+
+		locale := catalog.Negotiate(r.Header.Get("Accept-Language"), requested, remembered, preference)
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+
+		// 回答语言同时写入提示词上下文，AI调用据此要求模型使用该语言
+		ctx := prompt.WithLanguage(i18n.WithLocale(r.Context(), locale), locale)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestLocale 当前请求协商出的语言
+func requestLocale(r *http.Request) string {
+	return i18n.FromContext(r.Context())
+}
+
+// tr 按当前请求的语言翻译消息
+func tr(r *http.Request, key string, args ...interface{}) string {
+	return i18n.T(requestLocale(r), key, args...)
+}
+
+// errorKeys 各存储和服务的哨兵错误对应的消息键，按顺序用errors.Is匹配
+var errorKeys = []struct {
+	err  error
+	key  string
+	args []interface{}
+}{
+	{user.ErrInvalidInput, "error.user_invalid_input", nil},
+	{user.ErrUserExists, "error.user_exists", nil},
+	{user.ErrInvalidCredentials, "error.invalid_credentials", nil},
+	{user.ErrNotFound, "error.user_not_found", nil},
+	{history.ErrNotFound, "error.attempt_not_found", nil},
+	{persona.ErrNotFound, "error.persona_not_found", nil},
+	{persona.ErrInvalid, "error.persona_invalid", []interface{}{persona.MinSampleLength, persona.MaxSampleLength}},
+	{persona.ErrConflict, "error.persona_conflict", nil},
+	{corpus.ErrNotFound, "error.corpus_not_found", nil},
+	{corpus.ErrInvalid, "error.corpus_invalid", nil},
+	{experiment.ErrNotFound, "error.experiment_not_found", nil},
+	{experiment.ErrConflict, "error.experiment_conflict", nil},
+	{feedback.ErrInvalid, "error.feedback_invalid", nil},
+	{audio.ErrNoSynthesizer, "error.no_synthesizer", nil},
+	{aiPkg.ErrSpeechNotSupported, "error.speech_not_supported", nil},
+	{audio.ErrNoTranscript, "error.no_transcript", nil},
+	{audio.ErrUnsupportedFormat, "error.unsupported_audio_format", nil},
+}
+
+// errorText 将哨兵错误翻译为指定语言的消息，其他错误原样返回
+func errorText(locale string, err error) string {
+	for _, entry := range errorKeys {
+		if errors.Is(err, entry.err) {
+			return i18n.T(locale, entry.key, entry.args...)
+		}
+	}
+	return err.Error()
+}
+
+// trError 按当前请求的语言翻译哨兵错误
+func trError(r *http.Request, err error) string {
+	return errorText(requestLocale(r), err)
+}
+
+// handleLocale 修改当前用户的语言偏好，locale为空时清除偏好、改回按请求协商
+func (s *Server) handleLocale(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Locale string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	locale := i18n.Default().Match(req.Locale)
+	if req.Locale != "" && locale == "" {
+		http.Error(w, tr(r, "error.unsupported_locale", req.Locale), http.StatusBadRequest)
+		return
+	}
+	u := currentUser(r)
+	if u.ID == user.AnonymousID {
+		http.Error(w, tr(r, "error.anonymous_locale"), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := s.users.SetLocale(u.ID, locale)
+	if err != nil {
+		http.Error(w, tr(r, "error.save_locale_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": updated, "locale": locale})
+}
+
+// localeOption 页面语言切换选项
+type localeOption struct {
+	Code string
+	Name string
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"reactedge/internal/history"
+	"reactedge/internal/persona"
+)
+
+// TestErrorText 测试哨兵错误按语言翻译，包装的错误同样匹配
+func TestErrorText(t *testing.T) {
+	if got := errorText("en-US", fmt.Errorf("%w: 内置风格不能删除", persona.ErrInvalid)); !strings.HasPrefix(got, "Invalid style") {
+		t.Errorf("包装的风格错误应翻译为英文: %s", got)
+	}
+	if got := errorText("zh-CN", history.ErrNotFound); got != "训练记录不存在" {
+		t.Errorf("记录不存在的中文消息不正确: %s", got)
+	}
+	if got := errorText("en-US", errors.New("boom")); got != "boom" {
+		t.Errorf("未知错误应原样返回: %s", got)
+	}
+}
+
+// TestHandlerErrorsLocalized 测试登录失败和参数错误按请求语言返回
+func TestHandlerErrorsLocalized(t *testing.T) {
+	server := newTestServer(t, nil)
+	alice := newSession(t, server, "alice")
+
+	cases := []struct {
+		client       *http.Client
+		method, path string
+		body, want   string
+	}{
+		{http.DefaultClient, "POST", "/auth/login", `{"username":"nobody","password":"wrong-password"}`, "Incorrect username or password"},
+		{alice, "GET", "/history?limit=-1", "", "The limit parameter must be a non-negative integer"},
+		{alice, "GET", "/history/missing", "", "Training record not found"},
+	}
+	for _, c := range cases {
+		request, _ := http.NewRequest(c.method, server.URL+c.path, strings.NewReader(c.body))
+		request.Header.Set("Accept-Language", "en-US")
+		response, err := c.client.Do(request)
+		if err != nil {
+			t.Fatalf("请求%s失败: %v", c.path, err)
+		}
+		text, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if !strings.Contains(string(text), c.want) {
+			t.Errorf("%s应返回英文错误信息: %d %s", c.path, response.StatusCode, text)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"reactedge/internal/corpus"
+	"reactedge/internal/persona"
+	"reactedge/internal/user"
+	aiPkg "reactedge/pkg/ai"
+	"reactedge/pkg/style"
+)
+
+// 上传样本的大小上限
+const maxPersonaUploadSize = 1 << 20
+
+// personaSamplesDoc 自定义风格样本在语料库中的文档名
+const personaSamplesDoc = "samples"
+
+// newPersonaRegistry 根据配置加载自定义风格，加载失败时只使用内置风格
+func (s *Server) newPersonaRegistry() *persona.Registry {
+	path := "data/personas.json"
+	if s.config != nil {
+		path = s.config.Personas.DataFile
+	}
+
+	registry, err := persona.NewRegistry(path)
+	if err != nil {
+		fmt.Printf("⚠️ 自定义风格加载失败，只使用内置风格: %v\n", err)
+		registry, _ = persona.NewRegistry("")
+	}
+	return registry
+}
+
+// retrainStyleClassifier 用内置语料和自定义风格样本重新训练本地风格分类器
+func (s *Server) retrainStyleClassifier() {
+	classifier, err := s.personas.Classifier()
+	if err != nil {
+		fmt.Printf("⚠️ 风格分类器训练失败，继续使用内置分类器: %v\n", err)
+		style.SetDefault(nil)
+		return
+	}
+	style.SetDefault(classifier)
+}
+
+// persona 按标识查找风格，找不到时使用默认风格
+func (s *Server) persona(key string) *persona.Persona {
+	return s.personas.Resolve(key)
+}
+
+// personaAllowed 判断用户能否使用指定风格，不认识的标识按默认风格处理
+func (s *Server) personaAllowed(u *user.User, key string) bool {
+	p, ok := s.personas.Get(key)
+	return !ok || p.VisibleTo(u.ID, u.IsAdmin())
+}
+
+// localResponse 本地模拟回答，自定义风格使用最接近的内置风格
+func (s *Server) localResponse(key, question, content string) string {
+	if p, ok := s.personas.Get(key); ok && !p.Builtin {
+		key = p.Base
+	}
+	return s.aiEngine.GenerateStyleResponse(key, question, content)
+}
+
+// handlePersonas GET列出可用的风格，POST上传样本创建自定义风格
+func (s *Server) handlePersonas(w http.ResponseWriter, r *http.Request) {
+	u := currentUser(r)
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"personas": s.personas.List(u.ID, u.IsAdmin())})
+	case "POST":
+		s.createPersona(w, r, u)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createPersona 分析上传的样本并保存为自定义风格
+// JSON {name, key, description, samples, text, voice, shared}，或multipart表单的file字段（可多个）加同名表单字段
+func (s *Server) createPersona(w http.ResponseWriter, r *http.Request, u *user.User) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPersonaUploadSize)
+
+	var req struct {
+		Name        string   `json:"name"`
+		Key         string   `json:"key"`
+		Description string   `json:"description"`
+		Samples     []string `json:"samples"`
+		Text        string   `json:"text"`
+		Voice       string   `json:"voice"`
+		Shared      bool     `json:"shared"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxPersonaUploadSize); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, header := range r.MultipartForm.File["file"] {
+			file, err := header.Open()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.Samples = append(req.Samples, string(data))
+		}
+		req.Name, req.Key, req.Description = r.FormValue("name"), r.FormValue("key"), r.FormValue("description")
+		req.Text, req.Voice, req.Shared = r.FormValue("text"), r.FormValue("voice"), r.FormValue("shared") == "true"
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var samples []string
+	for _, sample := range append(req.Samples, req.Text) {
+		if sample = strings.TrimSpace(sample); sample != "" {
+			samples = append(samples, sample)
+		}
+	}
+	if strings.TrimSpace(req.Name) == "" || len(samples) == 0 {
+		http.Error(w, tr(r, "error.persona_samples_required"), http.StatusBadRequest)
+		return
+	}
+
+	// AI分析失败或不可用时只用本地特征
+	var analysis *aiPkg.StyleAnalysis
+	if s.aiManager != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), s.interactionTimeout())
+		result, err := s.aiManager.AnalyzeExpressionStyle(ctx, req.Name, strings.Join(samples, "\n\n"))
+		cancel()
+		if err != nil {
+			fmt.Printf("⚠️ 风格分析失败，只使用本地特征: %v\n", err)
+		} else {
+			analysis = result
+		}
+	}
+
+	derived := persona.Derive(req.Name, samples, analysis)
+	derived.Key, derived.Voice, derived.Shared, derived.OwnerID = req.Key, req.Voice, req.Shared, u.ID
+	if req.Description != "" {
+		derived.Description = req.Description
+	}
+	saved, err := s.personas.Add(derived)
+	switch {
+	case errors.Is(err, persona.ErrInvalid):
+		http.Error(w, trError(r, err), http.StatusBadRequest)
+		return
+	case errors.Is(err, persona.ErrConflict):
+		http.Error(w, trError(r, err), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 样本同时作为语料，生成回答时可以检索
+	if _, err := s.corpus.Add(saved.Key, personaSamplesDoc, saved.Name+"讲话样本", strings.Join(samples, "\n\n")); err != nil {
+		fmt.Printf("⚠️ 自定义风格样本写入语料库失败: %v\n", err)
+	}
+	s.retrainStyleClassifier()
+	writeJSON(w, http.StatusCreated, saved)
+}
+
+// handlePersonaItem GET查看风格详情，DELETE删除自定义风格（创建者或管理员）
+func (s *Server) handlePersonaItem(w http.ResponseWriter, r *http.Request) {
+	u := currentUser(r)
+	p, ok := s.personas.Get(r.PathValue("key"))
+	if !ok || !p.VisibleTo(u.ID, u.IsAdmin()) {
+		http.Error(w, trError(r, persona.ErrNotFound), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, p)
+	case "DELETE":
+		if p.OwnerID != u.ID && !u.IsAdmin() {
+			http.Error(w, tr(r, "error.persona_owner_only"), http.StatusForbidden)
+			return
+		}
+		err := s.personas.Remove(p.Key)
+		if errors.Is(err, persona.ErrInvalid) {
+			http.Error(w, trError(r, err), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.corpus.Remove(p.Key + "/" + personaSamplesDoc); err != nil && !errors.Is(err, corpus.ErrNotFound) {
+			fmt.Printf("⚠️ 删除自定义风格样本失败: %v\n", err)
+		}
+		s.retrainStyleClassifier()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
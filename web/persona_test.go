@@ -0,0 +1,53 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"reactedge/config"
+)
+
+// TestPrivatePersonaSamplesHidden 未共享的自定义风格样本不会出现在其他用户的语料列表和检索结果中
+func TestPrivatePersonaSamplesHidden(t *testing.T) {
+	server := newTestServer(t, func(cfg *config.Config) { cfg.Corpus.Dir = t.TempDir() })
+	owner := newSession(t, server, "owner")
+	viewer := newSession(t, server, "viewer")
+
+	phrase := "紫藤花架下的季度复盘"
+	body := `{"name":"王总","text":"` + strings.Repeat(phrase+"，我们把每一个数字都摊开来讲清楚。", 4) + `"}`
+	response, err := owner.Post(server.URL+"/personas", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("创建风格失败: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("创建风格返回%d", response.StatusCode)
+	}
+
+	get := func(client *http.Client, path string) string {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("请求%s失败: %v", path, err)
+		}
+		defer response.Body.Close()
+		data, _ := io.ReadAll(response.Body)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("请求%s返回%d: %s", path, response.StatusCode, data)
+		}
+		return string(data)
+	}
+
+	search := "/corpus/search?q=" + url.QueryEscape(phrase)
+	if !strings.Contains(get(owner, "/corpus"), "王总") || !strings.Contains(get(owner, search), phrase) {
+		t.Fatal("创建者应能看到自己的样本")
+	}
+	if strings.Contains(get(viewer, "/corpus"), "王总") {
+		t.Fatal("其他用户的语料列表不应包含私有风格样本")
+	}
+	if strings.Contains(get(viewer, search), phrase) {
+		t.Fatal("其他用户不应检索到私有风格样本")
+	}
+}
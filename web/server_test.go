@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"reactedge/config"
+	"reactedge/internal/ai"
+)
+
+// newTestServer 启动开启登录和注册、使用内存存储、没有AI服务的真实路由，configure可以调整配置
+func newTestServer(t *testing.T, configure func(cfg *config.Config)) *httptest.Server {
+	t.Helper()
+
+	_, server := newTestApp(t, configure)
+	return server
+}
+
+// newTestApp 同newTestServer，同时返回服务本身，便于直接准备存储中的数据
+func newTestApp(t *testing.T, configure func(cfg *config.Config)) (*Server, *httptest.Server) {
+	t.Helper()
+
+	cfg := config.GetDefaultConfig()
+	cfg.Auth.Enabled = true
+	cfg.Auth.DataFile = ""
+	cfg.Auth.AllowRegistration = true
+	cfg.History.DataFile = ""
+	cfg.Experiments.DataFile, cfg.Experiments.EventsFile = "", ""
+	cfg.Feedback.DataFile = ""
+	cfg.Personas.DataFile = ""
+	cfg.Corpus.Dir = ""
+	cfg.Analogies.Dir = ""
+	cfg.I18n.LocalesDir = ""
+	if configure != nil {
+		configure(cfg)
+	}
+
+	app := NewServer(ai.NewHanStyleAI(), nil, cfg)
+	server := httptest.NewServer(app.Router())
+	t.Cleanup(server.Close)
+	return app, server
+}
+
+// newSession 注册用户，返回带会话cookie的客户端
+func newSession(t *testing.T, server *httptest.Server, username string) *http.Client {
+	t.Helper()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	response, err := client.Post(server.URL+"/auth/register", "application/json", strings.NewReader(`{"username":"`+username+`","password":"`+username+`-password"}`))
+	if err != nil {
+		t.Fatalf("注册%s失败: %v", username, err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+		t.Fatalf("注册%s返回%d", username, response.StatusCode)
+	}
+	return client
+}
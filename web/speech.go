@@ -0,0 +1,99 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"reactedge/config"
+	"reactedge/internal/history"
+	"reactedge/pkg/audio"
+)
+
+// personaVoice 名人风格的默认音色，未配置时使用通用音色
+// 自定义风格优先用创建时指定的音色，其次用最接近的内置风格的音色
+func (s *Server) personaVoice(persona string) config.VoiceConfig {
+	if p, ok := s.personas.Get(persona); ok && !p.Builtin {
+		if p.Voice != "" {
+			return config.VoiceConfig{Voice: p.Voice}
+		}
+		persona = p.Base
+	}
+	if s.config != nil {
+		if voice, ok := s.config.AI.Voices[persona]; ok {
+			return voice
+		}
+	}
+	return config.VoiceConfig{}
+}
+
+// answerAudioURL 生成回答的朗读地址
+func answerAudioURL(attemptID string) string {
+	if attemptID == "" {
+		return ""
+	}
+	return "/answers/" + attemptID + "/audio"
+}
+
+// handleAnswerAudio 朗读一条生成的回答，默认使用该名人风格的音色
+// 可选参数：voice音色、speed语速(0.25-4)、format格式(mp3/opus/aac/flac/wav/pcm)
+func (s *Server) handleAnswerAudio(w http.ResponseWriter, r *http.Request) {
+	attempt, ok := s.ownedAttempt(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if attempt.Kind != history.KindGenerate || attempt.GeneratedAnswer == "" {
+		http.Error(w, tr(r, "error.no_speakable_answer"), http.StatusBadRequest)
+		return
+	}
+	if s.aiManager == nil {
+		http.Error(w, trError(r, audio.ErrNoSynthesizer), http.StatusServiceUnavailable)
+		return
+	}
+
+	voice := s.personaVoice(attempt.Persona)
+	values := r.URL.Query()
+	req := audio.SpeechRequest{
+		Text:         attempt.GeneratedAnswer,
+		Voice:        voice.Voice,
+		Speed:        voice.Speed,
+		Format:       audio.Format(values.Get("format")),
+		Instructions: voice.Instructions,
+	}
+	if value := values.Get("voice"); value != "" {
+		req.Voice, req.Instructions = value, ""
+	}
+	if value := values.Get("speed"); value != "" {
+		speed, err := strconv.ParseFloat(value, 64)
+		if err != nil || speed < audio.MinSpeed || speed > audio.MaxSpeed {
+			http.Error(w, tr(r, "error.invalid_speed"), http.StatusBadRequest)
+			return
+		}
+		req.Speed = speed
+	}
+	if req.Format != "" && !audio.ValidSpeechFormat(req.Format) {
+		http.Error(w, tr(r, "error.invalid_speech_format"), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.interactionTimeout())
+	defer cancel()
+
+	speech, err := s.aiManager.Synthesize(ctx, req)
+	if errors.Is(err, audio.ErrNoSynthesizer) {
+		http.Error(w, trError(r, err), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, trError(r, err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", speech.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(speech.Data)))
+	w.Header().Set("Content-Disposition", `inline; filename="answer-`+attempt.ID+`.`+string(speech.Format)+`"`)
+	// 回答内容不会变化，允许浏览器缓存，避免重复合成
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(speech.Data)
+}
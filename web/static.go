@@ -0,0 +1,212 @@
+package web
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"reactedge/internal/i18n"
+)
+
+// 页面模板和静态资源随程序一起编译，修改前端文件后重新编译即可生效
+var (
+	//go:embed templates/*.html
+	templateFiles embed.FS
+
+	//go:embed static
+	staticFiles embed.FS
+)
+
+// pageTemplates 解析后的页面模板，{{t}}在渲染时按请求语言替换
+var pageTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"t":     func(key string) string { return key },
+	"asset": assetURL,
+}).ParseFS(templateFiles, "templates/*.html"))
+
+// assetVersion 静态资源内容的摘要，加在资源地址后面，资源变化后浏览器会重新下载
+var assetVersion = func() string {
+	hash := sha256.New()
+	fs.WalkDir(staticFiles, "static", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := staticFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		io.WriteString(hash, name)
+		hash.Write(data)
+		return nil
+	})
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}()
+
+// assetURL 静态资源的地址，带上版本参数以便长期缓存
+func assetURL(name string) string {
+	return "/static/" + name + "?v=" + assetVersion
+}
+
+// pageData 页面模板数据
+type pageData struct {
+	Locale   string
+	Locales  []localeOption
+	Messages map[string]string // 页面脚本使用的文本
+}
+
+// renderPage 按请求语言渲染页面模板templates/<name>.html，页面脚本使用page.<name>.*的文本
+func renderPage(w http.ResponseWriter, r *http.Request, name string) {
+	locale := requestLocale(r)
+	catalog := i18n.Default()
+	tmpl, err := pageTemplates.Clone()
+	if err != nil {
+		http.Error(w, i18n.T(locale, "error.page_template", name, err.Error()), http.StatusInternalServerError)
+		return
+	}
+	tmpl.Funcs(template.FuncMap{
+		"t": func(key string) string { return catalog.T(locale, key) },
+	})
+
+	data := pageData{Locale: locale, Messages: catalog.Messages(locale, "page."+name+".")}
+	for _, code := range catalog.Locales() {
+		data.Locales = append(data.Locales, localeOption{Code: code, Name: catalog.T(code, "locale.name")})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// 页面文本随语言和登录状态变化，不缓存；静态资源另外缓存
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := tmpl.ExecuteTemplate(w, name+".html", data); err != nil {
+		fmt.Printf("⚠️ 渲染页面%s失败: %v\n", name, err)
+	}
+}
+
+// staticHandler 提供/static/下的前端资源：带版本参数的请求按static_cache_ttl缓存，
+// 没有版本参数时每次用ETag验证；不列出目录
+func (s *Server) staticHandler() http.Handler {
+	root, _ := fs.Sub(staticFiles, "static")
+	files := http.FileServer(http.FS(root))
+
+	etags := make(map[string]string)
+	fs.WalkDir(root, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(root, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		etags[name] = `"` + hex.EncodeToString(sum[:8]) + `"`
+		return nil
+	})
+
+	ttl := 0
+	if s.config != nil {
+		ttl = s.config.Production.StaticCacheTTL
+	}
+
+	return http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag, ok := etags[strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		if ttl > 0 && r.URL.Query().Get("v") != "" {
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", ttl))
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// gzipMiddleware 压缩文本类响应（页面、脚本、样式、JSON），WebSocket和Range请求不压缩
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") ||
+			r.Header.Get("Upgrade") != "" || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+		w.Header().Add("Vary", "Accept-Encoding")
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// gzipResponseWriter 在写入响应头时按Content-Type决定是否压缩
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer      *gzip.Writer
+	wroteHeader bool
+}
+
+// WriteHeader 对可压缩的内容启用gzip
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	header := w.Header()
+	if status != http.StatusNotModified && status != http.StatusNoContent &&
+		header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		w.writer = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write 写入响应体，需要压缩时经过gzip
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer != nil {
+		return w.writer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Close 结束gzip数据流
+func (w *gzipResponseWriter) Close() error {
+	if w.writer == nil {
+		return nil
+	}
+	return w.writer.Close()
+}
+
+// Unwrap 供http.ResponseController访问底层连接
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible 判断内容类型是否值得压缩，音频、图片等已压缩的格式跳过
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/javascript",
+		mediaType == "application/x-ndjson", mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
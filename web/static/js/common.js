@@ -0,0 +1,57 @@
+// 页面共用的翻译和JSON接口封装
+// 页面模板把当前语言的页面文本写入window.MESSAGES，脚本中用t('键')取用
+
+function t(key) {
+    const messages = window.MESSAGES || {};
+    return messages[key] || key;
+}
+
+// api 封装版本化JSON接口（/api/v1），出错时抛出带服务端错误消息的Error
+const api = {
+    base: '/api/v1',
+
+    async request(method, path, body) {
+        const options = { method: method, headers: { 'Accept': 'application/json' } };
+        if (body !== undefined) {
+            options.headers['Content-Type'] = 'application/json';
+            options.body = JSON.stringify(body);
+        }
+
+        const resp = await fetch(this.base + path, options);
+        const data = await resp.json().catch(function() { return {}; });
+        if (!resp.ok) {
+            const error = new Error(data.error || resp.statusText);
+            error.status = resp.status;
+            throw error;
+        }
+        return data;
+    },
+
+    styles() {
+        return this.request('GET', '/styles');
+    },
+
+    generate(style, content, question) {
+        return this.request('POST', '/generate', { style: style, content: content, question: question });
+    },
+
+    startChallenge() {
+        return this.request('POST', '/challenges');
+    },
+
+    challenge() {
+        return this.request('GET', '/challenges/current');
+    },
+
+    advanceChallenge() {
+        return this.request('POST', '/challenges/current/advance');
+    },
+
+    submitSpeech(speech, duration) {
+        return this.request('POST', '/challenges/current/speech', { speech: speech, duration: duration });
+    },
+
+    analyzeSpeech(text, duration) {
+        return this.request('POST', '/analysis/speech', { text: text, duration: duration });
+    }
+};
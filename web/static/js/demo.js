@@ -0,0 +1,325 @@
+// 演示页面：风格和经典内容列表来自 /api/v1/styles，回答通过WebSocket生成，
+// WebSocket不可用时改用 /api/v1/generate
+
+let websocket = null;
+let isConnected = false;
+let currentRequestId = null; // 跟踪当前请求
+let reconnectAttempts = 0;
+const maxReconnectAttempts = 5;
+
+function connectWebSocket() {
+    if (websocket && websocket.readyState === WebSocket.OPEN) {
+        return; // 已经连接
+    }
+
+    if (reconnectAttempts >= maxReconnectAttempts) {
+        updateConnectionStatus('disconnected', '🔴', t('page.demo.connect_failed'));
+        console.log('达到最大重连次数，停止重连');
+        return;
+    }
+
+    updateConnectionStatus('connecting', '🟡', t('page.demo.connecting'));
+
+    const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const wsUrl = protocol + '//' + window.location.host + '/ws?lang=' + encodeURIComponent(document.documentElement.lang);
+
+    try {
+        websocket = new WebSocket(wsUrl);
+        reconnectAttempts++;
+
+        websocket.onopen = function(event) {
+            isConnected = true;
+            reconnectAttempts = 0; // 重置重连计数
+            updateConnectionStatus('connected', '🟢', t('page.demo.connected'));
+            console.log('WebSocket连接已建立');
+        };
+
+        websocket.onmessage = function(event) {
+            try {
+                const message = JSON.parse(event.data);
+                handleWebSocketMessage(message);
+            } catch (e) {
+                console.error('解析WebSocket消息失败:', e);
+            }
+        };
+
+        websocket.onclose = function(event) {
+            isConnected = false;
+            const wasClean = event.wasClean;
+            console.log('WebSocket连接已关闭, clean:', wasClean, 'code:', event.code);
+
+            // 如果有正在进行的请求，标记为失败
+            if (currentRequestId) {
+                setStatus(t('page.demo.request_lost'), 'error');
+                finishRequest();
+            }
+
+            updateConnectionStatus('disconnected', '🔴', t('page.demo.disconnected'));
+
+            // 自动重连
+            if (!wasClean && reconnectAttempts < maxReconnectAttempts) {
+                setTimeout(connectWebSocket, 3000);
+            }
+        };
+
+        websocket.onerror = function(error) {
+            isConnected = false;
+            console.error('WebSocket错误:', error);
+            updateConnectionStatus('disconnected', '🔴', t('page.demo.connect_error'));
+        };
+
+    } catch (e) {
+        console.error('创建WebSocket连接失败:', e);
+        updateConnectionStatus('disconnected', '🔴', t('page.demo.connect_failed'));
+
+        if (reconnectAttempts < maxReconnectAttempts) {
+            setTimeout(connectWebSocket, 5000);
+        }
+    }
+}
+
+function updateConnectionStatus(statusClass, indicator, text) {
+    document.getElementById('connectionStatus').className = 'connection-status ' + statusClass;
+    document.getElementById('statusIndicator').textContent = indicator;
+    document.getElementById('statusText').textContent = text;
+}
+
+// setStatus 更新结果区域下方的状态文字，className为loading/success/error/cancelled
+function setStatus(text, className) {
+    const statusDiv = document.getElementById('status');
+    statusDiv.textContent = text;
+    statusDiv.className = className;
+}
+
+// showError 在结果区域显示错误提示
+function showError(detail) {
+    const box = document.createElement('div');
+    box.className = 'error-box';
+    const title = document.createElement('strong');
+    title.textContent = t('page.demo.sorry');
+    const small = document.createElement('small');
+    small.textContent = detail;
+    box.append(title, document.createElement('br'), small);
+
+    const responseDiv = document.getElementById('response');
+    responseDiv.replaceChildren(box);
+    document.getElementById('result').style.display = 'block';
+}
+
+// showResult 显示生成的回答，WebSocket和JSON接口的结果格式相同
+function showResult(result) {
+    const resultDiv = document.getElementById('result');
+    document.getElementById('response').replaceChildren(...formatResponse(result.response));
+    resultDiv.style.display = 'block';
+
+    const length = result.length || Array.from(result.response).length;
+    setStatus(t('page.demo.done').replace('{n}', length), 'success');
+    finishRequest();
+
+    // 滚动到结果区域
+    resultDiv.scrollIntoView({ behavior: 'smooth', block: 'start' });
+}
+
+// startRequest 进入生成中状态
+function startRequest() {
+    currentRequestId = Date.now().toString();
+
+    const button = document.getElementById('generateBtn');
+    const cancelBtn = document.getElementById('cancelBtn');
+    button.textContent = t('page.demo.generating');
+    button.disabled = true;
+    cancelBtn.style.display = 'inline-block';
+    cancelBtn.disabled = false;
+    return currentRequestId;
+}
+
+// finishRequest 清理请求状态并恢复按钮
+function finishRequest() {
+    currentRequestId = null;
+
+    const button = document.getElementById('generateBtn');
+    const cancelBtn = document.getElementById('cancelBtn');
+    button.textContent = t('page.demo.generate');
+    button.disabled = false;
+    cancelBtn.style.display = 'none';
+    cancelBtn.disabled = true;
+}
+
+// handleWebSocketMessage 处理服务端消息，只处理当前请求的消息，取消后到达的消息被忽略
+function handleWebSocketMessage(message) {
+    if (!message.id || message.id !== currentRequestId) {
+        if (message.type === 'notice') {
+            alert(message.payload.message);
+        } else if (message.type === 'error' && !message.id) {
+            console.error('WebSocket错误:', message.payload.message);
+        }
+        return;
+    }
+
+    const data = message.payload;
+    switch (message.type) {
+        case 'status':
+            setStatus(data.message || t('page.demo.processing'), 'loading');
+
+            if (data.stage === 'started') {
+                document.getElementById('result').style.display = 'block';
+                document.getElementById('response').textContent = t('page.demo.thinking');
+            }
+            break;
+
+        case 'result':
+            showResult(data);
+            break;
+
+        case 'cancelled':
+            setStatus(t('page.demo.cancelled'), 'cancelled');
+            finishRequest();
+            break;
+
+        case 'error':
+            setStatus(t('page.demo.failed') + data.message, 'error');
+            showError(data.message);
+            finishRequest();
+            break;
+    }
+}
+
+function cancelRequest() {
+    if (currentRequestId) {
+        // 通知服务端停止生成，本地立即结束请求，之后到达的消息会被忽略
+        if (websocket && websocket.readyState === WebSocket.OPEN) {
+            websocket.send(JSON.stringify({
+                version: 1,
+                id: 'cancel-' + currentRequestId,
+                action: 'cancel',
+                payload: { id: currentRequestId }
+            }));
+        }
+        setStatus(t('page.demo.cancelled'), 'cancelled');
+        finishRequest();
+        console.log('用户取消了当前请求');
+    } else if (websocket && websocket.readyState === WebSocket.OPEN) {
+        // 如果没有正在进行的请求，关闭连接
+        websocket.close();
+        updateConnectionStatus('disconnected', '🔴', t('page.demo.disconnected'));
+    }
+}
+
+async function generateResponse() {
+    const style = document.getElementById('style').value;
+    const content = document.getElementById('content').value;
+    const question = document.getElementById('question').value;
+
+    if (!question.trim()) {
+        alert(t('page.demo.empty_question'));
+        return;
+    }
+
+    // 如果有正在进行的请求，提示用户等待
+    if (currentRequestId) {
+        alert(t('page.demo.busy'));
+        return;
+    }
+
+    const requestId = startRequest();
+
+    // WebSocket不可用时通过JSON接口生成，同时尝试重新连接
+    if (!isConnected || !websocket || websocket.readyState !== WebSocket.OPEN) {
+        connectWebSocket();
+        setStatus(t('page.demo.processing'), 'loading');
+        document.getElementById('result').style.display = 'block';
+        document.getElementById('response').textContent = t('page.demo.thinking');
+        try {
+            const result = await api.generate(style, content, question);
+            if (currentRequestId === requestId) {
+                showResult(result);
+            }
+        } catch (error) {
+            if (currentRequestId === requestId) {
+                setStatus(t('page.demo.failed') + error.message, 'error');
+                showError(error.message);
+                finishRequest();
+            }
+        }
+        return;
+    }
+
+    try {
+        const requestData = {
+            version: 1,
+            id: requestId,
+            action: 'generate',
+            payload: { style: style, content: content, question: question }
+        };
+        websocket.send(JSON.stringify(requestData));
+        console.log('发送WebSocket请求:', requestData);
+    } catch (error) {
+        console.error('WebSocket请求失败:', error);
+        setStatus(t('page.demo.send_failed') + error.message, 'error');
+        showError(t('page.demo.send_failed_hint'));
+        finishRequest();
+    }
+}
+
+// formatResponse 按行拆成段落，空行保留为换行
+function formatResponse(text) {
+    return text.split('\n').map(function(line) {
+        if (!line.trim()) {
+            return document.createElement('br');
+        }
+        const p = document.createElement('p');
+        p.textContent = line.trim();
+        return p;
+    });
+}
+
+// addOption 向下拉列表追加选项
+function addOption(select, value, label) {
+    const option = document.createElement('option');
+    option.value = value;
+    option.textContent = label;
+    select.appendChild(option);
+}
+
+// loadStyles 从JSON接口加载可用的风格（内置风格和自定义风格）和经典内容
+async function loadStyles() {
+    try {
+        const data = await api.styles();
+        const styleSelect = document.getElementById('style');
+        styleSelect.replaceChildren();
+        (data.styles || []).forEach(function(style) {
+            addOption(styleSelect, style.key, style.label);
+        });
+
+        const contentSelect = document.getElementById('content');
+        contentSelect.replaceChildren();
+        (data.contents || []).forEach(function(content) {
+            addOption(contentSelect, content.key, content.label);
+        });
+    } catch (err) {
+        console.log('加载风格列表失败:', err);
+    }
+}
+
+// 支持回车键快速提交
+document.getElementById('question').addEventListener('keypress', function(e) {
+    if (e.key === 'Enter' && !e.shiftKey) {
+        e.preventDefault();
+        generateResponse();
+    }
+});
+document.getElementById('generateBtn').addEventListener('click', generateResponse);
+document.getElementById('cancelBtn').addEventListener('click', cancelRequest);
+
+// 页面加载时初始化WebSocket连接和风格列表
+document.addEventListener('DOMContentLoaded', function() {
+    connectWebSocket();
+    loadStyles();
+});
+
+// 页面卸载时关闭WebSocket连接
+window.addEventListener('beforeunload', function() {
+    if (websocket && websocket.readyState === WebSocket.OPEN) {
+        websocket.close();
+    }
+});
@@ -0,0 +1,25 @@
+// 登录页：登录或注册成功后跳转到next参数指定的站内地址，默认进入演示页面
+
+async function submitAuth(url) {
+    const resp = await fetch(url, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+            username: document.getElementById('username').value,
+            password: document.getElementById('password').value
+        })
+    });
+    if (!resp.ok) {
+        document.getElementById('error').textContent = await resp.text();
+        return;
+    }
+    const next = new URLSearchParams(location.search).get('next');
+    location.href = next && next.startsWith('/') && !next.startsWith('//') ? next : '/demo';
+}
+
+document.getElementById('loginBtn').addEventListener('click', function() {
+    submitAuth('/auth/login');
+});
+document.getElementById('registerBtn').addEventListener('click', function() {
+    submitAuth('/auth/register');
+});
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reactedge/config"
+	"reactedge/internal/history"
+	aiPkg "reactedge/pkg/ai"
+	"reactedge/pkg/prompt"
+)
+
+// handleTrainingEvaluate 评估一次训练回答并更新能力画像
+func (s *Server) handleTrainingEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Response   string `json:"response"`
+		Scenario   string `json:"scenario"`
+		Style      string `json:"style"`
+		Difficulty int    `json:"difficulty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Response == "" {
+		http.Error(w, tr(r, "error.empty_field", "response"), http.StatusBadRequest)
+		return
+	}
+
+	if s.aiManager == nil {
+		http.Error(w, tr(r, "error.ai_unavailable"), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.interactionTimeout())
+	defer cancel()
+
+	userID := currentUser(r).ID
+	evaluation, profile, err := s.aiManager.EvaluateUserReaction(ctx, userID, req.Response, req.Scenario, s.persona(req.Style).Name, req.Difficulty)
+	if err != nil {
+		http.Error(w, tr(r, "error.evaluate_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	attemptID := s.recordAttempt(&history.Attempt{
+		UserID:     userID,
+		Kind:       history.KindEvaluation,
+		Question:   req.Scenario,
+		Persona:    req.Style,
+		Scenario:   req.Scenario,
+		Difficulty: req.Difficulty,
+		UserAnswer: req.Response,
+		Evaluation: evaluation,
+		Prompt:     evaluationPrompt(evaluation),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"attempt_id": attemptID,
+		"evaluation": evaluation,
+		"profile":    profile,
+		"next":       s.trainer.NextChallenge(userID, requestLocale(r)),
+	})
+}
+
+// handleTrainingNext 推荐下一道训练题的难度和场景
+func (s *Server) handleTrainingNext(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.trainer.NextChallenge(targetUserID(r), requestLocale(r)))
+}
+
+// handleTrainingPlan 生成个性化每周训练计划
+func (s *Server) handleTrainingPlan(w http.ResponseWriter, r *http.Request) {
+	userID := targetUserID(r)
+	level, _ := strconv.Atoi(r.URL.Query().Get("level"))
+
+	if s.aiManager != nil {
+		training, err := s.aiManager.GeneratePersonalizedTraining(r.Context(), map[string]interface{}{"user_id": userID}, level)
+		if err != nil {
+			http.Error(w, tr(r, "error.plan_failed", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, training)
+		return
+	}
+
+	// AI服务不可用时直接使用本地引擎的评估数据
+	profile := s.trainer.GetProfile(userID)
+	training := &aiPkg.PersonalizedTraining{
+		WeeklyPlan:    s.trainer.GenerateWeeklyPlan(userID, requestLocale(r)),
+		NextChallenge: s.trainer.NextChallenge(userID, requestLocale(r)),
+	}
+	if profile != nil {
+		training.UserLevel = profile.Level()
+		for _, dim := range profile.WeakestDimensions()[:2] {
+			training.MainFocus = append(training.MainFocus, aiPkg.DimensionLabel(dim, requestLocale(r)))
+		}
+	}
+	writeJSON(w, http.StatusOK, training)
+}
+
+// interactionTimeout 获取AI交互超时时间
+func (s *Server) interactionTimeout() time.Duration {
+	timeoutSeconds := config.DefaultInteractionTimeout
+	if s.config != nil && s.config.AI.InteractionTimeout > 0 {
+		timeoutSeconds = s.config.AI.InteractionTimeout
+	}
+	return time.Duration(timeoutSeconds) * time.Second
+}
+
+// handleOutputStats 查看各服务商结构化输出的成功、纠正和降级次数
+func (s *Server) handleOutputStats(w http.ResponseWriter, r *http.Request) {
+	if s.aiManager == nil {
+		http.Error(w, tr(r, "error.ai_unavailable"), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.aiManager.GetOutputStats())
+}
+
+// evaluationPrompt 返回评估使用的提示词模板标识
+func evaluationPrompt(evaluation *aiPkg.ReactionEvaluation) string {
+	if evaluation == nil || evaluation.Output == nil {
+		return ""
+	}
+	return evaluation.Output.Prompt
+}
+
+// handlePrompts 列出当前生效的提示词模板及版本
+func (s *Server) handlePrompts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": prompt.Default().List()})
+}
+
+// handlePromptsReload 重新加载提示词模板覆盖目录
+func (s *Server) handlePromptsReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := prompt.Default().Reload(); err != nil {
+		http.Error(w, tr(r, "error.prompt_reload_failed", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": prompt.Default().List()})
+}
@@ -0,0 +1,521 @@
+package web
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"reactedge/internal/corpus"
+	"reactedge/internal/history"
+	"reactedge/internal/i18n"
+	"reactedge/internal/user"
+	"reactedge/pkg/prompt"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsProtocolVersion WebSocket消息协议的当前版本
+// 不带version的消息按旧格式（版本0）处理：字段直接放在消息中，回复内容放在data中且不带id
+const wsProtocolVersion = 1
+
+// maxInFlightRequests 每个连接上同时处理的请求上限
+const maxInFlightRequests = 4
+
+// 客户端消息的action
+const (
+	wsActionGenerate   = "generate"
+	wsActionCancel     = "cancel"
+	wsActionAudioStart = "audio_start"
+	wsActionAudioEnd   = "audio_end"
+)
+
+// 服务端消息的type
+const (
+	wsTypeStatus     = "status"
+	wsTypeResult     = "result"
+	wsTypeTranscript = "transcript"
+	wsTypeCancelled  = "cancelled"
+	wsTypeError      = "error"
+)
+
+// websocketSchema 全部消息类型的JSON Schema
+//
+//go:embed websocket.schema.json
+var websocketSchema []byte
+
+// wsRequest 客户端消息，id由客户端生成，同一连接上正在处理的请求之间不能重复
+type wsRequest struct {
+	Version int             `json:"version"`
+	ID      string          `json:"id"`
+	Action  string          `json:"action"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// wsReply 服务端消息，id与对应的客户端消息相同
+type wsReply struct {
+	Version int         `json:"version,omitempty"`
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+	Data    interface{} `json:"data,omitempty"` // 旧格式的消息内容
+	Time    int64       `json:"time"`
+}
+
+// wsStatus 处理进度
+type wsStatus struct {
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+}
+
+// wsError 错误消息
+type wsError struct {
+	Message string `json:"message"`
+}
+
+// wsResult 生成的回答，length为回答的字节数
+type wsResult struct {
+	generateResponse
+	Length int `json:"length"`
+}
+
+// wsCancelPayload 取消请求，id为空时取消连接上全部正在处理的请求
+type wsCancelPayload struct {
+	ID string `json:"id"`
+}
+
+// wsSession 一条WebSocket连接，记录连接上正在处理的请求
+// gorilla/websocket不允许并发写，所有消息放进发送队列，由writePump依次发送
+type wsSession struct {
+	hub         *wsHub
+	conn        *websocket.Conn
+	viewer      *user.User
+	locale      string // 连接建立时协商的语言，用于这个连接上的所有消息
+	connectedAt time.Time
+
+	ctx      context.Context // 连接断开时结束，正在处理的请求随之取消
+	stop     context.CancelFunc
+	send     chan wsReply
+	sent     int // 已发送的消息数，由hub.mu保护
+	dropOnce sync.Once
+
+	mu       sync.Mutex
+	inFlight []*wsCall
+
+	audio *wsAudioStream // 正在接收的录音，二进制帧追加到这里，只在读goroutine中访问
+}
+
+// wsCall 连接上一个正在异步处理的请求
+type wsCall struct {
+	session *wsSession
+	version int
+	id      string
+	ctx     context.Context // 请求被取消时结束
+	cancel  context.CancelFunc
+}
+
+// handleWebSocketSchema 返回WebSocket消息的JSON Schema
+func handleWebSocketSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(websocketSchema)
+}
+
+// checkOrigin 只允许同源页面和配置的来源建立WebSocket连接，防止其他网站借用户的登录Cookie连接；
+// 没有Origin的请求来自脚本等非浏览器客户端，不受限制
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	if s.config != nil {
+		for _, allowed := range s.config.Server.AllowedOrigins {
+			if strings.EqualFold(strings.TrimRight(strings.TrimSpace(allowed), "/"), origin) {
+				return true
+			}
+		}
+	}
+	log.Printf("⚠️ 拒绝来源为%s的WebSocket连接", origin)
+	return false
+}
+
+// handleWebSocket 处理WebSocket连接
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// 升级HTTP连接为WebSocket
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket升级失败: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	log.Printf("新的WebSocket连接建立: %s", r.RemoteAddr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ws := &wsSession{
+		hub:         s.wsHub,
+		conn:        conn,
+		viewer:      currentUser(r),
+		locale:      requestLocale(r),
+		connectedAt: time.Now(),
+		ctx:         ctx,
+		stop:        cancel,
+		send:        make(chan wsReply, wsSendQueue),
+	}
+	s.wsHub.register(ws)
+	defer func() {
+		// 连接断开时取消正在处理的请求并停止写goroutine
+		cancelled := ws.cancel("")
+		ws.clearAudio()
+		ws.stop()
+		s.wsHub.unregister(ws)
+		log.Printf("WebSocket连接已断开: %s，取消%d个正在处理的请求", r.RemoteAddr, len(cancelled))
+	}()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	// 收到消息或心跳回复时延长读超时
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	go ws.writePump()
+
+	for {
+		// 读取客户端消息
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket错误: %v", err)
+			}
+			break
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		if messageType == websocket.BinaryMessage {
+			ws.appendAudio(data)
+			continue
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			ws.write(wsProtocolVersion, "", wsTypeError, wsError{Message: i18n.T(ws.locale, "error.invalid_message", err.Error())})
+			continue
+		}
+		if req.Version == 0 {
+			// 旧格式的字段直接放在消息中
+			req.Payload = data
+		}
+		s.handleWebSocketRequest(ws, &req)
+	}
+}
+
+// handleWebSocketRequest 按action分发客户端消息
+func (s *Server) handleWebSocketRequest(ws *wsSession, req *wsRequest) {
+	switch {
+	case req.Version > wsProtocolVersion:
+		ws.write(wsProtocolVersion, req.ID, wsTypeError, wsError{Message: i18n.T(ws.locale, "error.unsupported_version", req.Version)})
+		return
+	case req.Version > 0 && req.ID == "":
+		ws.replyError(req, i18n.T(ws.locale, "error.missing_field", "id"))
+		return
+	case req.Action == "":
+		ws.replyError(req, i18n.T(ws.locale, "error.missing_field", "action"))
+		return
+	}
+
+	switch req.Action {
+	case wsActionGenerate:
+		s.handleWebSocketGenerate(ws, req)
+	case wsActionCancel:
+		s.handleWebSocketCancel(ws, req)
+	case wsActionAudioStart:
+		s.handleWebSocketAudioStart(ws, req)
+	case wsActionAudioEnd:
+		s.handleWebSocketAudioEnd(ws, req)
+	default:
+		ws.replyError(req, i18n.T(ws.locale, "error.unknown_action", req.Action))
+	}
+}
+
+// handleWebSocketGenerate 处理WebSocket生成请求
+func (s *Server) handleWebSocketGenerate(ws *wsSession, req *wsRequest) {
+	var payload generateRequest
+	if !ws.decode(req, &payload) {
+		return
+	}
+	if payload.Style == "" {
+		ws.replyError(req, i18n.T(ws.locale, "error.missing_field", "style"))
+		return
+	}
+	if strings.TrimSpace(payload.Question) == "" {
+		ws.replyError(req, i18n.T(ws.locale, "error.empty_question"))
+		return
+	}
+	if !s.personaAllowed(ws.viewer, payload.Style) {
+		ws.replyError(req, i18n.T(ws.locale, "error.style_forbidden"))
+		return
+	}
+
+	call, err := ws.begin(req)
+	if err != nil {
+		ws.replyError(req, err.Error())
+		return
+	}
+
+	// 发送开始状态
+	call.send(wsTypeStatus, wsStatus{Stage: "started", Message: i18n.T(ws.locale, "status.started")})
+
+	// 日志不记录问题内容
+	log.Printf("WebSocket请求%s: 用户%s，风格%s", call.id, ws.viewer.ID, payload.Style)
+
+	// 使用goroutine异步处理AI请求
+	go func() {
+		defer call.cancel()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("WebSocket处理panic: %v", r)
+				// 由于连接可能已断开，这里只记录日志，不发送错误消息
+				log.Printf("由于panic，跳过向客户端发送错误消息")
+			}
+		}()
+
+		s.processWebSocketAIRequest(call, payload)
+	}()
+}
+
+// processWebSocketAIRequest 处理WebSocket AI请求，请求被取消后不再发送任何消息
+func (s *Server) processWebSocketAIRequest(call *wsCall, req generateRequest) {
+	ws := call.session
+	userID := ws.viewer.ID
+
+	ctx, cancel := context.WithTimeout(prompt.WithLanguage(call.ctx, ws.locale), s.interactionTimeout())
+	defer cancel()
+
+	// 发送处理状态
+	call.send(wsTypeStatus, wsStatus{Stage: "processing", Message: i18n.T(ws.locale, "status.processing")})
+
+	assignment := s.assignVariant(userID)
+	var response, promptRef string
+	var passages []corpus.Passage
+	var err error
+
+	if s.aiManager != nil {
+		passages = s.retrievePassages(ctx, req.Style, req.Question, req.Content)
+		response, promptRef, err = s.generateAIResponse(ctx, req.Style, req.Question, req.Content, passages, assignment)
+		if err != nil && errors.Is(call.ctx.Err(), context.Canceled) {
+			log.Printf("WebSocket请求%s已取消", call.id)
+			return
+		}
+		if err != nil {
+			log.Printf("WebSocket AI生成回答失败: %v", err)
+			assignment = nil // 本地模拟回答不计入实验
+			passages = nil
+
+			// 检查是否是配额错误
+			errMsg := err.Error()
+			if strings.Contains(errMsg, "429") || strings.Contains(errMsg, "quota") {
+				log.Println("⚠️ WebSocket AI服务配额超限，已切换到本地模拟回答")
+				response = i18n.T(ws.locale, "error.quota_fallback", req.Style, s.localResponse(req.Style, req.Question, req.Content))
+
+				call.send(wsTypeStatus, wsStatus{Stage: "fallback", Message: i18n.T(ws.locale, "status.fallback")})
+			} else {
+				call.fail(i18n.T(ws.locale, "error.generate_failed", err.Error()))
+				return
+			}
+		}
+	} else {
+		// AI服务不可用，使用本地模拟回答
+		assignment = nil
+		response = s.localResponse(req.Style, req.Question, req.Content)
+
+		call.send(wsTypeStatus, wsStatus{Stage: "local", Message: i18n.T(ws.locale, "status.local")})
+	}
+
+	// 取消后生成的回答不记录也不发送
+	if !call.finish() {
+		log.Printf("WebSocket请求%s已取消，丢弃生成的回答", call.id)
+		return
+	}
+
+	attemptID := s.recordGeneration(&history.Attempt{
+		UserID:          userID,
+		Kind:            history.KindGenerate,
+		Question:        req.Question,
+		Persona:         req.Style,
+		Content:         req.Content,
+		GeneratedAnswer: response,
+		Prompt:          promptRef,
+		Citations:       citationIDs(passages),
+	}, assignment)
+
+	// 发送结果
+	call.write(wsTypeResult, wsResult{
+		generateResponse: generateResponse{
+			Response:  response,
+			AttemptID: attemptID,
+			AudioURL:  answerAudioURL(attemptID),
+			Citations: passages,
+		},
+		Length: len(response),
+	})
+	log.Printf("WebSocket请求%s已完成，回答%d字节", call.id, len(response))
+}
+
+// handleWebSocketCancel 取消payload.id对应的请求，被取消的请求以cancelled消息结束
+func (s *Server) handleWebSocketCancel(ws *wsSession, req *wsRequest) {
+	var payload wsCancelPayload
+	if !ws.decode(req, &payload) {
+		return
+	}
+
+	cancelled := ws.cancel(payload.ID)
+	if payload.ID != "" && len(cancelled) == 0 {
+		ws.replyError(req, i18n.T(ws.locale, "error.no_such_request", payload.ID))
+		return
+	}
+	for _, call := range cancelled {
+		call.write(wsTypeCancelled, wsStatus{Stage: "cancelled", Message: i18n.T(ws.locale, "status.cancelled")})
+	}
+}
+
+// decode 解析消息的payload，失败时回复错误并返回false
+func (ws *wsSession) decode(req *wsRequest, payload interface{}) bool {
+	if len(req.Payload) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(req.Payload, payload); err != nil {
+		ws.replyError(req, i18n.T(ws.locale, "error.invalid_message", err.Error()))
+		return false
+	}
+	return true
+}
+
+// begin 登记一个需要异步处理的请求，id重复或超过并发上限时返回错误
+func (ws *wsSession) begin(req *wsRequest) (*wsCall, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if len(ws.inFlight) >= maxInFlightRequests {
+		return nil, errors.New(i18n.T(ws.locale, "error.too_many_requests", maxInFlightRequests))
+	}
+	for _, call := range ws.inFlight {
+		if req.ID != "" && call.id == req.ID {
+			return nil, errors.New(i18n.T(ws.locale, "error.duplicate_request", req.ID))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ws.ctx)
+	call := &wsCall{session: ws, version: req.Version, id: req.ID, ctx: ctx, cancel: cancel}
+	ws.inFlight = append(ws.inFlight, call)
+	return call, nil
+}
+
+// cancel 取消id对应的请求，id为空时取消全部，返回被取消的请求
+func (ws *wsSession) cancel(id string) []*wsCall {
+	ws.mu.Lock()
+	var cancelled, remaining []*wsCall
+	for _, call := range ws.inFlight {
+		if id == "" || call.id == id {
+			cancelled = append(cancelled, call)
+		} else {
+			remaining = append(remaining, call)
+		}
+	}
+	ws.inFlight = remaining
+	ws.mu.Unlock()
+
+	for _, call := range cancelled {
+		call.cancel()
+	}
+	return cancelled
+}
+
+// inFlightCount 正在处理的请求数
+func (ws *wsSession) inFlightCount() int {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return len(ws.inFlight)
+}
+
+// write 把消息放进发送队列，version为0时按旧格式发送
+// 连接已断开时丢弃消息；队列已满说明客户端读得太慢，断开连接
+func (ws *wsSession) write(version int, id, msgType string, payload interface{}) {
+	reply := wsReply{Type: msgType, Time: time.Now().Unix()}
+	if version == 0 {
+		reply.Data = payload
+	} else {
+		reply.Version, reply.ID, reply.Payload = version, id, payload
+	}
+
+	// 连接已结束时不再入队，也不会走到队列已满的分支
+	select {
+	case <-ws.ctx.Done():
+	case ws.send <- reply:
+	default:
+		ws.drop()
+	}
+}
+
+// drop 发送队列已满时断开连接，多个写入方同时遇到队列已满时只断开和计数一次
+func (ws *wsSession) drop() {
+	ws.dropOnce.Do(func() {
+		log.Printf("⚠️ WebSocket发送队列已满，断开连接: %s", ws.conn.RemoteAddr())
+		ws.hub.countDropped()
+		ws.stop()
+		ws.conn.Close()
+	})
+}
+
+// replyError 回复错误消息
+func (ws *wsSession) replyError(req *wsRequest, message string) {
+	ws.write(req.Version, req.ID, wsTypeError, wsError{Message: message})
+}
+
+// finish 把请求移出正在处理的请求，请求已被取消时返回false，之后不应再发送消息
+func (call *wsCall) finish() bool {
+	ws := call.session
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	for i, c := range ws.inFlight {
+		if c == call {
+			ws.inFlight = append(ws.inFlight[:i], ws.inFlight[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// write 发送请求的消息
+func (call *wsCall) write(msgType string, payload interface{}) {
+	call.session.write(call.version, call.id, msgType, payload)
+}
+
+// send 发送处理进度，请求已被取消时忽略
+func (call *wsCall) send(msgType string, payload interface{}) {
+	if call.ctx.Err() != nil {
+		return
+	}
+	call.write(msgType, payload)
+}
+
+// fail 以错误消息结束请求，请求已被取消时忽略
+func (call *wsCall) fail(message string) {
+	if call.finish() {
+		call.write(wsTypeError, wsError{Message: message})
+	}
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"reactedge/config"
+)
+
+// TestCheckOrigin 只允许同源、配置的来源和不带Origin的客户端建立WebSocket连接
+func TestCheckOrigin(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Server.AllowedOrigins = []string{"https://app.example.com/"}
+	s := &Server{config: cfg}
+
+	for origin, want := range map[string]bool{
+		"":                        true,
+		"http://reactedge.local":  true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+		"null":                    false,
+	} {
+		r := httptest.NewRequest("GET", "http://reactedge.local/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := s.checkOrigin(r); got != want {
+			t.Errorf("来源%q: 期望%v，实际%v", origin, want, got)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second  // 写一条消息的超时
+	wsPongWait   = 120 * time.Second // 这段时间内没有收到消息或pong时断开连接
+	wsPingPeriod = 30 * time.Second  // 心跳间隔，必须小于wsPongWait
+	wsSendQueue  = 64                // 每个连接的发送队列长度，队列满时断开连接
+
+	wsMaxMessageSize       = 1 << 20 // 单条消息的大小上限，录音需要分成多个二进制帧发送
+	maxAudioStreamsPerUser = 2       // 每个用户在全部连接上同时接收的录音数上限
+)
+
+// wsTypeNotice 服务端主动发送的通知，不带id
+const wsTypeNotice = "notice"
+
+// wsHub 记录全部WebSocket连接，用于统计和广播
+// 锁顺序：持有hub.mu时不获取连接的ws.mu，需要两者的地方先复制连接列表再释放hub.mu
+type wsHub struct {
+	mu       sync.Mutex
+	sessions map[*wsSession]bool
+	total    int // 建立过的连接数
+	sent     int // 已发送的消息数
+	dropped  int // 因发送队列已满而断开的连接数
+
+	audioStreams map[string]int // 录音名额的计数键 -> 正在接收的录音数，见wsSession.audioKey
+}
+
+// wsStats WebSocket连接统计
+type wsStats struct {
+	Active      int                 `json:"active"`
+	Total       int                 `json:"total"`
+	Sent        int                 `json:"sent"`
+	Dropped     int                 `json:"dropped"`
+	InFlight    int                 `json:"in_flight"`
+	Connections []wsConnectionStats `json:"connections"`
+}
+
+// wsConnectionStats 一条连接的统计
+type wsConnectionStats struct {
+	UserID      string    `json:"user_id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Locale      string    `json:"locale"`
+	ConnectedAt time.Time `json:"connected_at"`
+	InFlight    int       `json:"in_flight"`
+	Queued      int       `json:"queued"`
+	Sent        int       `json:"sent"`
+}
+
+// wsNotice 通知内容
+type wsNotice struct {
+	Message string `json:"message"`
+}
+
+// broadcastRequest 广播通知的请求，userID为空时发给全部连接
+type broadcastRequest struct {
+	Message string `json:"message"`
+	UserID  string `json:"user_id"`
+}
+
+// newWSHub 创建连接表
+func newWSHub() *wsHub {
+	return &wsHub{sessions: make(map[*wsSession]bool), audioStreams: make(map[string]int)}
+}
+
+// register 登记新连接
+func (h *wsHub) register(ws *wsSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions[ws] = true
+	h.total++
+}
+
+// unregister 移除已断开的连接
+func (h *wsHub) unregister(ws *wsSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, ws)
+}
+
+// countSent 记录一条已发送的消息
+func (h *wsHub) countSent(ws *wsSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sent++
+	ws.sent++
+}
+
+// countDropped 记录一次因发送队列已满而断开连接
+func (h *wsHub) countDropped() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dropped++
+}
+
+// acquireAudio 按计数键占用一个录音名额，已达上限时返回false
+func (h *wsHub) acquireAudio(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.audioStreams[key] >= maxAudioStreamsPerUser {
+		return false
+	}
+	h.audioStreams[key]++
+	return true
+}
+
+// releaseAudio 释放计数键的录音名额
+func (h *wsHub) releaseAudio(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.audioStreams[key]--; h.audioStreams[key] <= 0 {
+		delete(h.audioStreams, key)
+	}
+}
+
+// broadcast 向连接发送通知，userID不为空时只发给该用户的连接，返回发送的连接数
+func (h *wsHub) broadcast(userID, msgType string, payload interface{}) int {
+	h.mu.Lock()
+	var targets []*wsSession
+	for ws := range h.sessions {
+		if userID == "" || ws.viewer.ID == userID {
+			targets = append(targets, ws)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, ws := range targets {
+		ws.write(wsProtocolVersion, "", msgType, payload)
+	}
+	return len(targets)
+}
+
+// stats 统计当前连接，先在hub.mu下复制连接和计数，释放后再读取各连接正在处理的请求
+func (h *wsHub) stats() wsStats {
+	h.mu.Lock()
+	stats := wsStats{Active: len(h.sessions), Total: h.total, Sent: h.sent, Dropped: h.dropped, Connections: []wsConnectionStats{}}
+	sessions := make([]*wsSession, 0, len(h.sessions))
+	sent := make([]int, 0, len(h.sessions))
+	for ws := range h.sessions {
+		sessions = append(sessions, ws)
+		sent = append(sent, ws.sent)
+	}
+	h.mu.Unlock()
+
+	for i, ws := range sessions {
+		connection := wsConnectionStats{
+			UserID:      ws.viewer.ID,
+			RemoteAddr:  ws.conn.RemoteAddr().String(),
+			Locale:      ws.locale,
+			ConnectedAt: ws.connectedAt,
+			InFlight:    ws.inFlightCount(),
+			Queued:      len(ws.send),
+			Sent:        sent[i],
+		}
+		stats.InFlight += connection.InFlight
+		stats.Connections = append(stats.Connections, connection)
+	}
+	return stats
+}
+
+// writePump 连接唯一的写goroutine，依次发送队列中的消息和心跳，连接结束时退出
+func (ws *wsSession) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case reply := <-ws.send:
+			ws.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := ws.conn.WriteJSON(reply); err != nil {
+				log.Printf("WebSocket发送消息失败: %v", err)
+				ws.conn.Close()
+				return
+			}
+			ws.hub.countSent(ws)
+		case <-ticker.C:
+			ws.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := ws.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("发送心跳失败: %v", err)
+				ws.conn.Close()
+				return
+			}
+		case <-ws.ctx.Done():
+			return
+		}
+	}
+}
+
+// handleWebSocketStats 查看WebSocket连接数、正在处理的请求和发送统计
+func (s *Server) handleWebSocketStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.wsHub.stats())
+}
+
+// handleWebSocketBroadcast 向在线连接发送通知，如服务维护提醒
+func (s *Server) handleWebSocketBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req broadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		http.Error(w, tr(r, "error.empty_notice"), http.StatusBadRequest)
+		return
+	}
+
+	delivered := s.wsHub.broadcast(req.UserID, wsTypeNotice, wsNotice{Message: req.Message})
+	log.Printf("📢 WebSocket通知已发送到%d个连接", delivered)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"delivered": delivered})
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"reactedge/internal/user"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestHubDropsSlowConnection 发送队列满时断开连接并计入统计，不阻塞写入方
+func TestHubDropsSlowConnection(t *testing.T) {
+	hub := newWSHub()
+	sessions := make(chan *wsSession, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// 不启动writePump，模拟读得太慢的客户端
+		ctx, cancel := context.WithCancel(context.Background())
+		ws := &wsSession{
+			hub:         hub,
+			conn:        conn,
+			viewer:      &user.User{ID: "u1"},
+			locale:      "zh-CN",
+			connectedAt: time.Now(),
+			ctx:         ctx,
+			stop:        cancel,
+			send:        make(chan wsReply, wsSendQueue),
+		}
+		hub.register(ws)
+		sessions <- ws
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("建立WebSocket连接失败: %v", err)
+	}
+	defer client.Close()
+	ws := <-sessions
+
+	for i := 0; i < wsSendQueue; i++ {
+		ws.write(wsProtocolVersion, "", wsTypeNotice, wsNotice{Message: "排队"})
+	}
+	if stats := hub.stats(); stats.Dropped != 0 || stats.Connections[0].Queued != wsSendQueue {
+		t.Fatalf("队列未满时不应断开: %+v", stats)
+	}
+
+	// 多个写入方同时遇到队列已满，只断开和计数一次
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ws.write(wsProtocolVersion, "", wsTypeNotice, wsNotice{Message: "溢出"})
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("队列已满时写入不应阻塞")
+	}
+
+	if stats := hub.stats(); stats.Dropped != 1 {
+		t.Errorf("应记录一次断开: %+v", stats)
+	}
+	if ws.ctx.Err() == nil {
+		t.Error("队列已满后连接应结束")
+	}
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := client.ReadMessage(); err == nil {
+		t.Error("客户端应收到连接关闭")
+	}
+
+	// 连接结束后写入的消息直接丢弃
+	ws.write(wsProtocolVersion, "", wsTypeNotice, wsNotice{Message: "丢弃"})
+	if stats := hub.stats(); stats.Dropped != 1 {
+		t.Errorf("连接结束后不应重复计数: %+v", stats)
+	}
+}